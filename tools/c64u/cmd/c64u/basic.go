@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/basic"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// BASIC COMMANDS
+// ============================================================================
+
+var (
+	basicOutput string
+	basicMixed  bool
+)
+
+var basicCmd = &cobra.Command{
+	Use:   "basic",
+	Short: "Build and run BASIC V2 programs from text",
+	Long: `Tokenise plain-text BASIC V2 listings into PRG files and run them on
+the C64 Ultimate.
+
+The listing format is the one "machine basic list" prints: every line
+starts with a line number, keywords may be written in either case, and
+control codes are written as escapes such as {CLR}, {DOWN*3}, {RVS ON},
+{WHT} or {$xx}. "?" is PRINT, and nothing is tokenised in strings, after
+REM or in DATA statements. Use --mixed for text written in the lower/upper
+case character set.`,
+}
+
+// ============================================================================
+// BASIC BUILD - Tokenise a listing into a PRG file
+// ============================================================================
+
+var basicBuildCmd = &cobra.Command{
+	Use:   "build <file.bas>",
+	Short: "Tokenise a BASIC listing into a PRG file",
+	Long: `Tokenise a BASIC V2 listing into a PRG file loading at $0801, with
+correct line links, as if it had been typed in and saved.
+
+Without --output the PRG is written next to the listing.
+
+Examples:
+  c64u basic build loader.bas
+  c64u basic build test.bas -o build/test.prg
+  c64u basic build menu.bas --mixed`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		prg, err := buildBasic(args[0])
+		if err != nil {
+			formatter.Error("Failed to tokenise BASIC", []string{err.Error()})
+			return
+		}
+
+		output := basicOutput
+		if output == "" {
+			output = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".prg"
+		}
+		if err := os.WriteFile(output, prg, 0644); err != nil {
+			formatter.Error("Failed to write file", []string{err.Error()})
+			return
+		}
+
+		formatter.Success(fmt.Sprintf("Built %s", output), map[string]interface{}{
+			"range": fmt.Sprintf("$%04X-$%04X", basic.DefaultStart, basic.DefaultStart+len(prg)-3),
+			"bytes": len(prg) - 2,
+		})
+	},
+}
+
+// ============================================================================
+// BASIC RUN - Tokenise a listing and run it on the device
+// ============================================================================
+
+var basicRunCmd = &cobra.Command{
+	Use:   "run <file.bas>",
+	Short: "Tokenise a BASIC listing and run it",
+	Long: `Tokenise a BASIC V2 listing, upload the program and RUN it on the
+C64 Ultimate.
+
+Examples:
+  c64u basic run test.bas
+  c64u basic run harness.bas && c64u machine screen`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		prg, err := buildBasic(args[0])
+		if err != nil {
+			formatter.Error("Failed to tokenise BASIC", []string{err.Error()})
+			return
+		}
+
+		tmpPath, err := writeTempFile("c64u-*.prg", prg)
+		if err != nil {
+			formatter.Error("Failed to upload program", []string{err.Error()})
+			return
+		}
+		defer os.Remove(tmpPath)
+
+		resp, err := apiClient.RunPRGUpload(tmpPath)
+		if err != nil {
+			formatter.Error("Failed to run program", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Running %s", filepath.Base(args[0])))
+	},
+}
+
+// buildBasic reads and tokenises a listing and returns the PRG file data
+func buildBasic(path string) ([]byte, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	cs := petscii.Upper
+	if basicMixed {
+		cs = petscii.Lower
+	}
+	program, err := basic.Tokenize(string(source), basic.DefaultStart, cs)
+	if err != nil {
+		return nil, err
+	}
+	start := basic.DefaultStart
+	return append([]byte{byte(start), byte(start >> 8)}, program...), nil
+}
+
+func init() {
+	basicCmd.PersistentFlags().BoolVar(&basicMixed, "mixed", false, "Listing uses the lower/upper case character set")
+	basicBuildCmd.Flags().StringVarP(&basicOutput, "output", "o", "", "Output PRG file (default: <file>.prg)")
+
+	basicCmd.AddCommand(basicBuildCmd)
+	basicCmd.AddCommand(basicRunCmd)
+}
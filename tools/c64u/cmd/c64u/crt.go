@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/crt"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// CARTRIDGE COMMANDS
+// ============================================================================
+
+var crtCmd = &cobra.Command{
+	Use:   "crt",
+	Short: "Inspect cartridge images",
+	Long:  `Inspect and validate local CRT cartridge images before running them.`,
+}
+
+// ============================================================================
+// CRT INFO - Show cartridge header and CHIP packets
+// ============================================================================
+
+var crtInfoCmd = &cobra.Command{
+	Use:   "info <file.crt>",
+	Short: "Show cartridge header, CHIP packets and validation results",
+	Long: `Parse a CRT file and display its header (version, hardware type,
+EXROM/GAME lines, name) and all CHIP packets (bank, load address, size,
+type). The file is checked for consistency and any problems are listed.
+
+Examples:
+  c64u crt info game.crt
+  c64u --json crt info game.crt`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+
+		cart, err := crt.ReadFile(path)
+		if err != nil {
+			formatter.Error("Failed to parse cartridge", []string{err.Error()})
+			return
+		}
+
+		issues := cart.Validate()
+
+		if jsonOut {
+			formatter.PrintData(map[string]interface{}{
+				"cartridge": cart,
+				"mode":      cart.Mode(),
+				"banks":     cart.Banks(),
+				"supported": crt.IsSupported(cart.HardwareType),
+				"issues":    issues,
+			})
+			return
+		}
+
+		formatter.PrintHeader(fmt.Sprintf("🎮 %s", filepath.Base(path)))
+		fmt.Println()
+		formatter.PrintKeyValue("Name", cart.Name)
+		formatter.PrintKeyValue("Version", fmt.Sprintf("%d.%02d", cart.Version>>8, cart.Version&0xFF))
+		formatter.PrintKeyValue("Hardware Type", fmt.Sprintf("%d (%s)", cart.HardwareType, cart.HardwareName))
+		if cart.Subtype != 0 {
+			formatter.PrintKeyValue("Subtype", fmt.Sprintf("%d", cart.Subtype))
+		}
+		formatter.PrintKeyValue("EXROM / GAME", fmt.Sprintf("%d / %d (%s)", cart.EXROM, cart.GAME, cart.Mode()))
+		formatter.PrintKeyValue("Banks", fmt.Sprintf("%d", cart.Banks()))
+		formatter.PrintKeyValue("File Size", fmt.Sprintf("%d bytes", cart.Size))
+		fmt.Println()
+
+		var rows [][]string
+		for _, chip := range cart.Chips {
+			rows = append(rows, []string{
+				fmt.Sprintf("%d", chip.Bank),
+				fmt.Sprintf("$%04X", chip.LoadAddress),
+				fmt.Sprintf("$%04X", chip.Size),
+				chip.TypeName,
+				fmt.Sprintf("$%X", chip.Offset),
+			})
+		}
+		formatter.PrintTable([]string{"Bank", "Load", "Size", "Type", "Offset"}, rows)
+		fmt.Println()
+
+		printCRTIssues(issues)
+	},
+}
+
+// printCRTIssues prints validation findings in text mode
+func printCRTIssues(issues []crt.Issue) {
+	if len(issues) == 0 {
+		formatter.Success("Cartridge image is consistent", nil)
+		return
+	}
+
+	for _, issue := range issues {
+		if issue.Severity == crt.SeverityError {
+			fmt.Printf("  ✗ %s\n", issue.Message)
+		} else {
+			fmt.Printf("  ⚠ %s\n", issue.Message)
+		}
+	}
+}
+
+// checkCartridge validates a local CRT before it is uploaded.
+// Warnings are printed; errors refuse the upload unless force is set.
+func checkCartridge(path string, force bool) bool {
+	cart, err := crt.ReadFile(path)
+	if err != nil {
+		if force {
+			formatter.Warning(fmt.Sprintf("%v (continuing because of --force)", err))
+			return true
+		}
+		formatter.Error("Refusing to run invalid cartridge", []string{
+			err.Error(),
+			"Use --force to upload anyway",
+		})
+		return false
+	}
+
+	issues := cart.Validate()
+	var errors []string
+	for _, issue := range issues {
+		if issue.Severity == crt.SeverityError {
+			errors = append(errors, issue.Message)
+		} else {
+			formatter.Warning(issue.Message)
+		}
+	}
+
+	if len(errors) > 0 {
+		if force {
+			for _, msg := range errors {
+				formatter.Warning(msg + " (continuing because of --force)")
+			}
+			return true
+		}
+		formatter.Error("Refusing to run invalid cartridge", append(errors, "Use --force to upload anyway"))
+		return false
+	}
+
+	formatter.Info(fmt.Sprintf("Cartridge %q: %s", cart.Name, cart.HardwareName))
+	return true
+}
+
+func init() {
+	crtCmd.AddCommand(crtInfoCmd)
+}
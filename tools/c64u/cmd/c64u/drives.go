@@ -0,0 +1,302 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// DRIVES COMMANDS
+// ============================================================================
+
+var (
+	drivesMountType string
+	drivesMountMode string
+)
+
+var drivesCmd = &cobra.Command{
+	Use:   "drives",
+	Short: "Floppy drive operations",
+	Long: `List, mount and control the C64 Ultimate's emulated floppy drives.
+
+Drives are addressed by their API name as shown by "drives list" (a, b).
+
+Mount types: d64, g64, d71, g71, d81
+Mount modes: readwrite, readonly, unlinked`,
+}
+
+// ============================================================================
+// DRIVES LIST - Show drives and mounted images
+// ============================================================================
+
+var drivesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all drives",
+	Long:  `List the emulated drives with their bus ID, type and mounted image.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.DrivesList()
+		if err != nil {
+			formatter.Error("Failed to list drives", []string{err.Error()})
+			return
+		}
+
+		if resp.HasErrors() {
+			formatter.Error("API returned errors", resp.Errors)
+			return
+		}
+
+		if jsonOut {
+			formatter.PrintData(resp.Data)
+			return
+		}
+
+		var rows [][]string
+		list, _ := resp.Data["drives"].([]interface{})
+		for _, item := range list {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			names := make([]string, 0, len(entry))
+			for name := range entry {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				fields, ok := entry[name].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				enabled := "off"
+				if on, _ := fields["enabled"].(bool); on {
+					enabled = "on"
+				}
+				busID := "-"
+				if id, ok := fields["bus_id"].(float64); ok {
+					busID = fmt.Sprintf("%d", int(id))
+				}
+				driveType, _ := fields["type"].(string)
+				image, _ := fields["image_file"].(string)
+				if image == "" {
+					image = "-"
+				}
+				rows = append(rows, []string{name, busID, enabled, driveType, image})
+			}
+		}
+
+		if len(rows) == 0 {
+			formatter.Info("No drives reported")
+			return
+		}
+
+		formatter.PrintTable([]string{"Drive", "Bus ID", "Power", "Type", "Image"}, rows)
+	},
+}
+
+// ============================================================================
+// DRIVES MOUNT/MOUNT-UPLOAD/UNMOUNT - Disk images
+// ============================================================================
+
+var drivesMountCmd = &cobra.Command{
+	Use:   "mount <drive> <image>",
+	Short: "Mount a disk image from the C64U filesystem",
+	Long: `Mount a disk image stored on the C64 Ultimate in a drive. The type is
+taken from the file extension unless --type is given.
+
+Examples:
+  c64u drives mount a /USB0/disks/game.d64
+  c64u drives mount b /USB0/disks/data.d81 --mode readonly`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.DrivesMount(args[0], args[1], drivesMountType, drivesMountMode)
+		if err != nil {
+			formatter.Error("Failed to mount image", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Mounted %s in drive %s", args[1], args[0]))
+	},
+}
+
+var drivesMountUploadCmd = &cobra.Command{
+	Use:   "mount-upload <drive> <file>",
+	Short: "Upload and mount a disk image",
+	Long: `Upload a local disk image and mount it in a drive.
+
+Examples:
+  c64u drives mount-upload a game.d64
+  c64u drives mount-upload a game.g64 --type g64 --mode unlinked`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.DrivesMountUpload(args[0], args[1], drivesMountType, drivesMountMode)
+		if err != nil {
+			formatter.Error("Failed to mount image", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Mounted %s in drive %s", filepath.Base(args[1]), args[0]))
+	},
+}
+
+var drivesUnmountCmd = &cobra.Command{
+	Use:   "unmount <drive>",
+	Short: "Remove the disk from a drive",
+	Long: `Unmount the disk image from a drive.
+
+Examples:
+  c64u drives unmount a`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.DrivesRemove(args[0])
+		if err != nil {
+			formatter.Error("Failed to unmount image", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Unmounted drive %s", args[0]))
+	},
+}
+
+// ============================================================================
+// DRIVES RESET/ON/OFF - Drive control
+// ============================================================================
+
+var drivesResetCmd = &cobra.Command{
+	Use:   "reset <drive>",
+	Short: "Reset a drive",
+	Long:  `Reset a drive, as if it had been switched off and on.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.DrivesReset(args[0])
+		if err != nil {
+			formatter.Error("Failed to reset drive", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Drive %s reset", args[0]))
+	},
+}
+
+var drivesOnCmd = &cobra.Command{
+	Use:   "on <drive>",
+	Short: "Enable a drive",
+	Long:  `Switch a drive on so it appears on the serial bus.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.DrivesOn(args[0])
+		if err != nil {
+			formatter.Error("Failed to enable drive", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Drive %s enabled", args[0]))
+	},
+}
+
+var drivesOffCmd = &cobra.Command{
+	Use:   "off <drive>",
+	Short: "Disable a drive",
+	Long:  `Switch a drive off so it no longer answers on the serial bus.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.DrivesOff(args[0])
+		if err != nil {
+			formatter.Error("Failed to disable drive", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Drive %s disabled", args[0]))
+	},
+}
+
+// ============================================================================
+// DRIVES LOAD-ROM/LOAD-ROM-UPLOAD/SET-MODE - ROM and drive mode
+// ============================================================================
+
+var drivesLoadROMCmd = &cobra.Command{
+	Use:   "load-rom <drive> <file>",
+	Short: "Load a drive ROM from the C64U filesystem",
+	Long: `Load a custom 16K or 32K drive ROM stored on the C64 Ultimate. The ROM
+stays active until the drive mode is changed or the device restarts.
+
+Examples:
+  c64u drives load-rom a /USB0/roms/jiffydos-1541.bin`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.DrivesLoadROM(args[0], args[1])
+		if err != nil {
+			formatter.Error("Failed to load ROM", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Loaded %s into drive %s", args[1], args[0]))
+	},
+}
+
+var drivesLoadROMUploadCmd = &cobra.Command{
+	Use:   "load-rom-upload <drive> <file>",
+	Short: "Upload and load a drive ROM",
+	Long: `Upload a local 16K or 32K drive ROM and load it into a drive.
+
+Examples:
+  c64u drives load-rom-upload a jiffydos-1541.bin`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.DrivesLoadROMUpload(args[0], args[1])
+		if err != nil {
+			formatter.Error("Failed to load ROM", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Loaded %s into drive %s", filepath.Base(args[1]), args[0]))
+	},
+}
+
+var drivesSetModeCmd = &cobra.Command{
+	Use:   "set-mode <drive> <mode>",
+	Short: "Set the drive mode",
+	Long: `Change the type of drive that is emulated: 1541, 1571 or 1581.
+
+Examples:
+  c64u drives set-mode a 1581`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		switch args[1] {
+		case "1541", "1571", "1581":
+		default:
+			formatter.Error("Invalid drive mode", []string{fmt.Sprintf("%q is not one of 1541, 1571, 1581", args[1])})
+			return
+		}
+
+		resp, err := apiClient.DrivesSetMode(args[0], args[1])
+		if err != nil {
+			formatter.Error("Failed to set drive mode", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Drive %s set to %s mode", args[0], args[1]))
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{drivesMountCmd, drivesMountUploadCmd} {
+		cmd.Flags().StringVar(&drivesMountType, "type", "", "Image type: d64, g64, d71, g71, d81")
+		cmd.Flags().StringVar(&drivesMountMode, "mode", "", "Mount mode: readwrite, readonly, unlinked")
+	}
+
+	drivesCmd.AddCommand(drivesListCmd)
+	drivesCmd.AddCommand(drivesMountCmd)
+	drivesCmd.AddCommand(drivesMountUploadCmd)
+	drivesCmd.AddCommand(drivesUnmountCmd)
+	drivesCmd.AddCommand(drivesResetCmd)
+	drivesCmd.AddCommand(drivesOnCmd)
+	drivesCmd.AddCommand(drivesOffCmd)
+	drivesCmd.AddCommand(drivesLoadROMCmd)
+	drivesCmd.AddCommand(drivesLoadROMUploadCmd)
+	drivesCmd.AddCommand(drivesSetModeCmd)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/api"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/diskimage"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// DRIVES PUT/GET - Edit the disk image mounted in a drive
+// ============================================================================
+
+var (
+	drivesPutName      string
+	drivesPutType      string
+	drivesPutOverwrite bool
+	drivesPutMode      string
+	drivesGetOutput    string
+)
+
+var drivesPutCmd = &cobra.Command{
+	Use:   "put <drive> <local-file>",
+	Short: "Add a file to the image mounted in a drive",
+	Long: `Add a local file to the disk image currently mounted in a drive.
+
+The mounted image is fetched via FTP, the file is written into it, the
+image is uploaded back to the same path and re-mounted so the drive sees
+the change. The drive may be given by bus ID (8, 9) or API name (a, b).
+
+The mount mode is kept if the firmware reports it; otherwise --mode is
+used (default readwrite).
+
+Examples:
+  c64u drives put 8 level2.prg
+  c64u drives put 8 build/data.bin --name "LEVEL DATA" --type seq
+  c64u drives put 8 game.prg --overwrite`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		drive, localFile := args[0], args[1]
+
+		info, remote, err := mountedImage(drive)
+		if err != nil {
+			formatter.Error("No usable image in drive", []string{err.Error()})
+			return
+		}
+
+		img, tmp, err := fetchMountedImage(remote)
+		if err != nil {
+			formatter.Error("Failed to fetch image", []string{err.Error()})
+			return
+		}
+		defer os.Remove(tmp)
+
+		entry, err := addLocalFile(img, localFile, drivesPutName, drivesPutType, drivesPutOverwrite)
+		if err != nil {
+			formatter.Error("Failed to add file", []string{err.Error()})
+			return
+		}
+
+		if err := img.Save(tmp); err != nil {
+			formatter.Error("Failed to save image", []string{err.Error()})
+			return
+		}
+		if err := apiClient.FTPUpload(tmp, remote); err != nil {
+			formatter.Error("Upload failed", []string{err.Error()})
+			return
+		}
+
+		mode := info.Mode
+		if mode == "" {
+			mode = drivesPutMode
+		}
+		resp, err := apiClient.DrivesMount(info.Name, remote, img.Format.Name, mode)
+		if err != nil {
+			formatter.Error("Failed to re-mount image", []string{err.Error()})
+			return
+		}
+		if resp.HasErrors() {
+			formatter.Error("Failed to re-mount image", resp.Errors)
+			return
+		}
+
+		formatter.Success(fmt.Sprintf("Added %s to %s", entry.Name, path.Base(remote)), map[string]interface{}{
+			"drive":       info.BusID,
+			"type":        entry.Type,
+			"blocks":      entry.Blocks,
+			"blocks_free": img.FreeBlocks(),
+			"mode":        mode,
+		})
+	},
+}
+
+var drivesGetCmd = &cobra.Command{
+	Use:   "get <drive> <name>",
+	Short: "Extract a file from the image mounted in a drive",
+	Long: `Copy a file out of the disk image currently mounted in a drive.
+
+The mounted image is fetched via FTP and left unchanged on the device.
+The name may use the CBM wildcards * and ?. Without --output the file is
+saved in the current directory under its CBM name and type.
+
+Examples:
+  c64u drives get 8 "HIGHSCORES"
+  c64u drives get 8 "LEVEL*" -o level.seq`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		drive, name := args[0], args[1]
+
+		_, remote, err := mountedImage(drive)
+		if err != nil {
+			formatter.Error("No usable image in drive", []string{err.Error()})
+			return
+		}
+
+		img, tmp, err := fetchMountedImage(remote)
+		if err != nil {
+			formatter.Error("Failed to fetch image", []string{err.Error()})
+			return
+		}
+		os.Remove(tmp)
+
+		entry, err := img.Find(name)
+		if err != nil {
+			formatter.Error("File not found", []string{err.Error()})
+			return
+		}
+
+		if !entry.IsFile() {
+			formatter.Error("Not a file", []string{fmt.Sprintf("%s is a %s entry", entry.Name, entry.Type)})
+			return
+		}
+
+		data, ext, err := readEntry(img, entry)
+		if err != nil {
+			formatter.Error("Failed to read file", []string{err.Error()})
+			return
+		}
+
+		out := drivesGetOutput
+		if out == "" {
+			out = diskimage.LocalFileName(entry.Name, ext, map[string]bool{})
+		}
+		if err := os.WriteFile(out, data, 0644); err != nil {
+			formatter.Error("Failed to write file", []string{err.Error()})
+			return
+		}
+
+		formatter.Success(fmt.Sprintf("Extracted %s", entry.Name), map[string]interface{}{
+			"file":  out,
+			"type":  entry.Type,
+			"bytes": len(data),
+		})
+	},
+}
+
+// mountedImage returns the drive and the remote path of its mounted image
+func mountedImage(drive string) (*api.DriveInfo, string, error) {
+	info, err := apiClient.FindDrive(drive)
+	if err != nil {
+		return nil, "", err
+	}
+
+	remote := info.MountedImage()
+	if remote == "" {
+		return nil, "", fmt.Errorf("drive %s has no image mounted", drive)
+	}
+	switch strings.ToLower(path.Ext(remote)) {
+	case ".d64", ".d71", ".d81":
+	default:
+		return nil, "", fmt.Errorf("%s: only d64, d71 and d81 images can be edited", remote)
+	}
+
+	return info, remote, nil
+}
+
+// fetchMountedImage downloads a remote image into a temp file and opens it.
+// The caller removes the temp file.
+func fetchMountedImage(remote string) (*diskimage.Image, string, error) {
+	tmp, err := os.CreateTemp("", "c64u-*"+strings.ToLower(path.Ext(remote)))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmp.Close()
+
+	if err := apiClient.FTPDownload(remote, tmp.Name()); err != nil {
+		os.Remove(tmp.Name())
+		return nil, "", err
+	}
+
+	img, err := diskimage.Open(tmp.Name())
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, "", err
+	}
+
+	return img, tmp.Name(), nil
+}
+
+func init() {
+	drivesPutCmd.Flags().StringVar(&drivesPutName, "name", "", "CBM file name (default: local file name)")
+	drivesPutCmd.Flags().StringVar(&drivesPutType, "type", "", "CBM file type: prg, seq, usr (default: from extension)")
+	drivesPutCmd.Flags().BoolVar(&drivesPutOverwrite, "overwrite", false, "Replace a file with the same name")
+	drivesPutCmd.Flags().StringVar(&drivesPutMode, "mode", "readwrite", "Mount mode if the drive does not report one")
+	drivesGetCmd.Flags().StringVarP(&drivesGetOutput, "output", "o", "", "Local output file")
+
+	drivesCmd.AddCommand(drivesPutCmd)
+	drivesCmd.AddCommand(drivesGetCmd)
+}
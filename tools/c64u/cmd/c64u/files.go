@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// FILES COMMANDS
+// ============================================================================
+
+var (
+	filesTracks   int
+	filesDiskName string
+)
+
+var filesCmd = &cobra.Command{
+	Use:   "files",
+	Short: "File information and disk image creation",
+	Long: `Query files on the C64 Ultimate filesystem and create empty disk images.
+
+Paths refer to the C64 Ultimate filesystem. Use "c64u fs" to transfer files.`,
+}
+
+// ============================================================================
+// FILES INFO - Show file information
+// ============================================================================
+
+var filesInfoCmd = &cobra.Command{
+	Use:   "info <path>",
+	Short: "Show file information",
+	Long: `Show the size and extension of a file. Wildcards are supported.
+
+Examples:
+  c64u files info /USB0/games/game.prg
+  c64u files info "/USB0/games/*.d64"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.FilesInfo(args[0])
+		if err != nil {
+			formatter.Error("Failed to get file info", []string{err.Error()})
+			return
+		}
+
+		if resp.HasErrors() {
+			formatter.Error("API returned errors", resp.Errors)
+			return
+		}
+
+		formatter.PrintData(resp.Data)
+	},
+}
+
+// ============================================================================
+// FILES CREATE-D64/D71/D81/DNP - Create empty disk images
+// ============================================================================
+
+var filesCreateD64Cmd = &cobra.Command{
+	Use:   "create-d64 <path>",
+	Short: "Create an empty D64 image",
+	Long: `Create an empty, formatted D64 image with 35 or 40 tracks.
+
+Examples:
+  c64u files create-d64 /USB0/disks/new.d64
+  c64u files create-d64 /USB0/disks/new.d64 --tracks 40 --name "MY DISK"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if filesTracks != 0 && filesTracks != 35 && filesTracks != 40 {
+			formatter.Error("Invalid track count", []string{"D64 images have 35 or 40 tracks"})
+			return
+		}
+
+		resp, err := apiClient.FilesCreateD64(args[0], filesTracks, filesDiskName)
+		if err != nil {
+			formatter.Error("Failed to create D64 image", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Created %s", args[0]))
+	},
+}
+
+var filesCreateD71Cmd = &cobra.Command{
+	Use:   "create-d71 <path>",
+	Short: "Create an empty D71 image",
+	Long: `Create an empty, formatted double-sided D71 image (70 tracks).
+
+Examples:
+  c64u files create-d71 /USB0/disks/new.d71 --name "MY DISK"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.FilesCreateD71(args[0], filesDiskName)
+		if err != nil {
+			formatter.Error("Failed to create D71 image", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Created %s", args[0]))
+	},
+}
+
+var filesCreateD81Cmd = &cobra.Command{
+	Use:   "create-d81 <path>",
+	Short: "Create an empty D81 image",
+	Long: `Create an empty, formatted D81 image (80 tracks, 2 sides).
+
+Examples:
+  c64u files create-d81 /USB0/disks/new.d81 --name "MY DISK"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.FilesCreateD81(args[0], filesDiskName)
+		if err != nil {
+			formatter.Error("Failed to create D81 image", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Created %s", args[0]))
+	},
+}
+
+var filesCreateDNPCmd = &cobra.Command{
+	Use:   "create-dnp <path>",
+	Short: "Create an empty DNP image",
+	Long: `Create an empty CMD native partition image. Each track holds 256
+blocks, up to 255 tracks (about 16MB).
+
+Examples:
+  c64u files create-dnp /USB0/disks/work.dnp --tracks 64 --name "WORK"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if filesTracks < 1 || filesTracks > 255 {
+			formatter.Error("Invalid track count", []string{"--tracks must be between 1 and 255"})
+			return
+		}
+
+		resp, err := apiClient.FilesCreateDNP(args[0], filesTracks, filesDiskName)
+		if err != nil {
+			formatter.Error("Failed to create DNP image", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Created %s", args[0]))
+	},
+}
+
+func init() {
+	filesCreateD64Cmd.Flags().IntVar(&filesTracks, "tracks", 0, "Number of tracks: 35 or 40 (default: 35)")
+	filesCreateDNPCmd.Flags().IntVar(&filesTracks, "tracks", 0, "Number of tracks (1-255, required)")
+	filesCreateDNPCmd.MarkFlagRequired("tracks")
+	for _, cmd := range []*cobra.Command{filesCreateD64Cmd, filesCreateD71Cmd, filesCreateD81Cmd, filesCreateDNPCmd} {
+		cmd.Flags().StringVar(&filesDiskName, "name", "", "Disk name")
+	}
+
+	filesCmd.AddCommand(filesInfoCmd)
+	filesCmd.AddCommand(filesCreateD64Cmd)
+	filesCmd.AddCommand(filesCreateD71Cmd)
+	filesCmd.AddCommand(filesCreateD81Cmd)
+	filesCmd.AddCommand(filesCreateDNPCmd)
+}
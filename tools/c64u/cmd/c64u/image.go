@@ -0,0 +1,886 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/api"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/diskimage"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// DISK IMAGE COMMANDS (local D64/D71/D81 files)
+// ============================================================================
+
+var (
+	imagePackName   string
+	imagePackID     string
+	imagePackTracks int
+	imagePackUpload string
+	imagePackMount  string
+	imageDiffDump   bool
+	imageBlockWrite string
+	imageBlockOff   string
+	imageExtractOut string
+	imageAddName    string
+	imageAddType    string
+	imageAddForce   bool
+)
+
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Work with local disk images",
+	Long: `Create and inspect local D64, D71, D81 and DNP disk images.
+
+Disk contents can be unpacked into a folder of plain files with a JSON
+manifest, kept under version control, and packed back into an image.`,
+}
+
+// ============================================================================
+// IMAGE LS - List a directory of a disk image
+// ============================================================================
+
+var imageLsCmd = &cobra.Command{
+	Use:   "ls <image> [dir]",
+	Short: "List the directory of a disk image",
+	Long: `List the files of a disk image like the C64 shows them.
+
+On DNP images (CMD native partitions) a subdirectory path can be given,
+using "/" as separator.
+
+Examples:
+  c64u image ls game.d64
+  c64u image ls dev.dnp TOOLS/ASM
+  c64u --json image ls dev.dnp`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+
+		img, err := diskimage.Open(path)
+		if err != nil {
+			formatter.Error("Failed to open image", []string{err.Error()})
+			return
+		}
+
+		if len(args) > 1 {
+			if err := img.ChDir(args[1]); err != nil {
+				formatter.Error("Failed to change directory", []string{err.Error()})
+				return
+			}
+		}
+
+		entries, err := img.Directory()
+		if err != nil {
+			formatter.Warning(err.Error())
+		}
+
+		if jsonOut {
+			formatter.PrintData(map[string]interface{}{
+				"format":      img.Format.String(),
+				"disk_name":   diskimage.DisplayName(img.DiskName()),
+				"disk_id":     diskimage.DisplayName(img.DiskID()),
+				"entries":     entries,
+				"blocks_free": img.FreeBlocks(),
+			})
+			return
+		}
+
+		formatter.PrintHeader(fmt.Sprintf("💾 %s", filepath.Base(path)))
+		fmt.Println()
+		formatter.PrintKeyValue("Disk Name", fmt.Sprintf("%q", diskimage.DisplayName(img.DiskName())))
+		formatter.PrintKeyValue("Disk ID / DOS", fmt.Sprintf("%s / %s",
+			diskimage.DisplayName(img.DiskID()), diskimage.DisplayName(img.DOSType())))
+		formatter.PrintKeyValue("Format", img.Format.String())
+		if len(args) > 1 {
+			formatter.PrintKeyValue("Directory", args[1])
+		}
+		fmt.Println()
+
+		headers := []string{"Blocks", "Name", "Type", "Start"}
+		hasGEOS := false
+		for i := range entries {
+			hasGEOS = hasGEOS || entries[i].IsGEOS()
+		}
+		if hasGEOS {
+			headers = append(headers, "GEOS")
+		}
+
+		var rows [][]string
+		for i := range entries {
+			e := &entries[i]
+			row := []string{
+				fmt.Sprintf("%d", e.Blocks),
+				fmt.Sprintf("%q", e.Name),
+				dirTypeLabel(e),
+				fmt.Sprintf("%d/%d", e.Track, e.Sector),
+			}
+			if hasGEOS {
+				row = append(row, geosLabel(e))
+			}
+			rows = append(rows, row)
+		}
+		formatter.PrintTable(headers, rows)
+		fmt.Println()
+		fmt.Printf("%d BLOCKS FREE.\n", img.FreeBlocks())
+	},
+}
+
+// ============================================================================
+// IMAGE EXTRACT - Copy one file out of a disk image
+// ============================================================================
+
+var imageExtractCmd = &cobra.Command{
+	Use:   "extract <image> <name>",
+	Short: "Extract a file from a disk image",
+	Long: `Copy one file out of a disk image. The name may use the CBM wildcards
+* and ? and, on DNP images, a subdirectory path. Without --output the file
+is saved in the current directory under its CBM name and type. GEOS files
+(sequential and VLIR) are written in CVT format.
+
+Examples:
+  c64u image extract game.d64 "GAME"
+  c64u image extract dev.dnp TOOLS/ASM/TURBO* -o turbo.prg`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		img, err := diskimage.Open(args[0])
+		if err != nil {
+			formatter.Error("Failed to open image", []string{err.Error()})
+			return
+		}
+
+		entry, err := img.Lookup(args[1])
+		if err != nil {
+			formatter.Error("File not found", []string{err.Error()})
+			return
+		}
+		if !entry.IsFile() {
+			formatter.Error("Not a file", []string{fmt.Sprintf("%s is a %s entry", entry.Name, entry.Type)})
+			return
+		}
+
+		data, ext, err := readEntry(img, entry)
+		if err != nil {
+			formatter.Error("Failed to read file", []string{err.Error()})
+			return
+		}
+
+		out := imageExtractOut
+		if out == "" {
+			out = diskimage.LocalFileName(entry.Name, ext, map[string]bool{})
+		}
+		if err := os.WriteFile(out, data, 0644); err != nil {
+			formatter.Error("Failed to write file", []string{err.Error()})
+			return
+		}
+
+		formatter.Success(fmt.Sprintf("Extracted %s", entry.Name), map[string]interface{}{
+			"file":  out,
+			"type":  entry.Type,
+			"bytes": len(data),
+		})
+	},
+}
+
+// ============================================================================
+// IMAGE ADD - Write a local file into a disk image
+// ============================================================================
+
+var imageAddCmd = &cobra.Command{
+	Use:   "add <image> <local-file>",
+	Short: "Add a file to a disk image",
+	Long: `Write a local file into a disk image and save the image.
+
+The CBM name defaults to the local file name in upper case and the type to
+the file extension (.prg, .seq, .usr; anything else is PRG). On DNP images
+the name may include a subdirectory path. GEOS files in CVT format are
+imported with their info block and VLIR records under the stored name.
+
+Examples:
+  c64u image add game.d64 intro.prg
+  c64u image add geoswork.d64 geowrite.cvt
+  c64u image add game.d64 scores.bin --name "HIGHSCORES" --type seq
+  c64u image add dev.dnp build/asm.prg --name TOOLS/ASM/ASM --overwrite`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+
+		img, err := diskimage.Open(path)
+		if err != nil {
+			formatter.Error("Failed to open image", []string{err.Error()})
+			return
+		}
+
+		entry, err := addLocalFile(img, args[1], imageAddName, imageAddType, imageAddForce)
+		if err != nil {
+			formatter.Error("Failed to add file", []string{err.Error()})
+			return
+		}
+
+		if err := img.Save(path); err != nil {
+			formatter.Error("Failed to save image", []string{err.Error()})
+			return
+		}
+
+		formatter.Success(fmt.Sprintf("Added %s to %s", entry.Name, filepath.Base(path)), map[string]interface{}{
+			"type":        entry.Type,
+			"blocks":      entry.Blocks,
+			"blocks_free": img.FreeBlocks(),
+		})
+	},
+}
+
+// ============================================================================
+// IMAGE GEOS - Show the GEOS info block of a file
+// ============================================================================
+
+var imageGeosCmd = &cobra.Command{
+	Use:   "geos <image> <name>",
+	Short: "Show GEOS file information",
+	Long: `Show the GEOS header of a file: file type, structure (sequential or VLIR),
+class name, author, description, addresses, the icon and, for VLIR files,
+the record index.
+
+Examples:
+  c64u image geos geoswork.d64 "GEOWRITE"
+  c64u --json image geos geoswork.d64 "DESK*"`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		img, err := diskimage.Open(args[0])
+		if err != nil {
+			formatter.Error("Failed to open image", []string{err.Error()})
+			return
+		}
+
+		entry, err := img.Lookup(args[1])
+		if err != nil {
+			formatter.Error("File not found", []string{err.Error()})
+			return
+		}
+
+		g, err := img.GEOSInfo(entry)
+		if err != nil && g == nil {
+			formatter.Error("Failed to read GEOS header", []string{err.Error()})
+			return
+		}
+		if err != nil {
+			formatter.Warning(err.Error())
+		}
+
+		if jsonOut {
+			formatter.PrintData(map[string]interface{}{
+				"entry": entry,
+				"geos":  g,
+				"icon":  g.IconRows(),
+			})
+			return
+		}
+
+		formatter.PrintHeader(fmt.Sprintf("💾 %s", entry.Name))
+		fmt.Println()
+		formatter.PrintKeyValue("CBM Type", dirTypeLabel(entry))
+		formatter.PrintKeyValue("GEOS Type", fmt.Sprintf("%d (%s)", g.FileType, g.FileTypeName))
+		formatter.PrintKeyValue("Structure", g.Structure)
+		formatter.PrintKeyValue("Class", g.ClassName)
+		if g.Author != "" {
+			formatter.PrintKeyValue("Author", g.Author)
+		}
+		if g.Parent != "" {
+			formatter.PrintKeyValue("Application", g.Parent)
+		}
+		if g.Date != "" {
+			formatter.PrintKeyValue("Date", g.Date)
+		}
+		formatter.PrintKeyValue("Load / End / Start", fmt.Sprintf("$%04X / $%04X / $%04X", g.LoadAddress, g.EndAddress, g.StartAddress))
+		formatter.PrintKeyValue("Info Block", fmt.Sprintf("%d/%d", g.InfoTrack, g.InfoSector))
+		if g.Description != "" {
+			formatter.PrintKeyValue("Description", g.Description)
+		}
+		fmt.Println()
+
+		for _, row := range g.IconRows() {
+			fmt.Printf("  |%s|\n", row)
+		}
+
+		if len(g.Records) > 0 {
+			fmt.Println()
+			var rows [][]string
+			for _, r := range g.Records {
+				if r.Empty {
+					rows = append(rows, []string{fmt.Sprintf("%d", r.Index), "empty", "", ""})
+					continue
+				}
+				rows = append(rows, []string{
+					fmt.Sprintf("%d", r.Index),
+					fmt.Sprintf("%d/%d", r.Track, r.Sector),
+					fmt.Sprintf("%d", r.Blocks),
+					fmt.Sprintf("%d", r.Size),
+				})
+			}
+			formatter.PrintTable([]string{"Record", "Start", "Blocks", "Bytes"}, rows)
+		}
+	},
+}
+
+// geosLabel describes the GEOS type and structure of a directory entry
+func geosLabel(e *diskimage.DirEntry) string {
+	if !e.IsGEOS() {
+		return ""
+	}
+	if e.VLIR {
+		return diskimage.GEOSTypeName(e.GEOSType) + " (VLIR)"
+	}
+	return diskimage.GEOSTypeName(e.GEOSType)
+}
+
+// readEntry returns the contents of a file and the extension to save it
+// under. GEOS files are converted to CVT.
+func readEntry(img *diskimage.Image, entry *diskimage.DirEntry) ([]byte, string, error) {
+	switch {
+	case entry.IsGEOS():
+		data, err := img.ExportCVT(entry)
+		return data, "cvt", err
+	case entry.Track == 0:
+		return nil, entry.Type, nil
+	default:
+		data, err := img.ReadFile(entry)
+		return data, entry.Type, err
+	}
+}
+
+// addLocalFile writes a local file into an image. An empty name or type is
+// derived from the local file name; the name may carry a directory path on
+// DNP images. CVT files are imported as GEOS files under their stored name
+// unless a type is forced. An existing file is only replaced if overwrite
+// is set.
+func addLocalFile(img *diskimage.Image, localFile, name, typeName string, overwrite bool) (*diskimage.DirEntry, error) {
+	data, err := os.ReadFile(localFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", localFile, err)
+	}
+
+	geos := typeName == "" && diskimage.IsCVT(data)
+	dir, base := diskimage.SplitPath(name)
+
+	var rawName []byte
+	switch {
+	case base != "":
+		rawName = petscii.FromASCII(base)
+	case geos:
+		rawName = diskimage.CVTName(data)
+	default:
+		stem := strings.TrimSuffix(filepath.Base(localFile), filepath.Ext(localFile))
+		rawName = petscii.FromASCII(strings.ToUpper(stem))
+	}
+	if len(rawName) > 16 {
+		rawName = rawName[:16]
+	}
+
+	if err := img.ChDir(dir); err != nil {
+		return nil, err
+	}
+
+	if existing, err := img.FindRaw(rawName); err == nil {
+		if !overwrite {
+			return nil, fmt.Errorf("%q is already on the disk, use --overwrite to replace it", existing.Name)
+		}
+		if err := img.DeleteFile(existing); err != nil {
+			return nil, err
+		}
+	}
+
+	if geos {
+		return img.ImportCVT(data, rawName)
+	}
+
+	if typeName == "" {
+		typeName = "prg"
+		ext := strings.TrimPrefix(filepath.Ext(localFile), ".")
+		if _, err := diskimage.ParseTypeName(ext); err == nil {
+			typeName = ext
+		}
+	}
+	fileType, err := diskimage.ParseTypeName(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	return img.AddFile(diskimage.NewFile{
+		Name:   rawName,
+		Type:   fileType,
+		Closed: true,
+		Data:   data,
+	})
+}
+
+// ============================================================================
+// IMAGE PACK - Build a disk image from a folder
+// ============================================================================
+
+var imagePackCmd = &cobra.Command{
+	Use:   "pack <dir> <image>",
+	Short: "Pack a folder into a disk image",
+	Long: `Build a disk image from the files in a folder.
+
+The image type is taken from the output extension (.d64, .d71, .d81,
+.dnp). DNP images need --tracks unless the manifest records it.
+If the folder contains a manifest.json written by 'image unpack', the disk
+name, ID, directory order, file types, locked flags and raw PETSCII names
+are restored from it. Other files are added after the listed ones in name
+order, typed by extension (.prg, .seq, .usr, .del; anything else is PRG).
+
+Packing is deterministic: the same folder always produces the same image.
+
+Examples:
+  c64u image pack ./disk game.d64
+  c64u image pack ./disk game.d81 --name "MY GAME" --id 01
+  c64u image pack ./disk game.d64 --mount 8`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, out := args[0], args[1]
+
+		// Keep a 40-track layout recorded in the manifest unless overridden
+		tracks := imagePackTracks
+		if m, err := diskimage.LoadManifest(filepath.Join(dir, diskimage.ManifestFile)); err == nil && tracks == 0 {
+			if "."+m.Format == strings.ToLower(filepath.Ext(out)) {
+				tracks = m.Tracks
+			}
+		}
+
+		f, err := diskimage.FormatByName(filepath.Ext(out), tracks)
+		if err != nil {
+			formatter.Error("Unsupported image type", []string{err.Error()})
+			return
+		}
+
+		img, m, err := diskimage.Pack(dir, diskimage.PackOptions{
+			Format:   f,
+			DiskName: imagePackName,
+			DiskID:   imagePackID,
+		})
+		if err != nil {
+			formatter.Error("Failed to pack image", []string{err.Error()})
+			return
+		}
+
+		if err := img.Save(out); err != nil {
+			formatter.Error("Failed to save image", []string{err.Error()})
+			return
+		}
+
+		formatter.Success(fmt.Sprintf("Packed %d files into %s", len(m.Files), out), map[string]interface{}{
+			"format":      img.Format.String(),
+			"disk_name":   m.DiskName,
+			"blocks_free": img.FreeBlocks(),
+		})
+
+		if imagePackUpload != "" {
+			if err := apiClient.FTPUpload(out, imagePackUpload); err != nil {
+				formatter.Error("Upload failed", []string{err.Error()})
+				return
+			}
+			formatter.Success("Uploaded image", map[string]interface{}{"remote": imagePackUpload})
+		}
+
+		if imagePackMount != "" {
+			resp, err := apiClient.DrivesMountUpload(imagePackMount, out, img.Format.Name, "")
+			if err != nil {
+				formatter.Error("Failed to mount image", []string{err.Error()})
+				return
+			}
+			formatter.PrintResponse(resp, fmt.Sprintf("Mounted on drive %s", imagePackMount))
+		}
+	},
+}
+
+// ============================================================================
+// IMAGE UNPACK - Extract all files of a disk image into a folder
+// ============================================================================
+
+var imageUnpackCmd = &cobra.Command{
+	Use:   "unpack <image> <dir>",
+	Short: "Unpack a disk image into a folder",
+	Long: `Extract every file of a disk image into a folder and write a
+manifest.json that preserves the disk header, CBM file types, locked
+flags, raw PETSCII names and directory order.
+
+REL files, CBM partitions and subdirectories are skipped with a warning,
+as "image pack" cannot recreate them.
+
+Examples:
+  c64u image unpack game.d64 ./disk
+  c64u image pack ./disk rebuilt.d64`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, dir := args[0], args[1]
+
+		img, err := diskimage.Open(path)
+		if err != nil {
+			formatter.Error("Failed to open image", []string{err.Error()})
+			return
+		}
+
+		m, err := diskimage.Unpack(img, dir)
+		if err != nil {
+			formatter.Error("Failed to unpack image", []string{err.Error()})
+			return
+		}
+
+		for _, s := range m.Skipped {
+			formatter.Warning(fmt.Sprintf("Skipped %s", s))
+		}
+
+		formatter.Success(fmt.Sprintf("Unpacked %d files from %s", len(m.Files), filepath.Base(path)), map[string]interface{}{
+			"dir":       dir,
+			"disk_name": m.DiskName,
+			"manifest":  filepath.Join(dir, diskimage.ManifestFile),
+		})
+	},
+}
+
+// ============================================================================
+// IMAGE DIFF - Compare two disk images
+// ============================================================================
+
+var imageDiffCmd = &cobra.Command{
+	Use:   "diff <image-a> <image-b>",
+	Short: "Compare two disk images",
+	Long: `Compare two disk images and show what changed from the first to the second:
+disk name and ID, directory entries (added, removed, or changed by content
+hash, type or size), BAM allocation differences and raw changed sectors.
+
+BAM and sector comparisons require both images to have the same format.
+With --sectors, every differing block is hex-dumped from both images.
+
+Examples:
+  c64u image diff before.d64 after.d64
+  c64u image diff before.d64 after.d64 --sectors
+  c64u --json image diff before.d81 after.d81`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		a, err := diskimage.Open(args[0])
+		if err != nil {
+			formatter.Error("Failed to open image", []string{fmt.Sprintf("%s: %v", args[0], err)})
+			return
+		}
+		b, err := diskimage.Open(args[1])
+		if err != nil {
+			formatter.Error("Failed to open image", []string{fmt.Sprintf("%s: %v", args[1], err)})
+			return
+		}
+
+		d, err := diskimage.Compare(a, b)
+		if err != nil {
+			formatter.Error("Failed to compare images", []string{err.Error()})
+			return
+		}
+
+		if jsonOut {
+			formatter.PrintData(d)
+			return
+		}
+
+		formatter.PrintHeader(fmt.Sprintf("💾 %s → %s", filepath.Base(args[0]), filepath.Base(args[1])))
+		fmt.Println()
+
+		if d.IsEmpty() {
+			formatter.Success("Images are identical", nil)
+			return
+		}
+
+		if !d.SameGeometry {
+			formatter.Warning(fmt.Sprintf("Formats differ (%s vs %s), only comparing headers and files", a.Format, b.Format))
+		}
+		if d.DiskNameA != d.DiskNameB {
+			formatter.PrintKeyValue("Disk Name", fmt.Sprintf("%q → %q", d.DiskNameA, d.DiskNameB))
+		}
+		if d.DiskIDA != d.DiskIDB {
+			formatter.PrintKeyValue("Disk ID", fmt.Sprintf("%q → %q", d.DiskIDA, d.DiskIDB))
+		}
+		formatter.PrintKeyValue("Blocks Free", fmt.Sprintf("%d → %d", d.FreeA, d.FreeB))
+		fmt.Println()
+
+		if len(d.Files) > 0 {
+			var rows [][]string
+			for _, f := range d.Files {
+				rows = append(rows, []string{
+					diffMarker(f.Status) + " " + f.Status,
+					fmt.Sprintf("%q", f.Name),
+					diffPair(f.TypeA, f.TypeB),
+					diffPair(diffBlocks(f.BlocksA, f.TypeA), diffBlocks(f.BlocksB, f.TypeB)),
+					strings.Join(diffChanges(f), ", "),
+				})
+			}
+			formatter.PrintTable([]string{"Status", "Name", "Type", "Blocks", "Changes"}, rows)
+			fmt.Println()
+		}
+
+		if len(d.BAM) > 0 {
+			var rows [][]string
+			for _, bd := range d.BAM {
+				rows = append(rows, []string{
+					fmt.Sprintf("%d", bd.Track),
+					formatSectorList(bd.Allocated),
+					formatSectorList(bd.Freed),
+				})
+			}
+			formatter.PrintTable([]string{"Track", "Allocated", "Freed"}, rows)
+			fmt.Println()
+		}
+
+		if len(d.Sectors) > 0 {
+			formatter.PrintKeyValue("Changed Sectors", fmt.Sprintf("%d", len(d.Sectors)))
+			if !imageDiffDump {
+				var list []string
+				for _, ts := range d.Sectors {
+					list = append(list, fmt.Sprintf("%d/%d", ts.Track, ts.Sector))
+				}
+				fmt.Printf("  %s\n", strings.Join(list, " "))
+				return
+			}
+
+			for _, ts := range d.Sectors {
+				sa, _ := a.Sector(ts.Track, ts.Sector)
+				sb, _ := b.Sector(ts.Track, ts.Sector)
+				fmt.Println()
+				formatter.PrintHeader(fmt.Sprintf("Track %d Sector %d", ts.Track, ts.Sector))
+				fmt.Printf("--- %s\n%s", filepath.Base(args[0]), api.FormatMemoryDump(sa, 0))
+				fmt.Printf("+++ %s\n%s", filepath.Base(args[1]), api.FormatMemoryDump(sb, 0))
+			}
+		}
+	},
+}
+
+// ============================================================================
+// IMAGE BLOCK - View and patch a single track/sector
+// ============================================================================
+
+var imageBlockCmd = &cobra.Command{
+	Use:   "block <image> <track> <sector>",
+	Short: "Show or patch a single disk block",
+	Long: `Hex-dump one block of a disk image with annotations: the link to the
+next block (or the number of bytes used in a chain's last block), the BAM
+state, which file the block belongs to, the disk header, directory entries
+on directory blocks and the decoded BAM bitmaps on BAM blocks.
+
+With --write, hex bytes are patched into the block at --offset and the
+image is saved before the block is shown.
+
+Examples:
+  c64u image block game.d64 18 0
+  c64u image block game.d64 18 1
+  c64u image block game.d64 17 0 --offset 0x02 --write "A9 00 8D 20 D0"
+  c64u --json image block game.d81 40 1`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+
+		track, err := strconv.Atoi(args[1])
+		if err != nil {
+			formatter.Error("Invalid track", []string{err.Error()})
+			return
+		}
+		sector, err := strconv.Atoi(args[2])
+		if err != nil {
+			formatter.Error("Invalid sector", []string{err.Error()})
+			return
+		}
+
+		img, err := diskimage.Open(path)
+		if err != nil {
+			formatter.Error("Failed to open image", []string{err.Error()})
+			return
+		}
+
+		if imageBlockWrite != "" {
+			offset, err := strconv.ParseInt(strings.Replace(imageBlockOff, "$", "0x", 1), 0, 32)
+			if err != nil {
+				formatter.Error("Invalid offset", []string{err.Error()})
+				return
+			}
+			patch, err := hex.DecodeString(strings.Join(strings.Fields(imageBlockWrite), ""))
+			if err != nil {
+				formatter.Error("Invalid hex data", []string{err.Error()})
+				return
+			}
+			if err := img.WriteBlock(track, sector, int(offset), patch); err != nil {
+				formatter.Error("Failed to patch block", []string{err.Error()})
+				return
+			}
+			if err := img.Save(path); err != nil {
+				formatter.Error("Failed to save image", []string{err.Error()})
+				return
+			}
+			formatter.Success(fmt.Sprintf("Wrote %d bytes at offset $%02X", len(patch), offset), nil)
+		}
+
+		info, err := img.Inspect(track, sector)
+		if err != nil {
+			formatter.Error("Invalid block", []string{err.Error()})
+			return
+		}
+		data, _ := img.Sector(track, sector)
+
+		if jsonOut {
+			formatter.PrintData(map[string]interface{}{
+				"block": info,
+				"data":  hex.EncodeToString(data),
+			})
+			return
+		}
+
+		formatter.PrintHeader(fmt.Sprintf("💾 %s  Track %d Sector %d", filepath.Base(path), track, sector))
+		fmt.Println()
+		formatter.PrintKeyValue("Image Offset", fmt.Sprintf("$%05X", info.Offset))
+		formatter.PrintKeyValue("Role", strings.Join(info.Roles, ", "))
+		if info.Free {
+			formatter.PrintKeyValue("BAM", "free")
+		} else {
+			formatter.PrintKeyValue("BAM", "allocated")
+		}
+		if info.IsLast() {
+			formatter.PrintKeyValue("Link", fmt.Sprintf("last block, %d bytes used ($01 = $%02X)", info.BytesUsed, info.NextSector))
+		} else {
+			formatter.PrintKeyValue("Link", fmt.Sprintf("next block %d/%d", info.NextTrack, info.NextSector))
+		}
+		if info.Owner != "" {
+			formatter.PrintKeyValue("File", fmt.Sprintf("%q, block %d of its chain", info.Owner, info.ChainIndex+1))
+		}
+		if info.DiskName != "" || info.DiskID != "" {
+			formatter.PrintKeyValue("Disk Name", fmt.Sprintf("%q", info.DiskName))
+			formatter.PrintKeyValue("Disk ID / DOS", fmt.Sprintf("%s / %s", info.DiskID, info.DOSType))
+		}
+		fmt.Println()
+
+		fmt.Print(api.FormatMemoryDump(data, 0))
+
+		if len(info.Entries) > 0 {
+			fmt.Println()
+			var rows [][]string
+			for _, e := range info.Entries {
+				rows = append(rows, []string{
+					fmt.Sprintf("$%02X", e.EntryOffset),
+					dirTypeLabel(&e),
+					fmt.Sprintf("%q", e.Name),
+					fmt.Sprintf("%d/%d", e.Track, e.Sector),
+					fmt.Sprintf("%d", e.Blocks),
+				})
+			}
+			formatter.PrintTable([]string{"Entry", "Type", "Name", "Start", "Blocks"}, rows)
+		}
+
+		if len(info.BAM) > 0 {
+			fmt.Println()
+			var rows [][]string
+			for _, u := range info.BAM {
+				free := fmt.Sprintf("%d", u.FreeCount)
+				if n := strings.Count(u.Bitmap, "."); n != u.FreeCount {
+					free += fmt.Sprintf(" (bitmap: %d)", n)
+				}
+				rows = append(rows, []string{fmt.Sprintf("%d", u.Track), free, u.Bitmap})
+			}
+			formatter.PrintTable([]string{"Track", "Free", "Sectors (. free, # used)"}, rows)
+		}
+	},
+}
+
+// dirTypeLabel formats a file type the way a directory listing shows it,
+// e.g. "*PRG" for an unclosed file and "SEQ<" for a locked one
+func dirTypeLabel(e *diskimage.DirEntry) string {
+	label := e.Type
+	if !e.Closed {
+		label = "*" + label
+	}
+	if e.Locked {
+		label += "<"
+	}
+	return label
+}
+
+// diffMarker returns the +/-/~ marker for a file diff status
+func diffMarker(status string) string {
+	switch status {
+	case diskimage.DiffAdded:
+		return "+"
+	case diskimage.DiffRemoved:
+		return "-"
+	case diskimage.DiffBroken:
+		return "!"
+	default:
+		return "~"
+	}
+}
+
+// diffChanges lists the changes of a file, followed by read errors
+func diffChanges(f diskimage.FileDiff) []string {
+	changes := f.Changes
+	if f.ErrorA != "" {
+		changes = append(changes, "unreadable in A: "+f.ErrorA)
+	}
+	if f.ErrorB != "" {
+		changes = append(changes, "unreadable in B: "+f.ErrorB)
+	}
+	return changes
+}
+
+// diffPair formats an old and new value, collapsing equal or one-sided pairs
+func diffPair(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "", a == b:
+		return a
+	default:
+		return a + " → " + b
+	}
+}
+
+// diffBlocks formats a block count, empty if the file is absent on that side
+func diffBlocks(blocks int, fileType string) string {
+	if fileType == "" {
+		return ""
+	}
+	return fmt.Sprintf("%d", blocks)
+}
+
+// formatSectorList formats sector numbers as a comma separated list
+func formatSectorList(sectors []int) string {
+	parts := make([]string, len(sectors))
+	for i, s := range sectors {
+		parts[i] = fmt.Sprintf("%d", s)
+	}
+	return strings.Join(parts, ",")
+}
+
+func init() {
+	imagePackCmd.Flags().StringVar(&imagePackName, "name", "", "Disk name (default: from manifest or folder name)")
+	imagePackCmd.Flags().StringVar(&imagePackID, "id", "", "Two-character disk ID (default: from manifest or \"00\")")
+	imagePackCmd.Flags().IntVar(&imagePackTracks, "tracks", 0, "Number of tracks (d64: 35 or 40, dnp: 1-255)")
+	imagePackCmd.Flags().StringVar(&imagePackUpload, "upload", "", "Upload the image to this path on the C64 Ultimate via FTP")
+	imagePackCmd.Flags().StringVar(&imagePackMount, "mount", "", "Upload and mount the image on this drive (e.g. 8)")
+
+	imageDiffCmd.Flags().BoolVar(&imageDiffDump, "sectors", false, "Hex-dump every changed sector from both images")
+
+	imageExtractCmd.Flags().StringVarP(&imageExtractOut, "output", "o", "", "Local output file")
+
+	imageAddCmd.Flags().StringVar(&imageAddName, "name", "", "CBM file name, optionally with a DNP directory path")
+	imageAddCmd.Flags().StringVar(&imageAddType, "type", "", "CBM file type: prg, seq, usr, del (default: from extension)")
+	imageAddCmd.Flags().BoolVar(&imageAddForce, "overwrite", false, "Replace a file with the same name")
+
+	imageBlockCmd.Flags().StringVar(&imageBlockWrite, "write", "", "Hex bytes to patch into the block")
+	imageBlockCmd.Flags().StringVar(&imageBlockOff, "offset", "0", "Offset within the block for --write (decimal, 0x or $ hex)")
+
+	imageCmd.AddCommand(imageLsCmd)
+	imageCmd.AddCommand(imageExtractCmd)
+	imageCmd.AddCommand(imageAddCmd)
+	imageCmd.AddCommand(imageGeosCmd)
+	imageCmd.AddCommand(imagePackCmd)
+	imageCmd.AddCommand(imageUnpackCmd)
+	imageCmd.AddCommand(imageDiffCmd)
+	imageCmd.AddCommand(imageBlockCmd)
+}
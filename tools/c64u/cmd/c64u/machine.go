@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// MACHINE COMMANDS
+// ============================================================================
+
+var readMemLength int
+
+var machineCmd = &cobra.Command{
+	Use:   "machine",
+	Short: "Machine control and memory access",
+	Long: `Reset, pause and resume the C64, and read and write its memory via DMA.
+
+Addresses are given in hex, with or without a "$" or "0x" prefix.`,
+}
+
+// ============================================================================
+// MACHINE RESET/REBOOT/PAUSE/RESUME/POWEROFF/MENU-BUTTON - Control
+// ============================================================================
+
+var machineResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Reset the machine",
+	Long:  `Reset the C64 without changing the configuration.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.MachineReset()
+		if err != nil {
+			formatter.Error("Failed to reset machine", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, "Machine reset")
+	},
+}
+
+var machineRebootCmd = &cobra.Command{
+	Use:   "reboot",
+	Short: "Reboot the machine",
+	Long:  `Restart the C64 and re-initialise the cartridge.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.MachineReboot()
+		if err != nil {
+			formatter.Error("Failed to reboot machine", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, "Machine rebooted")
+	},
+}
+
+var machinePauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause the machine",
+	Long:  `Stop the CPU by pulling the DMA line low. Use "machine resume" to continue.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.MachinePause()
+		if err != nil {
+			formatter.Error("Failed to pause machine", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, "Machine paused")
+	},
+}
+
+var machineResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume the machine",
+	Long:  `Resume the CPU after "machine pause".`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.MachineResume()
+		if err != nil {
+			formatter.Error("Failed to resume machine", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, "Machine resumed")
+	},
+}
+
+var machinePowerOffCmd = &cobra.Command{
+	Use:   "poweroff",
+	Short: "Power off the machine (U64 only)",
+	Long:  `Power off the Ultimate 64. The device is no longer reachable afterwards.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.MachinePowerOff()
+		if err != nil {
+			formatter.Error("Failed to power off machine", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, "Machine powered off")
+	},
+}
+
+var machineMenuButtonCmd = &cobra.Command{
+	Use:   "menu-button",
+	Short: "Press the Menu button",
+	Long: `Simulate a press of the Menu button (a short press of the Multi Button
+on the Ultimate 64), which opens or closes the Ultimate menu.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.MachineMenuButton()
+		if err != nil {
+			formatter.Error("Failed to press menu button", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, "Menu button pressed")
+	},
+}
+
+// ============================================================================
+// MACHINE WRITE-MEM/WRITE-MEM-FILE/READ-MEM - Memory access
+// ============================================================================
+
+var machineWriteMemCmd = &cobra.Command{
+	Use:   "write-mem <address> <hex-data>",
+	Short: "Write hex data to memory",
+	Long: `Write up to 128 bytes of hex data to memory via DMA.
+
+Examples:
+  c64u machine write-mem d020 00
+  c64u machine write-mem $0400 0102030405`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		address := trimHexPrefix(args[0])
+		data := strings.ReplaceAll(args[1], " ", "")
+
+		resp, err := apiClient.MachineWriteMem(address, data)
+		if err != nil {
+			formatter.Error("Failed to write memory", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Wrote %d bytes to $%s", len(data)/2, strings.ToUpper(address)))
+	},
+}
+
+var machineWriteMemFileCmd = &cobra.Command{
+	Use:   "write-mem-file <address> <file>",
+	Short: "Write a file to memory",
+	Long: `Write the contents of a local binary file to memory via DMA. The file
+is written as-is; a PRG load address is not stripped.
+
+Examples:
+  c64u machine write-mem-file c000 routine.bin`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		address := trimHexPrefix(args[0])
+
+		resp, err := apiClient.MachineWriteMemFile(address, args[1])
+		if err != nil {
+			formatter.Error("Failed to write memory", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Wrote %s to $%s", args[1], strings.ToUpper(address)))
+	},
+}
+
+var machineReadMemCmd = &cobra.Command{
+	Use:   "read-mem <address>",
+	Short: "Read memory (hex dump)",
+	Long: `Read memory via DMA and show it as a hex dump.
+
+Examples:
+  c64u machine read-mem 0400
+  c64u machine read-mem $c000 --length 64`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		address := trimHexPrefix(args[0])
+		var start int
+		if _, err := fmt.Sscanf(address, "%x", &start); err != nil {
+			formatter.Error("Invalid address", []string{fmt.Sprintf("%q is not a hex address", args[0])})
+			return
+		}
+
+		resp, err := apiClient.MachineReadMem(address, readMemLength)
+		if err != nil {
+			formatter.Error("Failed to read memory", []string{err.Error()})
+			return
+		}
+
+		if resp.HasErrors() {
+			formatter.Error("API returned errors", resp.Errors)
+			return
+		}
+
+		if jsonOut {
+			formatter.PrintData(map[string]interface{}{
+				"address": fmt.Sprintf("%04X", start),
+				"length":  len(resp.RawBody),
+				"data":    fmt.Sprintf("%X", resp.RawBody),
+			})
+			return
+		}
+
+		fmt.Print(api.FormatMemoryDump(resp.RawBody, start))
+	},
+}
+
+// ============================================================================
+// MACHINE DEBUG-REG/DEBUG-REG-SET - Debug register (U64 only)
+// ============================================================================
+
+var machineDebugRegCmd = &cobra.Command{
+	Use:   "debug-reg",
+	Short: "Read the debug register (U64 only)",
+	Long:  `Read the Ultimate 64 debug register at $D7FF.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.MachineDebugReg()
+		if err != nil {
+			formatter.Error("Failed to read debug register", []string{err.Error()})
+			return
+		}
+
+		if resp.HasErrors() {
+			formatter.Error("API returned errors", resp.Errors)
+			return
+		}
+
+		formatter.PrintData(resp.Data)
+	},
+}
+
+var machineDebugRegSetCmd = &cobra.Command{
+	Use:   "debug-reg-set <hex-value>",
+	Short: "Write the debug register (U64 only)",
+	Long: `Write a hex value to the Ultimate 64 debug register at $D7FF.
+
+Examples:
+  c64u machine debug-reg-set 01`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		value := trimHexPrefix(args[0])
+
+		resp, err := apiClient.MachineDebugRegSet(value)
+		if err != nil {
+			formatter.Error("Failed to write debug register", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Debug register set to $%s", strings.ToUpper(value)))
+	},
+}
+
+// trimHexPrefix removes a "$" or "0x" prefix from a hex value
+func trimHexPrefix(s string) string {
+	s = strings.TrimPrefix(s, "$")
+	s = strings.TrimPrefix(s, "0x")
+	return strings.TrimPrefix(s, "0X")
+}
+
+func init() {
+	machineReadMemCmd.Flags().IntVar(&readMemLength, "length", 256, "Number of bytes to read")
+
+	machineCmd.AddCommand(machineResetCmd)
+	machineCmd.AddCommand(machineRebootCmd)
+	machineCmd.AddCommand(machinePauseCmd)
+	machineCmd.AddCommand(machineResumeCmd)
+	machineCmd.AddCommand(machinePowerOffCmd)
+	machineCmd.AddCommand(machineMenuButtonCmd)
+	machineCmd.AddCommand(machineWriteMemCmd)
+	machineCmd.AddCommand(machineWriteMemFileCmd)
+	machineCmd.AddCommand(machineReadMemCmd)
+	machineCmd.AddCommand(machineDebugRegCmd)
+	machineCmd.AddCommand(machineDebugRegSetCmd)
+}
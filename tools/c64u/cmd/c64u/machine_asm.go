@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/asm"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// MACHINE ASM - Assemble a snippet into memory
+// ============================================================================
+
+var (
+	asmFile   string
+	asmDryRun bool
+	asmPause  bool
+)
+
+var machineAsmCmd = &cobra.Command{
+	Use:   "asm <address> [line...]",
+	Short: "Assemble code directly into memory",
+	Long: `Assemble 6502/6510 code and write it to memory, like the VICE
+monitor's "a" command. Each argument is one source line; without lines the
+source is read from --file or stdin.
+
+Supported are labels ("loop:"), all addressing modes, undocumented opcodes
+by their KickAssembler names, "*=" to continue at another address, and the
+.byte, .word, .text, .const/.label (NAME = value) and .encoding directives. Operands may use $hex, %binary,
+decimal, 'c' characters, labels from --symbols, * for the current address,
++/- and the < and > byte operators. Comments start with // or ;.
+
+Examples:
+  c64u machine asm 1015 "nop" "nop" "nop"
+  c64u machine asm 100a "jmp Done" --symbols hello.sym
+  c64u machine asm c000 'loop: inc $d020' 'jmp loop'
+  c64u machine asm c000 --file patch.asm --dry-run
+  printf 'lda #$00\nsta $d020\n' | c64u machine asm 1000`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		start, err := parseAddress(args[0])
+		if err != nil {
+			formatter.Error("Invalid address", []string{err.Error()})
+			return
+		}
+
+		source, err := asmSource(args[1:])
+		if err != nil {
+			formatter.Error("Failed to read source", []string{err.Error()})
+			return
+		}
+
+		var resolver asm.Resolver
+		if t := currentSymbols(); t.Len() > 0 {
+			resolver = t
+		}
+		res, err := asm.Assemble(source, start, resolver)
+		if err != nil {
+			formatter.Error("Assembly failed", []string{err.Error()})
+			return
+		}
+		if res.Size() == 0 {
+			formatter.Error("Nothing to assemble", nil)
+			return
+		}
+
+		if !jsonOut {
+			for _, l := range res.Lines {
+				fmt.Printf("%04X  %-9s  %s\n", l.Address, l.Hex, l.Source)
+			}
+			fmt.Println()
+		}
+
+		if !asmDryRun {
+			if err := withPause(asmPause, func() error {
+				for _, seg := range res.Segments {
+					if err := apiClient.MachineWriteMemRange(seg.Start, seg.Data); err != nil {
+						return err
+					}
+				}
+				return nil
+			}); err != nil {
+				formatter.Error("Write failed", []string{err.Error()})
+				return
+			}
+		}
+
+		if jsonOut {
+			formatter.PrintData(map[string]interface{}{
+				"written": !asmDryRun,
+				"bytes":   res.Size(),
+				"lines":   res.Lines,
+				"labels":  res.Labels,
+			})
+			return
+		}
+
+		var ranges []string
+		for _, seg := range res.Segments {
+			ranges = append(ranges, fmt.Sprintf("$%04X-$%04X", seg.Start, seg.End()))
+		}
+		msg := fmt.Sprintf("Assembled %d bytes", res.Size())
+		if !asmDryRun {
+			msg = fmt.Sprintf("Wrote %d bytes", res.Size())
+		}
+		formatter.Success(msg, map[string]interface{}{
+			"range": strings.Join(ranges, ", "),
+		})
+	},
+}
+
+// asmSource joins the line arguments, or reads --file or stdin
+func asmSource(lines []string) (string, error) {
+	if len(lines) > 0 {
+		return strings.Join(lines, "\n"), nil
+	}
+	if asmFile != "" {
+		data, err := os.ReadFile(asmFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", asmFile, err)
+		}
+		return string(data), nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return string(data), nil
+}
+
+func init() {
+	machineAsmCmd.Flags().StringVarP(&asmFile, "file", "f", "", "Read the source from a file")
+	machineAsmCmd.Flags().BoolVar(&asmDryRun, "dry-run", false, "Only show the assembled listing")
+	machineAsmCmd.Flags().BoolVar(&asmPause, "pause", false, "Pause the machine while writing")
+
+	machineCmd.AddCommand(machineAsmCmd)
+}
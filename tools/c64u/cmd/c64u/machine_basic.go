@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/basic"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// MACHINE BASIC - Inspect the BASIC program in memory
+// ============================================================================
+
+// basicRAMEnd is the end of BASIC RAM, the furthest a program can reach
+const basicRAMEnd = 0xA000
+
+var basicListMixed bool
+
+var machineBasicCmd = &cobra.Command{
+	Use:   "basic",
+	Short: "Inspect the BASIC program in memory",
+	Long:  `Inspect the BASIC V2 program currently in the C64's memory.`,
+}
+
+var machineBasicListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the BASIC program in memory",
+	Long: `List the BASIC program in memory like LIST, without touching the
+running machine. The line links are followed from the start-of-BASIC
+pointer ($2B/$2C) and the keywords are expanded. Control codes in strings
+are shown as {CLR}-style escapes, as accepted by "basic build".
+
+Broken or looping links end the listing with a warning. Bytes between the
+end of the program and the variable pointer ($2D/$2E) are reported, which
+is where a SYS stub's machine code usually lives. Changed BASIC vectors
+and tokens unknown to V2 indicate a BASIC extension.
+
+Examples:
+  c64u machine basic list
+  c64u machine basic list --mixed > program.bas
+  c64u machine basic list --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cs := petscii.Upper
+		if basicListMixed {
+			cs = petscii.Lower
+		}
+
+		pointers, err := apiClient.MachineReadMemRange(basic.PointerStart, 4, 0)
+		if err != nil {
+			formatter.Error("Failed to read BASIC pointers", []string{err.Error()})
+			return
+		}
+		start := int(pointers[0]) | int(pointers[1])<<8
+		vars := int(pointers[2]) | int(pointers[3])<<8
+		var warnings []string
+		if start < 0x0200 || start >= basicRAMEnd {
+			warnings = append(warnings, fmt.Sprintf("start pointer $%04X is invalid, using $%04X", start, basic.DefaultStart))
+			start = basic.DefaultStart
+		}
+
+		// Read up to the variables first; stale pointers fall back to all of
+		// BASIC RAM
+		limit := vars
+		if limit <= start || limit > basicRAMEnd {
+			limit = basicRAMEnd
+		}
+		var prog *basic.Program
+		for {
+			mem, err := apiClient.MachineReadMemRange(start, limit-start, 0)
+			if err != nil {
+				formatter.Error("Failed to read BASIC program", []string{err.Error()})
+				return
+			}
+			prog = basic.Parse(mem, start, start, cs)
+			if prog.End >= 0 || limit == basicRAMEnd {
+				break
+			}
+			limit = basicRAMEnd
+		}
+		warnings = append(warnings, prog.Warnings...)
+
+		vectorMem, err := apiClient.MachineReadMemRange(basic.VectorsStart, basic.VectorsSize, 0)
+		if err != nil {
+			formatter.Error("Failed to read BASIC vectors", []string{err.Error()})
+			return
+		}
+		vectors := basic.ChangedVectors(vectorMem)
+
+		var sysTargets []int
+		for _, l := range prog.Lines {
+			if addr, ok := sysTarget(l.Text); ok {
+				sysTargets = append(sysTargets, addr)
+			}
+		}
+		trailing := 0
+		if prog.End >= 0 && vars > prog.End {
+			trailing = vars - prog.End
+		}
+
+		if jsonOut {
+			tokens := make([]string, len(prog.ExtensionTokens))
+			for i, t := range prog.ExtensionTokens {
+				tokens[i] = fmt.Sprintf("$%02X", t)
+			}
+			formatter.PrintData(map[string]interface{}{
+				"start":            start,
+				"end":              prog.End,
+				"vars":             vars,
+				"lines":            prog.Lines,
+				"sys":              sysTargets,
+				"trailing_bytes":   trailing,
+				"extension_tokens": tokens,
+				"changed_vectors":  vectors,
+				"warnings":         warnings,
+			})
+			return
+		}
+
+		if len(prog.Lines) == 0 {
+			formatter.Info("No BASIC program in memory")
+		}
+		for _, l := range prog.Lines {
+			fmt.Printf("%d %s\n", l.Number, l.Text)
+		}
+
+		var notes []string
+		for _, addr := range sysTargets {
+			note := fmt.Sprintf("SYS %d calls $%04X", addr, addr)
+			if label := describeAddress(addr); label != "" {
+				note += " (" + label + ")"
+			}
+			notes = append(notes, note)
+		}
+		if trailing > 0 {
+			notes = append(notes, fmt.Sprintf("$%04X-$%04X: %d bytes after the program (machine code or data)", prog.End, vars-1, trailing))
+		}
+		// Notes go to stderr like warnings, so a redirected listing can be
+		// fed to "basic build" unchanged
+		for _, n := range notes {
+			formatter.Note(n)
+		}
+		if len(vectors) > 0 || len(prog.ExtensionTokens) > 0 {
+			var details []string
+			details = append(details, vectors...)
+			if len(prog.ExtensionTokens) > 0 {
+				details = append(details, fmt.Sprintf("tokens unknown to BASIC V2: % X", prog.ExtensionTokens))
+			}
+			formatter.Warning("BASIC extension detected: " + strings.Join(details, "; "))
+		}
+		for _, w := range warnings {
+			formatter.Warning(w)
+		}
+	},
+}
+
+// sysTarget returns the address of a "SYS 4096" or "SYS (4096)" statement
+// at the start of a line
+func sysTarget(text string) (int, bool) {
+	rest, ok := strings.CutPrefix(text, "SYS")
+	if !ok {
+		return 0, false
+	}
+	rest = strings.TrimLeft(rest, " (")
+	end := 0
+	for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+		end++
+	}
+	addr, err := strconv.Atoi(rest[:end])
+	if err != nil || addr > 0xFFFF {
+		return 0, false
+	}
+	return addr, true
+}
+
+func init() {
+	machineBasicListCmd.Flags().BoolVar(&basicListMixed, "mixed", false, "Show text in the lower/upper case character set")
+
+	machineBasicCmd.AddCommand(machineBasicListCmd)
+	machineCmd.AddCommand(machineBasicCmd)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/memdump"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// MACHINE FILL / COPY / COMPARE - Block memory operations
+// ============================================================================
+
+var blockPause bool
+
+var machineFillCmd = &cobra.Command{
+	Use:   "fill <start> <end> <bytes> | <start>..<end> <bytes>",
+	Short: "Fill a memory range with a byte pattern",
+	Long: `Fill a memory range (end inclusive) with a byte or a repeating hex
+pattern.
+
+Examples:
+  c64u machine fill 0400 07e7 20
+  c64u machine fill d800 dbe7 01 --pause
+  c64u machine fill 2000..3fff "aa 55"`,
+	Args: cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		start, end, err := parseRangeArgs(args[:len(args)-1])
+		if err != nil {
+			formatter.Error("Invalid range", []string{err.Error()})
+			return
+		}
+		pattern, err := parseHexBytes(args[len(args)-1])
+		if err != nil || len(pattern) == 0 {
+			formatter.Error("Invalid fill pattern", []string{"Expected hex bytes, e.g. 20 or \"aa 55\""})
+			return
+		}
+
+		data := make([]byte, end-start+1)
+		for i := range data {
+			data[i] = pattern[i%len(pattern)]
+		}
+
+		if err := withPause(blockPause, func() error {
+			return apiClient.MachineWriteMemRange(start, data)
+		}); err != nil {
+			formatter.Error("Fill failed", []string{err.Error()})
+			return
+		}
+
+		formatter.Success(fmt.Sprintf("Filled $%04X-$%04X", start, end), map[string]interface{}{
+			"bytes":   len(data),
+			"pattern": fmt.Sprintf("% X", pattern),
+		})
+	},
+}
+
+var machineCopyCmd = &cobra.Command{
+	Use:   "copy <src-start> <src-end> <dest> | <src-start>..<src-end> <dest>",
+	Short: "Copy a memory range to another address",
+	Long: `Copy a memory range (end inclusive) to a destination address. The
+source is read completely before writing, so overlapping ranges are safe.
+
+Examples:
+  c64u machine copy c000 c0ff 4000
+  c64u machine copy 0400 07e7 3000 --pause
+  c64u machine copy DirectLoop..Done c000 --symbols hello.sym`,
+	Args: cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		start, end, err := parseRangeArgs(args[:len(args)-1])
+		if err != nil {
+			formatter.Error("Invalid range", []string{err.Error()})
+			return
+		}
+		dest, err := parseAddress(args[len(args)-1])
+		if err != nil {
+			formatter.Error("Invalid destination", []string{err.Error()})
+			return
+		}
+		length := end - start + 1
+		if dest+length > 0x10000 {
+			formatter.Error("Invalid destination", []string{fmt.Sprintf("%d bytes at $%04X run past $FFFF", length, dest)})
+			return
+		}
+
+		if err := withPause(blockPause, func() error {
+			data, err := apiClient.MachineReadMemRange(start, length, 0)
+			if err != nil {
+				return err
+			}
+			return apiClient.MachineWriteMemRange(dest, data)
+		}); err != nil {
+			formatter.Error("Copy failed", []string{err.Error()})
+			return
+		}
+
+		formatter.Success(fmt.Sprintf("Copied $%04X-$%04X to $%04X", start, end, dest), map[string]interface{}{
+			"bytes": length,
+		})
+	},
+}
+
+// compareDiff is one differing range reported by machine compare
+type compareDiff struct {
+	Source memdump.Range `json:"source"`
+	Dest   memdump.Range `json:"dest"`
+	Bytes  int           `json:"bytes"`
+	A      string        `json:"a"`
+	B      string        `json:"b"`
+}
+
+var machineCompareCmd = &cobra.Command{
+	Use:   "compare <start> <end> <dest> | <start>..<end> <dest>",
+	Short: "Compare a memory range with another address",
+	Long: `Compare a memory range (end inclusive) with the same number of bytes
+at a destination address and list the ranges that differ.
+
+Examples:
+  c64u machine compare c000 c0ff 4000
+  c64u machine compare 0400..07e7 3000 --pause
+  c64u machine compare 2000 27ff 6000 --json`,
+	Args: cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		start, end, err := parseRangeArgs(args[:len(args)-1])
+		if err != nil {
+			formatter.Error("Invalid range", []string{err.Error()})
+			return
+		}
+		dest, err := parseAddress(args[len(args)-1])
+		if err != nil {
+			formatter.Error("Invalid destination", []string{err.Error()})
+			return
+		}
+		length := end - start + 1
+		if dest+length > 0x10000 {
+			formatter.Error("Invalid destination", []string{fmt.Sprintf("%d bytes at $%04X run past $FFFF", length, dest)})
+			return
+		}
+
+		var a, b []byte
+		if err := withPause(blockPause, func() error {
+			var err error
+			if a, err = apiClient.MachineReadMemRange(start, length, 0); err != nil {
+				return err
+			}
+			b, err = apiClient.MachineReadMemRange(dest, length, 0)
+			return err
+		}); err != nil {
+			formatter.Error("Failed to read memory", []string{err.Error()})
+			return
+		}
+
+		diffs := []compareDiff{}
+		total := 0
+		for _, r := range memdump.Diff(a, b, start) {
+			offset := dest - start
+			diffs = append(diffs, compareDiff{
+				Source: r,
+				Dest:   memdump.Range{Start: r.Start + offset, End: r.End + offset},
+				Bytes:  r.Len(),
+				A:      fmt.Sprintf("%X", a[r.Start-start:r.End-start+1]),
+				B:      fmt.Sprintf("%X", b[r.Start-start:r.End-start+1]),
+			})
+			total += r.Len()
+		}
+
+		if jsonOut {
+			formatter.PrintData(map[string]interface{}{
+				"identical": len(diffs) == 0,
+				"bytes":     total,
+				"diffs":     diffs,
+			})
+			return
+		}
+
+		if len(diffs) == 0 {
+			formatter.Success(fmt.Sprintf("$%04X-$%04X and $%04X-$%04X are identical", start, end, dest, dest+length-1), nil)
+			return
+		}
+
+		var rows [][]string
+		for _, d := range diffs {
+			rows = append(rows, []string{
+				d.Source.String(),
+				d.Dest.String(),
+				fmt.Sprintf("%d", d.Bytes),
+				shortHex(a[d.Source.Start-start : d.Source.End-start+1]),
+				shortHex(b[d.Source.Start-start : d.Source.End-start+1]),
+			})
+		}
+		formatter.PrintTable([]string{"Source", "Dest", "Bytes", "Source Data", "Dest Data"}, rows)
+		fmt.Println()
+		formatter.PrintKeyValue("Differences", fmt.Sprintf("%d bytes in %d ranges", total, len(diffs)))
+	},
+}
+
+// withPause runs fn, with the machine paused if pause is set
+func withPause(pause bool, fn func() error) error {
+	if pause {
+		if err := pauseMachine(); err != nil {
+			return err
+		}
+		defer resumeMachine()
+	}
+	return fn()
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{machineFillCmd, machineCopyCmd, machineCompareCmd} {
+		cmd.Flags().BoolVar(&blockPause, "pause", false, "Pause the machine during the operation")
+		machineCmd.AddCommand(cmd)
+	}
+}
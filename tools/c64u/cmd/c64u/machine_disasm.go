@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/disasm"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// MACHINE DISASM - Disassemble memory
+// ============================================================================
+
+var disasmIllegal bool
+
+var machineDisasmCmd = &cobra.Command{
+	Use:   "disasm <address> [length] | <start>..<end>",
+	Short: "Disassemble memory",
+	Long: `Read memory and disassemble it as 6502/6510 code. The length is
+decimal, or hex with a $ or 0x prefix, and defaults to 64 bytes.
+
+Branch and jump targets are resolved and commented with project labels
+(see --symbols) or well-known KERNAL/BASIC entry points and I/O registers.
+Undocumented opcodes are shown as .byte unless --illegal is given.
+
+Examples:
+  c64u machine disasm 1000
+  c64u machine disasm 1000 32
+  c64u machine disasm DirectLoop..Done --symbols hello.sym
+  c64u machine disasm c000 0x100 --illegal
+  c64u machine disasm e544 --json`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+		if len(args) == 2 {
+			target += ":" + args[1]
+		} else if !strings.Contains(target, "..") {
+			target += ":64"
+		}
+		start, length, err := parseWatchTarget(target)
+		if err != nil {
+			formatter.Error("Invalid range", []string{err.Error()})
+			return
+		}
+
+		// Read a little more so the last instruction is complete
+		readLen := length + 2
+		if start+readLen > 0x10000 {
+			readLen = 0x10000 - start
+		}
+		data, err := apiClient.MachineReadMemRange(start, readLen, 0)
+		if err != nil {
+			formatter.Error("Failed to read memory", []string{err.Error()})
+			return
+		}
+
+		opts := disasm.Options{Illegal: disasmIllegal}
+		if t := currentSymbols(); t.Len() > 0 {
+			opts.Labels = t
+		}
+		lines := disasm.Disassemble(data, start, length, opts)
+
+		if jsonOut {
+			formatter.PrintData(lines)
+			return
+		}
+
+		for _, l := range lines {
+			for _, label := range l.Labels {
+				fmt.Println(formatter.GetSectionStyle().Render(label + ":"))
+			}
+			fmt.Println(l.Format())
+		}
+	},
+}
+
+func init() {
+	machineDisasmCmd.Flags().BoolVar(&disasmIllegal, "illegal", false, "Decode undocumented 6510 opcodes")
+
+	machineCmd.AddCommand(machineDisasmCmd)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// MACHINE FIND - Search memory for byte patterns and text
+// ============================================================================
+
+var (
+	findText    bool
+	findScreen  bool
+	findMixed   bool
+	findContext int
+	findMax     int
+)
+
+// findMatch is one hit of machine find
+type findMatch struct {
+	Address      int    `json:"address"`
+	Symbol       string `json:"symbol,omitempty"`
+	ContextStart int    `json:"context_start"`
+	Context      string `json:"context"`
+}
+
+var machineFindCmd = &cobra.Command{
+	Use:   "find <start> <end> <pattern> | <start>..<end> <pattern>",
+	Short: "Search memory for bytes, text or screen codes",
+	Long: `Search a memory range for a pattern and list every match with the
+surrounding bytes, like the monitor's hunt command.
+
+The pattern is hex by default, with ?? as a wildcard byte. With --text it
+is text converted to PETSCII, with --screen text converted to screen codes
+as stored in screen memory. Letters match the upper case character set;
+use --mixed for text written in the lower/upper case set.
+
+Examples:
+  c64u machine find 0800 9fff "a9 ?? 8d 20 d0"
+  c64u machine find 0000 ffff 20d2ff
+  c64u machine find 0800 ffff "HELLO" --text
+  c64u machine find 0400 07e7 "score" --screen
+  c64u machine find 0800..9fff "Game Over" --text --mixed --json`,
+	Args: cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		start, end, err := parseRangeArgs(args[:len(args)-1])
+		if err != nil {
+			formatter.Error("Invalid range", []string{err.Error()})
+			return
+		}
+		if findText && findScreen {
+			formatter.Error("Conflicting flags", []string{"Use either --text or --screen"})
+			return
+		}
+		if findContext < 0 {
+			formatter.Error("Invalid context", []string{"--context must not be negative"})
+			return
+		}
+
+		patternArg := args[len(args)-1]
+		var pattern []byte
+		var mask []bool
+		switch {
+		case findText:
+			pattern = petscii.Encode(patternArg, findCharset())
+		case findScreen:
+			pattern = petscii.EncodeScreen(patternArg, findCharset())
+		default:
+			pattern, mask, err = parseHexPattern(patternArg)
+			if err != nil {
+				formatter.Error("Invalid pattern", []string{err.Error()})
+				return
+			}
+		}
+		if len(pattern) == 0 {
+			formatter.Error("Empty pattern", nil)
+			return
+		}
+
+		data, err := apiClient.MachineReadMemRange(start, end-start+1, 0)
+		if err != nil {
+			formatter.Error("Failed to read memory", []string{err.Error()})
+			return
+		}
+
+		var matches []findMatch
+		for _, off := range findPattern(data, pattern, mask) {
+			from := off - findContext
+			if from < 0 {
+				from = 0
+			}
+			to := off + len(pattern) + findContext
+			if to > len(data) {
+				to = len(data)
+			}
+			matches = append(matches, findMatch{
+				Address:      start + off,
+				Symbol:       describeAddress(start + off),
+				ContextStart: start + from,
+				Context:      fmt.Sprintf("% X", data[from:to]),
+			})
+			if findMax > 0 && len(matches) >= findMax {
+				break
+			}
+		}
+
+		if jsonOut {
+			formatter.PrintData(map[string]interface{}{
+				"pattern": fmt.Sprintf("% X", pattern),
+				"matches": matches,
+			})
+			return
+		}
+
+		formatter.PrintHeader(fmt.Sprintf("🔍 %s in $%04X-$%04X", patternLabel(pattern, mask), start, end))
+		fmt.Println()
+		if len(matches) == 0 {
+			formatter.Info("No matches")
+			return
+		}
+
+		highlight := formatter.GetChangeStyle()
+		var rows [][]string
+		for _, m := range matches {
+			from := m.ContextStart - start
+			off := m.Address - start
+			to := off + len(pattern) + findContext
+			if to > len(data) {
+				to = len(data)
+			}
+
+			hit := fmt.Sprintf("% X", data[off:off+len(pattern)])
+			if formatter.NoColor {
+				hit = "[" + hit + "]"
+			} else {
+				hit = highlight.Render(hit)
+			}
+			context := strings.TrimSpace(fmt.Sprintf("% X", data[from:off]) + " " + hit + " " + fmt.Sprintf("% X", data[off+len(pattern):to]))
+
+			row := []string{fmt.Sprintf("$%04X", m.Address), context}
+			if currentSymbols().Len() > 0 {
+				row = append(row, m.Symbol)
+			}
+			rows = append(rows, row)
+		}
+		headers := []string{"Address", "Context"}
+		if currentSymbols().Len() > 0 {
+			headers = append(headers, "Symbol")
+		}
+		formatter.PrintTable(headers, rows)
+		fmt.Println()
+		formatter.PrintKeyValue("Matches", fmt.Sprintf("%d", len(matches)))
+	},
+}
+
+// parseHexPattern parses hex bytes with ?? wildcards; spaces are optional
+func parseHexPattern(s string) ([]byte, []bool, error) {
+	clean := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', ',', '$':
+			return -1
+		}
+		return r
+	}, s)
+	if len(clean)%2 != 0 {
+		return nil, nil, fmt.Errorf("odd number of hex digits in %q", s)
+	}
+
+	var pattern []byte
+	var mask []bool
+	for i := 0; i < len(clean); i += 2 {
+		pair := clean[i : i+2]
+		if pair == "??" {
+			pattern = append(pattern, 0)
+			mask = append(mask, false)
+			continue
+		}
+		b, err := parseHexBytes(pair)
+		if err != nil {
+			return nil, nil, err
+		}
+		pattern = append(pattern, b[0])
+		mask = append(mask, true)
+	}
+	return pattern, mask, nil
+}
+
+// findPattern returns the offsets of all matches. A nil mask compares
+// every byte; otherwise only bytes whose mask entry is set.
+func findPattern(data, pattern []byte, mask []bool) []int {
+	var offsets []int
+	for i := 0; i+len(pattern) <= len(data); i++ {
+		match := true
+		for j, b := range pattern {
+			if (mask == nil || mask[j]) && data[i+j] != b {
+				match = false
+				break
+			}
+		}
+		if match {
+			offsets = append(offsets, i)
+		}
+	}
+	return offsets
+}
+
+// patternLabel formats a pattern for the header, showing wildcards as ??
+func patternLabel(pattern []byte, mask []bool) string {
+	parts := make([]string, len(pattern))
+	for i, b := range pattern {
+		if mask != nil && !mask[i] {
+			parts[i] = "??"
+		} else {
+			parts[i] = fmt.Sprintf("%02X", b)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// findCharset returns the character set selected by --mixed
+func findCharset() petscii.Charset {
+	if findMixed {
+		return petscii.Lower
+	}
+	return petscii.Upper
+}
+
+func init() {
+	machineFindCmd.Flags().BoolVar(&findText, "text", false, "Pattern is text, searched as PETSCII")
+	machineFindCmd.Flags().BoolVar(&findScreen, "screen", false, "Pattern is text, searched as screen codes")
+	machineFindCmd.Flags().BoolVar(&findMixed, "mixed", false, "Use the lower/upper case character set for text")
+	machineFindCmd.Flags().IntVar(&findContext, "context", 8, "Bytes of context shown around each match")
+	machineFindCmd.Flags().IntVar(&findMax, "max", 256, "Stop after this many matches (0 = all)")
+
+	machineCmd.AddCommand(machineFindCmd)
+}
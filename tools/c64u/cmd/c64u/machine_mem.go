@@ -0,0 +1,391 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/api"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/memdump"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// MACHINE MEMORY TOOLS - Multi-request memory operations
+// ============================================================================
+
+var (
+	dumpOutput  string
+	dumpFormat  string
+	dumpLabel   string
+	dumpChunk   int
+	dumpPause   bool
+	dumpCharset string
+
+	writeVerify  bool
+	writeRaw     bool
+	writePause   bool
+	writeText    bool
+	writeCharset string
+)
+
+// ============================================================================
+// MACHINE DUMP - Read a memory range into a file
+// ============================================================================
+
+var machineDumpCmd = &cobra.Command{
+	Use:   "dump <start> <end> | <start>..<end>",
+	Short: "Dump a memory range to a file",
+	Long: `Read a memory range (end address inclusive, up to the full 64K) via DMA.
+The range is split into chunks the device accepts and reassembled.
+
+Output formats:
+  raw    plain binary (default for unknown extensions)
+  prg    binary with a two-byte load address header
+  ihex   Intel HEX records
+  c      C array with a #define for the start address
+  kick   KickAssembler source with .byte directives
+
+The format is taken from --format or from the extension of the output file
+(.bin, .prg, .hex, .c/.h, .asm/.s/.inc). Without --output a hex dump is
+printed, or the text formats are written to stdout. The text column of the
+hex dump shows ASCII by default; use --charset petscii or screen (or their
+-lower variants for the lower/upper case set) to read C64 text.
+
+Addresses are hex, with an optional $ or 0x prefix, or label expressions
+when symbols are loaded (see --symbols).
+
+Examples:
+  c64u machine dump 0000 ffff -o memory.bin
+  c64u machine dump DirectLoop..Done --symbols hello.sym
+  c64u machine dump c000 c7ff -o tables.prg
+  c64u machine dump 2000 23ff -o level1.asm --label level1
+  c64u machine dump 0400 07e7 --format c
+  c64u machine dump 0400 07e7 --charset screen
+  c64u machine dump 0801 9fff -o game.prg --pause`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		start, end, err := parseRangeArgs(args)
+		if err != nil {
+			formatter.Error("Invalid range", []string{err.Error()})
+			return
+		}
+
+		format := dumpFormat
+		if format == "" && dumpOutput != "" {
+			format = memdump.FormatFromPath(dumpOutput)
+		}
+
+		label := dumpLabel
+		if label == "" {
+			label = fmt.Sprintf("mem_%04x", start)
+			if dumpOutput != "" {
+				label = strings.TrimSuffix(filepath.Base(dumpOutput), filepath.Ext(dumpOutput))
+			}
+		}
+
+		if err := petscii.ValidEncoding(dumpCharset); err != nil {
+			formatter.Error("Invalid charset", []string{err.Error()})
+			return
+		}
+
+		data, err := readMemory(start, end-start+1, dumpChunk, dumpPause)
+		if err != nil {
+			formatter.Error("Failed to read memory", []string{err.Error()})
+			return
+		}
+
+		if format == "" {
+			fmt.Print(formatAnnotatedDump(data, start, dumpCharset))
+			return
+		}
+
+		out, err := memdump.Encode(format, data, start, label)
+		if err != nil {
+			formatter.Error("Invalid format", []string{err.Error()})
+			return
+		}
+
+		if dumpOutput == "" {
+			if !memdump.IsText(format) {
+				formatter.Error("Binary output needs a file", []string{"Use -o <file> with raw and prg formats"})
+				return
+			}
+			os.Stdout.Write(out)
+			return
+		}
+
+		if err := os.WriteFile(dumpOutput, out, 0644); err != nil {
+			formatter.Error("Failed to write file", []string{err.Error()})
+			return
+		}
+
+		formatter.Success(fmt.Sprintf("Dumped $%04X-$%04X", start, end), map[string]interface{}{
+			"file":   dumpOutput,
+			"format": format,
+			"bytes":  len(data),
+		})
+	},
+}
+
+// ============================================================================
+// MACHINE WRITE - Write data of any length to memory
+// ============================================================================
+
+var machineWriteCmd = &cobra.Command{
+	Use:   "write [address] <hex|@file|text>",
+	Short: "Write hex data or a file to memory (any length)",
+	Long: `Write data of any length to memory with a single binary upload.
+
+The data is either a hex string (spaces allowed) or @file for a binary
+file. PRG files (.prg) are written to their embedded load address; an
+explicit address overrides it. Use --raw to write a .prg file including
+its two-byte header. A .sym or .vs file next to the .prg is loaded
+automatically, so its labels can be used as the address.
+
+With --text the data is text, converted with --charset: petscii (default),
+screen for screen codes as stored in screen memory, the -lower variants
+for the lower/upper case set, or ascii.
+
+With --verify the memory is read back afterwards and any mismatching
+address ranges are reported.
+
+Examples:
+  c64u machine write d020 "00 00"
+  c64u machine write 2000 @sprites.bin --verify
+  c64u machine write @charset.prg --verify
+  c64u machine write 3000 @charset.prg --pause
+  c64u machine write str_to_print+2 "08 05" --symbols hello.sym
+  c64u machine write 0400 "HELLO WORLD" --text --charset screen
+  c64u machine write 0428 "Mixed Case" --text --charset screen-lower`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		addrArg, dataArg := "", args[0]
+		if len(args) == 2 {
+			addrArg, dataArg = args[0], args[1]
+		}
+
+		encoding := ""
+		if writeText {
+			if err := petscii.ValidEncoding(writeCharset); err != nil {
+				formatter.Error("Invalid charset", []string{err.Error()})
+				return
+			}
+			encoding = writeCharset
+		}
+
+		start, data, err := writePayload(addrArg, dataArg, writeRaw, encoding)
+		if err != nil {
+			formatter.Error("Invalid data", []string{err.Error()})
+			return
+		}
+		if len(data) == 0 {
+			formatter.Error("Nothing to write", nil)
+			return
+		}
+		end := start + len(data) - 1
+
+		// The read-back for --verify happens before the machine resumes
+		var readBack []byte
+		if err := withPause(writePause, func() error {
+			if err := apiClient.MachineWriteMemRange(start, data); err != nil {
+				return err
+			}
+			if writeVerify {
+				var err error
+				if readBack, err = apiClient.MachineReadMemRange(start, len(data), 0); err != nil {
+					return fmt.Errorf("verify: %w", err)
+				}
+			}
+			return nil
+		}); err != nil {
+			formatter.Error("Write failed", []string{err.Error()})
+			return
+		}
+
+		result := map[string]interface{}{
+			"bytes": len(data),
+		}
+
+		if writeVerify {
+			if mismatches := memdump.Diff(data, readBack, start); len(mismatches) > 0 {
+				var details []string
+				for _, r := range mismatches {
+					details = append(details, fmt.Sprintf("%s (%d bytes)", r, r.Len()))
+				}
+				formatter.Error(fmt.Sprintf("Verify failed: %d range(s) differ", len(mismatches)), details)
+				return
+			}
+			result["verified"] = true
+		}
+		if label := describeAddress(start); label != "" {
+			result["symbol"] = label
+		}
+
+		formatter.Success(fmt.Sprintf("Wrote $%04X-$%04X", start, end), result)
+	},
+}
+
+// writePayload resolves the start address and bytes for machine write.
+// A .prg file supplies its own load address unless raw is set. With an
+// encoding the data argument is text converted by that encoding.
+func writePayload(addrArg, dataArg string, raw bool, encoding string) (int, []byte, error) {
+	var data []byte
+	start := -1
+
+	if encoding != "" {
+		encoded, err := petscii.EncodeAs(dataArg, encoding)
+		if err != nil {
+			return 0, nil, err
+		}
+		data = encoded
+	} else if strings.HasPrefix(dataArg, "@") {
+		path := dataArg[1:]
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		data = content
+
+		if strings.EqualFold(filepath.Ext(path), ".prg") {
+			useProgramSymbols(path)
+			if !raw {
+				if len(content) < 2 {
+					return 0, nil, fmt.Errorf("%s is too short for a PRG file", path)
+				}
+				start = int(content[0]) | int(content[1])<<8
+				data = content[2:]
+			}
+		}
+	} else {
+		decoded, err := parseHexBytes(dataArg)
+		if err != nil {
+			return 0, nil, err
+		}
+		data = decoded
+	}
+
+	if addrArg != "" {
+		addr, err := parseAddress(addrArg)
+		if err != nil {
+			return 0, nil, err
+		}
+		start = addr
+	}
+	if start < 0 {
+		return 0, nil, fmt.Errorf("an address is required unless writing a .prg file")
+	}
+	if start+len(data) > 0x10000 {
+		return 0, nil, fmt.Errorf("%d bytes at $%04X run past $FFFF", len(data), start)
+	}
+
+	return start, data, nil
+}
+
+// parseHexBytes decodes a hex string, ignoring whitespace, commas and $ signs
+func parseHexBytes(s string) ([]byte, error) {
+	clean := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', ',', '$':
+			return -1
+		}
+		return r
+	}, s)
+	clean = strings.TrimPrefix(strings.ToLower(clean), "0x")
+
+	data, err := hex.DecodeString(clean)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex data: %w", err)
+	}
+	return data, nil
+}
+
+// readMemory reads a range in chunks, optionally with the machine paused
+// so the data is consistent
+func readMemory(start, length, chunk int, pause bool) ([]byte, error) {
+	var data []byte
+	err := withPause(pause, func() error {
+		var err error
+		data, err = apiClient.MachineReadMemRange(start, length, chunk)
+		return err
+	})
+	return data, err
+}
+
+// pauseMachine stops the CPU via DMA
+func pauseMachine() error {
+	resp, err := apiClient.MachinePause()
+	if err != nil {
+		return fmt.Errorf("failed to pause machine: %w", err)
+	}
+	if resp.HasErrors() {
+		return fmt.Errorf("failed to pause machine: %s", strings.Join(resp.Errors, "; "))
+	}
+	return nil
+}
+
+// resumeMachine restarts the CPU after pauseMachine
+func resumeMachine() {
+	resp, err := apiClient.MachineResume()
+	if err != nil {
+		formatter.Warning(fmt.Sprintf("Failed to resume machine: %v", err))
+		return
+	}
+	if resp.HasErrors() {
+		formatter.Warning("Failed to resume machine: " + strings.Join(resp.Errors, "; "))
+	}
+}
+
+// parseAddress evaluates an address: hex with optional $ or 0x prefix, or
+// a label expression such as "Done" or "str_to_print+2"
+func parseAddress(s string) (int, error) {
+	return currentSymbols().Eval(s)
+}
+
+// parseRange parses an inclusive start/end address pair
+func parseRange(startArg, endArg string) (int, int, error) {
+	start, err := parseAddress(startArg)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseAddress(endArg)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("end $%04X is below start $%04X", end, start)
+	}
+	return start, end, nil
+}
+
+// parseRangeArgs accepts either <start> <end> or a single "A..B" expression
+func parseRangeArgs(args []string) (int, int, error) {
+	if len(args) == 2 {
+		return parseRange(args[0], args[1])
+	}
+	if !strings.Contains(args[0], "..") {
+		return 0, 0, fmt.Errorf("expected <start> <end> or <start>..<end>")
+	}
+	return currentSymbols().EvalRange(args[0])
+}
+
+func init() {
+	machineDumpCmd.Flags().StringVarP(&dumpOutput, "output", "o", "", "Output file")
+	machineDumpCmd.Flags().StringVarP(&dumpFormat, "format", "f", "", "Output format: raw, prg, ihex, c, kick (default: from extension)")
+	machineDumpCmd.Flags().StringVar(&dumpLabel, "label", "", "Array or label name for c and kick output (default: file name)")
+	machineDumpCmd.Flags().IntVar(&dumpChunk, "chunk", api.ReadMemChunk, "Bytes per DMA read request")
+	machineDumpCmd.Flags().BoolVar(&dumpPause, "pause", false, "Pause the machine while reading")
+	machineDumpCmd.Flags().StringVar(&dumpCharset, "charset", petscii.EncodingASCII, "Text column of the hex dump: ascii, petscii, screen (or -lower)")
+
+	machineWriteCmd.Flags().BoolVar(&writeVerify, "verify", false, "Read the memory back and report mismatches")
+	machineWriteCmd.Flags().BoolVar(&writeRaw, "raw", false, "Write .prg files including the load address header")
+	machineWriteCmd.Flags().BoolVar(&writePause, "pause", false, "Pause the machine while writing")
+	machineWriteCmd.Flags().BoolVar(&writeText, "text", false, "Data is text, converted with --charset")
+	machineWriteCmd.Flags().StringVar(&writeCharset, "charset", petscii.EncodingPETSCII, "Text encoding for --text: petscii, screen, ascii (or -lower)")
+
+	machineCmd.AddCommand(machineDumpCmd)
+	machineCmd.AddCommand(machineWriteCmd)
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/vic"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// MACHINE SCREEN - Capture the text screen
+// ============================================================================
+
+var (
+	screenANSI    bool
+	screenCharset string
+	screenPalette string
+)
+
+// screenCapture is the text screen as read from the machine
+type screenCapture struct {
+	Layout     vic.Layout
+	Lower      bool
+	Border     byte
+	Background byte
+	Codes      []byte
+	Colors     []byte
+}
+
+var machineScreenCmd = &cobra.Command{
+	Use:   "screen",
+	Short: "Show the text screen as Unicode text",
+	Long: `Read the text screen and print it as 40x25 lines of Unicode text.
+
+The VIC bank and screen address are taken from $DD00 and $D018, so
+programs that move the screen are captured correctly. The character set
+(upper case or lower/upper case) is detected from $D018 as well and can be
+forced with --charset. Graphics characters use the Unicode Symbols for
+Legacy Computing block; reverse characters are shown as normal text unless
+--ansi is given.
+
+With --ansi the text is printed with 24-bit terminal colours from colour
+RAM and the background colour, using the --palette colours. With --json
+the lines, screen codes and colours are returned with the VIC setup.
+
+Examples:
+  c64u machine screen
+  c64u machine screen --ansi
+  c64u machine screen --charset lower > screen.txt
+  c64u machine screen --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		palette, err := vic.LookupPalette(screenPalette)
+		if err != nil {
+			formatter.Error("Invalid palette", []string{err.Error()})
+			return
+		}
+
+		capture, err := captureScreen()
+		if err != nil {
+			formatter.Error("Failed to read screen", []string{err.Error()})
+			return
+		}
+		switch screenCharset {
+		case "auto":
+		case "upper":
+			capture.Lower = false
+		case "lower":
+			capture.Lower = true
+		default:
+			formatter.Error("Invalid charset", []string{"Use auto, upper or lower"})
+			return
+		}
+		lines := capture.Lines()
+
+		if jsonOut {
+			codes := make([]string, vic.Rows)
+			colors := make([]string, vic.Rows)
+			for row := range codes {
+				cells := capture.Codes[row*vic.Columns : (row+1)*vic.Columns]
+				codes[row] = fmt.Sprintf("%X", cells)
+				var sb strings.Builder
+				for _, c := range capture.Colors[row*vic.Columns : (row+1)*vic.Columns] {
+					fmt.Fprintf(&sb, "%X", c&0x0F)
+				}
+				colors[row] = sb.String()
+			}
+			charset := "upper"
+			if capture.Lower {
+				charset = "lower"
+			}
+			formatter.PrintData(map[string]interface{}{
+				"bank":       capture.Layout.Bank,
+				"screen":     capture.Layout.Screen,
+				"chars":      capture.Layout.Chars,
+				"charset":    charset,
+				"border":     capture.Border & 0x0F,
+				"background": capture.Background & 0x0F,
+				"lines":      lines,
+				"codes":      codes,
+				"colors":     colors,
+			})
+			return
+		}
+
+		if screenANSI {
+			fmt.Print(capture.ANSI(palette))
+			return
+		}
+		for _, line := range lines {
+			fmt.Println(strings.TrimRight(line, " \u00A0"))
+		}
+	},
+}
+
+// captureScreen reads the VIC setup, screen memory and colour RAM
+func captureScreen() (*screenCapture, error) {
+	bank, err := apiClient.MachineReadMemRange(vic.RegBank, 1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read $DD00: %w", err)
+	}
+	mem, err := apiClient.MachineReadMemRange(vic.RegMemory, 1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read $D018: %w", err)
+	}
+	colors, err := apiClient.MachineReadMemRange(vic.RegBorder, 2, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read $D020: %w", err)
+	}
+
+	layout := vic.Decode(bank[0], mem[0])
+	codes, err := apiClient.MachineReadMemRange(layout.Screen, vic.ScreenSize, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read screen memory: %w", err)
+	}
+	colorRAM, err := apiClient.MachineReadMemRange(vic.ColorRAM, vic.ScreenSize, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read colour RAM: %w", err)
+	}
+
+	return &screenCapture{
+		Layout:     layout,
+		Lower:      layout.LowerCase(),
+		Border:     colors[0],
+		Background: colors[1],
+		Codes:      codes,
+		Colors:     colorRAM,
+	}, nil
+}
+
+// charset returns the character set used to decode the screen
+func (s *screenCapture) charset() petscii.Charset {
+	if s.Lower {
+		return petscii.Lower
+	}
+	return petscii.Upper
+}
+
+// Lines returns the 25 screen lines as text
+func (s *screenCapture) Lines() []string {
+	lines := make([]string, vic.Rows)
+	for row := range lines {
+		lines[row] = petscii.DecodeScreen(s.Codes[row*vic.Columns:(row+1)*vic.Columns], s.charset())
+	}
+	return lines
+}
+
+// ANSI renders the screen with 24-bit colour escape sequences. Reverse
+// characters swap the character and background colours.
+func (s *screenCapture) ANSI(palette vic.Palette) string {
+	bg := palette[s.Background&0x0F]
+	var sb strings.Builder
+	for row := 0; row < vic.Rows; row++ {
+		last := ""
+		for col := 0; col < vic.Columns; col++ {
+			n := row*vic.Columns + col
+			fg, back := palette[s.Colors[n]&0x0F], bg
+			if s.Codes[n]&0x80 != 0 {
+				fg, back = back, fg
+			}
+			if esc := ansiColor(38, fg) + ansiColor(48, back); esc != last {
+				sb.WriteString(esc)
+				last = esc
+			}
+			sb.WriteRune(petscii.ScreenRune(s.Codes[n], s.charset()))
+		}
+		sb.WriteString("\x1b[0m\n")
+	}
+	return sb.String()
+}
+
+// ansiColor returns a 24-bit colour escape; code is 38 for the foreground
+// and 48 for the background
+func ansiColor(code int, c color.RGBA) string {
+	return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", code, c.R, c.G, c.B)
+}
+
+func init() {
+	machineScreenCmd.Flags().BoolVar(&screenANSI, "ansi", false, "Print with terminal colours from colour RAM")
+	machineScreenCmd.Flags().StringVar(&screenCharset, "charset", "auto", "Character set: auto, upper, lower")
+	machineScreenCmd.Flags().StringVar(&screenPalette, "palette", vic.DefaultPalette, "Colour palette for --ansi: "+strings.Join(vic.PaletteNames(), ", "))
+
+	machineCmd.AddCommand(machineScreenCmd)
+}
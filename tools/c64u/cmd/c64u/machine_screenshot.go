@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/png"
+	"os"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/vic"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ============================================================================
+// MACHINE SCREENSHOT - Render the screen from memory to PNG
+// ============================================================================
+
+var (
+	shotOutput  string
+	shotBorder  bool
+	shotScale   int
+	shotPalette string
+	shotChargen string
+	shotPause   bool
+)
+
+var machineScreenshotCmd = &cobra.Command{
+	Use:   "screenshot -o <file.png>",
+	Short: "Render the screen from memory to a PNG file",
+	Long: `Read the VIC-II registers, screen and colour RAM, character set or
+bitmap and sprite data via DMA and render the picture to a PNG file, without
+a capture card.
+
+Supported are text, multicolour text, extended colour text, hires bitmap
+and multicolour bitmap mode, smooth scrolling, 38 column/24 row mode and
+all sprites with expansion, multicolour and background priority. The
+image is 320x200, or 384x272 with --border. Raster effects (splits,
+open borders) are not visible, since memory is read once.
+
+DMA cannot read the character ROM, so pictures that use it (the normal
+text screen) need a 4K ROM dump given with --chargen or the "chargen"
+config key (C64U_CHARGEN), e.g. the chargen file of VICE. Character sets
+and bitmaps in RAM need nothing extra. In bank 3 memory hidden under I/O
+or ROM is only captured while the program has banked it out.
+
+Examples:
+  c64u machine screenshot -o shot.png --chargen ~/roms/chargen
+  c64u machine screenshot -o shot.png --border --scale 2
+  c64u machine screenshot -o game.png --palette pepto --pause`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if shotOutput == "" {
+			formatter.Error("No output file", []string{"Use -o <file.png>"})
+			return
+		}
+		if shotScale < 1 || shotScale > 8 {
+			formatter.Error("Invalid scale", []string{"--scale must be between 1 and 8"})
+			return
+		}
+		palette, err := vic.LookupPalette(shotPalette)
+		if err != nil {
+			formatter.Error("Invalid palette", []string{err.Error()})
+			return
+		}
+
+		mem := &vic.Memory{}
+		chargen := shotChargen
+		if chargen == "" {
+			chargen = viper.GetString("chargen")
+		}
+		if chargen != "" {
+			if mem.CharROM, err = vic.LoadCharROM(chargen); err != nil {
+				formatter.Error("Invalid character ROM", []string{err.Error()})
+				return
+			}
+		}
+
+		if err := withPause(shotPause, func() error {
+			return readVICMemory(mem)
+		}); err != nil {
+			formatter.Error("Failed to read memory", []string{err.Error()})
+			return
+		}
+
+		img, err := vic.Render(mem, palette, shotBorder)
+		if errors.Is(err, vic.ErrCharROM) {
+			formatter.Error("Character ROM required", []string{
+				err.Error(),
+				"Use --chargen <file> with a 4K character ROM dump (e.g. VICE's chargen)",
+			})
+			return
+		}
+		if err != nil {
+			formatter.Error("Failed to render", []string{err.Error()})
+			return
+		}
+		img = vic.Scale(img, shotScale)
+
+		f, err := os.Create(shotOutput)
+		if err != nil {
+			formatter.Error("Failed to create file", []string{err.Error()})
+			return
+		}
+		if err := png.Encode(f, img); err != nil {
+			f.Close()
+			formatter.Error("Failed to write PNG", []string{err.Error()})
+			return
+		}
+		if err := f.Close(); err != nil {
+			formatter.Error("Failed to write PNG", []string{err.Error()})
+			return
+		}
+
+		result := map[string]interface{}{
+			"file":   shotOutput,
+			"mode":   mem.Mode().String(),
+			"size":   fmt.Sprintf("%dx%d", img.Bounds().Dx(), img.Bounds().Dy()),
+			"screen": fmt.Sprintf("$%04X", mem.Layout.Screen),
+		}
+		switch mem.Mode() {
+		case vic.ModeBitmap, vic.ModeMulticolorBitmap:
+			result["bitmap"] = fmt.Sprintf("$%04X", mem.Bitmap())
+		default:
+			result["chars"] = fmt.Sprintf("$%04X", mem.Layout.Chars)
+		}
+		if n := spriteCount(mem.Regs[0x15]); n > 0 {
+			result["sprites"] = n
+		}
+		formatter.Success("Screenshot saved", result)
+	},
+}
+
+// readVICMemory reads the registers, the VIC bank and colour RAM. The
+// collision registers $D01E/$D01F are skipped, since reading clears them.
+func readVICMemory(mem *vic.Memory) error {
+	regs, err := apiClient.MachineReadMemRange(0xD000, 0x1E, 0)
+	if err != nil {
+		return fmt.Errorf("failed to read VIC-II registers: %w", err)
+	}
+	copy(mem.Regs[:], regs)
+	regs, err = apiClient.MachineReadMemRange(0xD020, 0x0F, 0)
+	if err != nil {
+		return fmt.Errorf("failed to read VIC-II registers: %w", err)
+	}
+	copy(mem.Regs[0x20:], regs)
+
+	bank, err := apiClient.MachineReadMemRange(vic.RegBank, 1, 0)
+	if err != nil {
+		return fmt.Errorf("failed to read $DD00: %w", err)
+	}
+	mem.Layout = vic.Decode(bank[0], mem.Regs[0x18])
+
+	if mem.Bank, err = apiClient.MachineReadMemRange(mem.Layout.Bank, 0x4000, 0); err != nil {
+		return fmt.Errorf("failed to read VIC bank: %w", err)
+	}
+	if mem.ColorRAM, err = apiClient.MachineReadMemRange(vic.ColorRAM, vic.ScreenSize, 0); err != nil {
+		return fmt.Errorf("failed to read colour RAM: %w", err)
+	}
+	return nil
+}
+
+// spriteCount counts the enabled sprites in $D015
+func spriteCount(enabled byte) int {
+	n := 0
+	for ; enabled != 0; enabled >>= 1 {
+		n += int(enabled & 1)
+	}
+	return n
+}
+
+func init() {
+	machineScreenshotCmd.Flags().StringVarP(&shotOutput, "output", "o", "", "Output PNG file")
+	machineScreenshotCmd.Flags().BoolVar(&shotBorder, "border", false, "Include the border (384x272)")
+	machineScreenshotCmd.Flags().IntVar(&shotScale, "scale", 1, "Enlarge the image by this factor")
+	machineScreenshotCmd.Flags().StringVar(&shotPalette, "palette", vic.DefaultPalette, "Colour palette: "+strings.Join(vic.PaletteNames(), ", "))
+	machineScreenshotCmd.Flags().StringVar(&shotChargen, "chargen", "", "Character ROM dump (4K) for screens using the ROM font")
+	machineScreenshotCmd.Flags().BoolVar(&shotPause, "pause", false, "Pause the machine while reading")
+
+	machineCmd.AddCommand(machineScreenshotCmd)
+}
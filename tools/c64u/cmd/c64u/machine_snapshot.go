@@ -0,0 +1,347 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/memdump"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// MACHINE SNAPSHOT - Save, compare and restore the full address space
+// ============================================================================
+
+var (
+	snapshotIO        bool
+	snapshotComment   string
+	snapshotOverwrite bool
+	snapshotSkipIO    bool
+)
+
+var machineSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save, compare and restore memory snapshots",
+	Long: `Capture the full 64K address space into a local snapshot store
+(~/.config/c64u/snapshots), compare snapshots and write them back.
+
+The I/O area $D000-$DFFF is skipped unless --io is given, because reading
+some registers (e.g. the CIA interrupt flags) has side effects.
+
+Examples:
+  c64u machine snapshot save before
+  c64u machine snapshot save after --io
+  c64u machine snapshot diff before after
+  c64u machine snapshot restore before
+  c64u machine snapshot list`,
+}
+
+var machineSnapshotSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Capture memory into a snapshot",
+	Long: `Read all 64K of memory while the machine is paused and store it as
+a snapshot. Without --io the I/O area $D000-$DFFF is not read.
+
+Examples:
+  c64u machine snapshot save title
+  c64u machine snapshot save level2 --io --comment "after loading level 2"
+  c64u machine snapshot save title --overwrite`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := snapshot.ValidName(name); err != nil {
+			formatter.Error("Invalid name", []string{err.Error()})
+			return
+		}
+		if snapshot.Exists(name) && !snapshotOverwrite {
+			formatter.Error("Snapshot already exists", []string{"Use --overwrite to replace " + name})
+			return
+		}
+
+		mem, err := captureMemory(snapshotIO)
+		if err != nil {
+			formatter.Error("Failed to read memory", []string{err.Error()})
+			return
+		}
+
+		s := &snapshot.Snapshot{
+			Name:    name,
+			Created: time.Now(),
+			Host:    host,
+			IO:      snapshotIO,
+			Comment: snapshotComment,
+			Memory:  mem,
+		}
+		if err := snapshot.Save(s); err != nil {
+			formatter.Error("Failed to save snapshot", []string{err.Error()})
+			return
+		}
+
+		formatter.Success(fmt.Sprintf("Saved snapshot %s", name), map[string]interface{}{
+			"io": snapshotIO,
+		})
+	},
+}
+
+var machineSnapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored snapshots",
+	Long: `List the snapshots in the local snapshot store.
+
+Examples:
+  c64u machine snapshot list
+  c64u machine snapshot list --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		list, err := snapshot.List()
+		if err != nil {
+			formatter.Error("Failed to list snapshots", []string{err.Error()})
+			return
+		}
+
+		if jsonOut {
+			formatter.PrintData(list)
+			return
+		}
+		if len(list) == 0 {
+			formatter.Info("No snapshots stored")
+			return
+		}
+
+		var rows [][]string
+		for _, s := range list {
+			io := "no"
+			if s.IO {
+				io = "yes"
+			}
+			rows = append(rows, []string{s.Name, s.Created.Format("2006-01-02 15:04:05"), s.Host, io, s.Comment})
+		}
+		formatter.PrintTable([]string{"Name", "Created", "Host", "I/O", "Comment"}, rows)
+	},
+}
+
+var machineSnapshotDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a stored snapshot",
+	Long: `Remove a snapshot from the local snapshot store.
+
+Examples:
+  c64u machine snapshot delete before`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := snapshot.Delete(args[0]); err != nil {
+			formatter.Error("Failed to delete snapshot", []string{err.Error()})
+			return
+		}
+		formatter.Success(fmt.Sprintf("Deleted snapshot %s", args[0]), nil)
+	},
+}
+
+// snapshotChange is one changed range in a snapshot diff
+type snapshotChange struct {
+	memdump.Range
+	Bytes  int      `json:"bytes"`
+	Areas  []string `json:"areas,omitempty"`
+	Symbol string   `json:"symbol,omitempty"`
+	A      string   `json:"a"`
+	B      string   `json:"b"`
+}
+
+var machineSnapshotDiffCmd = &cobra.Command{
+	Use:   "diff <a> <b>",
+	Short: "List memory ranges that differ between two snapshots",
+	Long: `Compare two snapshots and list every changed address range with the
+old and new bytes, the memory map area it lies in and, with --symbols, the
+nearest label. The I/O area is only compared when both snapshots captured
+it.
+
+Examples:
+  c64u machine snapshot diff before after
+  c64u machine snapshot diff before after --symbols game.sym
+  c64u machine snapshot diff before after --json`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		a, err := snapshot.Load(args[0])
+		if err != nil {
+			formatter.Error("Failed to load snapshot", []string{err.Error()})
+			return
+		}
+		b, err := snapshot.Load(args[1])
+		if err != nil {
+			formatter.Error("Failed to load snapshot", []string{err.Error()})
+			return
+		}
+
+		var changes []snapshotChange
+		total := 0
+		for _, r := range memdump.Diff(a.Memory, b.Memory, 0) {
+			for _, part := range splitIO(r, a.IO && b.IO) {
+				changes = append(changes, snapshotChange{
+					Range:  part,
+					Bytes:  part.Len(),
+					Areas:  memdump.RangeAreas(part),
+					Symbol: describeAddress(part.Start),
+					A:      fmt.Sprintf("%X", a.Memory[part.Start:part.End+1]),
+					B:      fmt.Sprintf("%X", b.Memory[part.Start:part.End+1]),
+				})
+				total += part.Len()
+			}
+		}
+
+		if jsonOut {
+			formatter.PrintData(map[string]interface{}{
+				"a":       a.Name,
+				"b":       b.Name,
+				"io":      a.IO && b.IO,
+				"bytes":   total,
+				"changes": changes,
+			})
+			return
+		}
+
+		formatter.PrintHeader(fmt.Sprintf("🧠 %s → %s", a.Name, b.Name))
+		fmt.Println()
+		if len(changes) == 0 {
+			formatter.Success("Snapshots are identical", nil)
+			return
+		}
+		if !(a.IO && b.IO) {
+			formatter.Info("I/O area $D000-$DFFF not compared")
+		}
+
+		var rows [][]string
+		for _, c := range changes {
+			area := strings.Join(c.Areas, ", ")
+			if c.Symbol != "" {
+				area = c.Symbol + " (" + area + ")"
+			}
+			rows = append(rows, []string{
+				c.Range.String(),
+				fmt.Sprintf("%d", c.Bytes),
+				area,
+				shortHex(a.Memory[c.Start : c.End+1]),
+				shortHex(b.Memory[c.Start : c.End+1]),
+			})
+		}
+		formatter.PrintTable([]string{"Range", "Bytes", "Location", a.Name, b.Name}, rows)
+		fmt.Println()
+		formatter.PrintKeyValue("Changed", fmt.Sprintf("%d bytes in %d ranges", total, len(changes)))
+	},
+}
+
+var machineSnapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <name>",
+	Short: "Write a snapshot back to memory",
+	Long: `Write a snapshot back via DMA while the machine is paused. The CPU
+port at $0000-$0001 is written last so the banking stays stable during the
+restore. The I/O area is only written when the snapshot captured it and
+--skip-io is not given.
+
+Examples:
+  c64u machine snapshot restore before
+  c64u machine snapshot restore level2 --skip-io`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := snapshot.Load(args[0])
+		if err != nil {
+			formatter.Error("Failed to load snapshot", []string{err.Error()})
+			return
+		}
+
+		withIO := s.IO && !snapshotSkipIO
+		ranges := []memdump.Range{{Start: 0x0002, End: memdump.IOStart - 1}}
+		if withIO {
+			ranges = append(ranges, memdump.Range{Start: memdump.IOStart, End: memdump.IOEnd})
+		}
+		ranges = append(ranges,
+			memdump.Range{Start: memdump.IOEnd + 1, End: 0xFFFF},
+			memdump.Range{Start: 0x0000, End: 0x0001},
+		)
+
+		written := 0
+		if err := withPause(true, func() error {
+			for _, r := range ranges {
+				if err := apiClient.MachineWriteMemRange(r.Start, s.Memory[r.Start:r.End+1]); err != nil {
+					return fmt.Errorf("%s: %w", r, err)
+				}
+				written += r.Len()
+			}
+			return nil
+		}); err != nil {
+			formatter.Error("Restore failed", []string{err.Error()})
+			return
+		}
+
+		formatter.Success(fmt.Sprintf("Restored snapshot %s", s.Name), map[string]interface{}{
+			"bytes": written,
+			"io":    withIO,
+		})
+	},
+}
+
+// captureMemory reads the full address space with the machine paused.
+// Without io the I/O area is left zeroed.
+func captureMemory(io bool) ([]byte, error) {
+	mem := make([]byte, snapshot.MemorySize)
+	ranges := []memdump.Range{{Start: 0x0000, End: 0xFFFF}}
+	if !io {
+		ranges = []memdump.Range{
+			{Start: 0x0000, End: memdump.IOStart - 1},
+			{Start: memdump.IOEnd + 1, End: 0xFFFF},
+		}
+	}
+
+	if err := withPause(true, func() error {
+		for _, r := range ranges {
+			data, err := apiClient.MachineReadMemRange(r.Start, r.Len(), 0)
+			if err != nil {
+				return err
+			}
+			copy(mem[r.Start:], data)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return mem, nil
+}
+
+// splitIO removes the I/O area from a range unless io is set
+func splitIO(r memdump.Range, io bool) []memdump.Range {
+	if io || r.End < memdump.IOStart || r.Start > memdump.IOEnd {
+		return []memdump.Range{r}
+	}
+	var parts []memdump.Range
+	if r.Start < memdump.IOStart {
+		parts = append(parts, memdump.Range{Start: r.Start, End: memdump.IOStart - 1})
+	}
+	if r.End > memdump.IOEnd {
+		parts = append(parts, memdump.Range{Start: memdump.IOEnd + 1, End: r.End})
+	}
+	return parts
+}
+
+// shortHex formats up to eight bytes as hex, marking longer data with an ellipsis
+func shortHex(data []byte) string {
+	const max = 8
+	if len(data) <= max {
+		return fmt.Sprintf("% X", data)
+	}
+	return fmt.Sprintf("% X …", data[:max])
+}
+
+func init() {
+	machineSnapshotSaveCmd.Flags().BoolVar(&snapshotIO, "io", false, "Also capture the I/O area $D000-$DFFF")
+	machineSnapshotSaveCmd.Flags().StringVar(&snapshotComment, "comment", "", "Note stored with the snapshot")
+	machineSnapshotSaveCmd.Flags().BoolVar(&snapshotOverwrite, "overwrite", false, "Replace an existing snapshot")
+	machineSnapshotRestoreCmd.Flags().BoolVar(&snapshotSkipIO, "skip-io", false, "Do not write the I/O area even if captured")
+
+	machineSnapshotCmd.AddCommand(machineSnapshotSaveCmd)
+	machineSnapshotCmd.AddCommand(machineSnapshotListCmd)
+	machineSnapshotCmd.AddCommand(machineSnapshotDiffCmd)
+	machineSnapshotCmd.AddCommand(machineSnapshotRestoreCmd)
+	machineSnapshotCmd.AddCommand(machineSnapshotDeleteCmd)
+	machineCmd.AddCommand(machineSnapshotCmd)
+}
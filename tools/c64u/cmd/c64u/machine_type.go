@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// MACHINE TYPE - Type text through the KERNAL keyboard buffer
+// ============================================================================
+
+// KERNAL keyboard buffer
+const (
+	keyBuffer     = 0x0277
+	keyBufferSize = 10
+	keyCount      = 0x00C6
+)
+
+var (
+	typeMixed   bool
+	typeTimeout time.Duration
+)
+
+// typeReplacer turns the backslash escapes of typed text into newlines,
+// which are typed as RETURN
+var typeReplacer = strings.NewReplacer(`\\`, `\`, `\r`, "\n", `\n`, "\n", "\r\n", "\n", "\r", "\n")
+
+var machineTypeCmd = &cobra.Command{
+	Use:   "type <text>",
+	Short: "Type text on the C64 keyboard",
+	Long: `Type text by feeding it into the KERNAL keyboard buffer ($0277, with
+the number of keys at $C6), as if it was typed on the keyboard. Text longer
+than the buffer is sent in chunks of up to 10 keys, each after the previous
+one has been read.
+
+\r, \n or a line break press RETURN. Special keys are written as escapes
+in braces, optionally with a repeat count:
+
+  {RETURN} {CLR} {HOME} {DEL} {INST} {STOP}
+  {UP} {DOWN} {LEFT} {RIGHT} {DOWN*5}
+  {F1} ... {F8}, {RVS ON} {RVS OFF}, colours like {WHT} {RED} {LBLU}
+  {$xx} for any PETSCII code
+
+Letters are typed unshifted, which gives upper case in the default
+character set; with --mixed upper case letters are typed shifted for the
+lower/upper case set. The running program must read the keyboard through
+the KERNAL (BASIC, the KERNAL screen editor and most prompts do).
+
+Examples:
+  c64u machine type 'LOAD"*",8,1\r'
+  c64u machine type 'RUN\r'
+  c64u machine type '{CLR}10 PRINT "HELLO"\rRUN\r'
+  c64u machine type 'y{RETURN}' --timeout 30s`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cs := petscii.Upper
+		if typeMixed {
+			cs = petscii.Lower
+		}
+		keys, err := petscii.EncodeEscaped(typeReplacer.Replace(args[0]), cs)
+		if err != nil {
+			formatter.Error("Invalid text", []string{err.Error()})
+			return
+		}
+		if len(keys) == 0 {
+			formatter.Error("Nothing to type", nil)
+			return
+		}
+
+		chunks := 0
+		for i := 0; i < len(keys); i += keyBufferSize {
+			end := i + keyBufferSize
+			if end > len(keys) {
+				end = len(keys)
+			}
+			if err := waitKeyBuffer(typeTimeout); err != nil {
+				formatter.Error(fmt.Sprintf("Typed %d of %d keys", i, len(keys)), []string{err.Error()})
+				return
+			}
+			if err := apiClient.MachineWriteMemRange(keyBuffer, keys[i:end]); err != nil {
+				formatter.Error("Failed to write keyboard buffer", []string{err.Error()})
+				return
+			}
+			if err := apiClient.MachineWriteMemRange(keyCount, []byte{byte(end - i)}); err != nil {
+				formatter.Error("Failed to write key count", []string{err.Error()})
+				return
+			}
+			chunks++
+		}
+
+		formatter.Success(fmt.Sprintf("Typed %d keys", len(keys)), map[string]interface{}{
+			"chunks": chunks,
+		})
+	},
+}
+
+// waitKeyBuffer waits until the keyboard buffer is empty
+func waitKeyBuffer(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		count, err := apiClient.MachineReadMemRange(keyCount, 1, 0)
+		if err != nil {
+			return fmt.Errorf("failed to read key count: %w", err)
+		}
+		if count[0] == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("keyboard buffer still holds %d keys after %s; is the program reading the keyboard?", count[0], timeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func init() {
+	machineTypeCmd.Flags().BoolVar(&typeMixed, "mixed", false, "Type for the lower/upper case character set")
+	machineTypeCmd.Flags().DurationVar(&typeTimeout, "timeout", 5*time.Second, "Maximum wait for the keyboard buffer to drain")
+
+	machineCmd.AddCommand(machineTypeCmd)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/memdump"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// MACHINE WATCH - Poll a memory range and show changes live
+// ============================================================================
+
+var (
+	watchInterval time.Duration
+	watchCount    int
+	watchCharset  string
+)
+
+// watchEvent is one NDJSON line emitted by machine watch --json
+type watchEvent struct {
+	Time  time.Time `json:"time"`
+	Poll  int       `json:"poll"`
+	Event string    `json:"event"` // "initial" or "change"
+	Start int       `json:"start"`
+	End   int       `json:"end"`
+	Old   string    `json:"old,omitempty"`
+	New   string    `json:"new"`
+}
+
+var machineWatchCmd = &cobra.Command{
+	Use:   "watch <address>[:length] | <start>..<end>",
+	Short: "Watch a memory range and highlight changes",
+	Long: `Poll a memory range and redraw a hex view in which the bytes that
+changed since the previous poll are highlighted. The length is decimal, or
+hex with a $ or 0x prefix, and defaults to 16 bytes. With symbols loaded
+the address may be a label expression or a "Start..End" range. The text
+column follows --charset as in machine dump. Stop with Ctrl-C.
+
+With --json one JSON object per line (NDJSON) is written: an "initial"
+event with the full range, then a "change" event for every changed range.
+
+Examples:
+  c64u machine watch d012:1 --interval 50ms
+  c64u machine watch 0400:40 --charset screen
+  c64u machine watch c000:0x100 --interval 1s
+  c64u machine watch 00a0:3 --json --count 100
+  c64u machine watch score:2 --symbols game.sym`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		start, length, err := parseWatchTarget(args[0])
+		if err != nil {
+			formatter.Error("Invalid range", []string{err.Error()})
+			return
+		}
+		if err := petscii.ValidEncoding(watchCharset); err != nil {
+			formatter.Error("Invalid charset", []string{err.Error()})
+			return
+		}
+		if watchInterval <= 0 {
+			formatter.Error("Invalid interval", []string{"--interval must be positive"})
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		enc := json.NewEncoder(os.Stdout)
+		var prev []byte
+		for poll := 1; watchCount == 0 || poll <= watchCount; poll++ {
+			data, err := apiClient.MachineReadMemRange(start, length, 0)
+			if err != nil {
+				formatter.Error("Failed to read memory", []string{err.Error()})
+				return
+			}
+			now := time.Now()
+
+			if jsonOut {
+				if prev == nil {
+					enc.Encode(watchEvent{Time: now, Poll: poll, Event: "initial", Start: start, End: start + length - 1, New: fmt.Sprintf("%X", data)})
+				} else {
+					for _, r := range memdump.Diff(prev, data, start) {
+						enc.Encode(watchEvent{
+							Time: now, Poll: poll, Event: "change", Start: r.Start, End: r.End,
+							Old: fmt.Sprintf("%X", prev[r.Start-start:r.End-start+1]),
+							New: fmt.Sprintf("%X", data[r.Start-start:r.End-start+1]),
+						})
+					}
+				}
+			} else {
+				fmt.Print("\033[H\033[2J")
+				title := fmt.Sprintf("👀 $%04X-$%04X every %s", start, start+length-1, watchInterval)
+				if label := describeAddress(start); label != "" {
+					title = fmt.Sprintf("👀 %s ($%04X-$%04X) every %s", label, start, start+length-1, watchInterval)
+				}
+				formatter.PrintHeader(title)
+				fmt.Println(formatter.GetDimStyle().Render(fmt.Sprintf("poll %d at %s, Ctrl-C to stop", poll, now.Format("15:04:05.000"))))
+				fmt.Println()
+				fmt.Print(renderWatchDump(data, prev, start))
+			}
+			prev = data
+			if poll == watchCount {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(watchInterval):
+			}
+		}
+	},
+}
+
+// renderWatchDump formats a hex dump like FormatMemoryDump, highlighting
+// bytes that differ from prev. Without colours changed bytes get a '*'.
+func renderWatchDump(data, prev []byte, start int) string {
+	changed := formatter.GetChangeStyle()
+
+	var sb strings.Builder
+	for i := 0; i < len(data); i += 16 {
+		fmt.Fprintf(&sb, "%04X: ", start+i)
+		for j := 0; j < 16; j++ {
+			n := i + j
+			switch {
+			case n >= len(data):
+				sb.WriteString("   ")
+			case prev != nil && prev[n] != data[n]:
+				if formatter.NoColor {
+					fmt.Fprintf(&sb, "%02X*", data[n])
+				} else {
+					sb.WriteString(changed.Render(fmt.Sprintf("%02X", data[n])) + " ")
+				}
+			default:
+				fmt.Fprintf(&sb, "%02X ", data[n])
+			}
+			if j == 7 {
+				sb.WriteString(" ")
+			}
+		}
+
+		sb.WriteString(" |")
+		for j := 0; j < 16 && i+j < len(data); j++ {
+			c := string(petscii.DumpRune(data[i+j], watchCharset))
+			if prev != nil && prev[i+j] != data[i+j] && !formatter.NoColor {
+				c = changed.Render(c)
+			}
+			sb.WriteString(c)
+		}
+		sb.WriteString("|")
+		if t := currentSymbols(); t.Len() > 0 {
+			if labels := rowLabels(t, start+i, len(data)-i); labels != "" {
+				sb.WriteString("  " + formatter.GetDimStyle().Render(labels))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// parseWatchTarget parses "<address>[:length]" or "<start>..<end>". The
+// length is decimal or hex with a $ or 0x prefix.
+func parseWatchTarget(arg string) (int, int, error) {
+	if strings.Contains(arg, "..") {
+		start, end, err := currentSymbols().EvalRange(arg)
+		if err != nil {
+			return 0, 0, err
+		}
+		return start, end - start + 1, nil
+	}
+
+	addrArg, lenArg, hasLen := strings.Cut(arg, ":")
+	start, err := parseAddress(addrArg)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	length := 16
+	if hasLen {
+		base, digits := 10, lenArg
+		switch {
+		case strings.HasPrefix(lenArg, "$"):
+			base, digits = 16, lenArg[1:]
+		case strings.HasPrefix(strings.ToLower(lenArg), "0x"):
+			base, digits = 16, lenArg[2:]
+		}
+		v, err := strconv.ParseUint(digits, base, 32)
+		if err != nil || v == 0 {
+			return 0, 0, fmt.Errorf("invalid length %q", lenArg)
+		}
+		length = int(v)
+	}
+	if start+length > 0x10000 {
+		return 0, 0, fmt.Errorf("%d bytes at $%04X run past $FFFF", length, start)
+	}
+	return start, length, nil
+}
+
+func init() {
+	machineWatchCmd.Flags().DurationVar(&watchInterval, "interval", 250*time.Millisecond, "Time between polls (e.g. 100ms, 1s)")
+	machineWatchCmd.Flags().IntVar(&watchCount, "count", 0, "Stop after this many polls (0 = until Ctrl-C)")
+	machineWatchCmd.Flags().StringVar(&watchCharset, "charset", petscii.EncodingASCII, "Text column: ascii, petscii, screen (or -lower)")
+
+	machineCmd.AddCommand(machineWatchCmd)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/prg"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// PRG COMMANDS
+// ============================================================================
+
+var prgCmd = &cobra.Command{
+	Use:   "prg",
+	Short: "Inspect program files",
+	Long:  `Inspect local PRG files before running them on the C64 Ultimate.`,
+}
+
+// ============================================================================
+// PRG INFO - Show load address, memory regions and BASIC stub
+// ============================================================================
+
+var prgInfoCmd = &cobra.Command{
+	Use:   "info <file.prg>",
+	Short: "Show load address, memory regions and BASIC stub",
+	Long: `Analyze a PRG file: load address, end address, size, the memory
+regions it overlaps and any BASIC SYS stub with the effective start address.
+
+Likely placement mistakes are reported, e.g. a SYS stub pointing at an
+address that holds no code, or data loaded into the I/O area.
+
+Examples:
+  c64u prg info hello.prg
+  c64u --json prg info hello.prg`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+
+		p, err := prg.ReadFile(path)
+		if err != nil {
+			formatter.Error("Failed to read PRG file", []string{err.Error()})
+			return
+		}
+
+		info := p.Analyze()
+
+		if jsonOut {
+			formatter.PrintData(info)
+			return
+		}
+
+		formatter.PrintHeader(fmt.Sprintf("📄 %s", filepath.Base(path)))
+		fmt.Println()
+		formatter.PrintKeyValue("Load Address", fmt.Sprintf("$%04X (%d)", info.LoadAddress, info.LoadAddress))
+		formatter.PrintKeyValue("End Address", fmt.Sprintf("$%04X", info.EndAddress))
+		formatter.PrintKeyValue("Size", fmt.Sprintf("%d bytes", info.Size))
+		if info.Stub != nil {
+			formatter.PrintKeyValue("BASIC Stub", info.Stub.Text)
+		}
+		formatter.PrintKeyValue("Start Address", fmt.Sprintf("$%04X (%d)", info.StartAddress, info.StartAddress))
+		fmt.Println()
+
+		var rows [][]string
+		for _, r := range info.Regions {
+			rows = append(rows, []string{
+				fmt.Sprintf("$%04X-$%04X", r.Start, r.End),
+				r.Name,
+			})
+		}
+		formatter.PrintTable([]string{"Range", "Region"}, rows)
+
+		if len(info.Warnings) > 0 {
+			fmt.Println()
+			for _, w := range info.Warnings {
+				fmt.Printf("  ⚠ %s\n", w)
+			}
+		}
+	},
+}
+
+// warnPRG prints placement warnings for PRG data about to be run
+func warnPRG(data []byte) {
+	p, err := prg.Parse(data)
+	if err != nil {
+		formatter.Warning(err.Error())
+		return
+	}
+
+	for _, w := range p.Analyze().Warnings {
+		formatter.Warning(w)
+	}
+}
+
+func init() {
+	prgCmd.AddCommand(prgInfoCmd)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/api"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/tape"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// RUN - Upload and run a local file, detected by extension
+// ============================================================================
+
+var (
+	runEntry int
+	runForce bool
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <file>",
+	Short: "Upload and run a local file",
+	Long: `Upload a local file to the C64 Ultimate and run it.
+
+The runner is chosen by file extension:
+  .prg   check placement, then run via DMA load (runners:run_prg)
+  .t64   extract an entry as PRG and run it
+  .crt   validate, then start as cartridge (runners:run_crt)
+  .sid   play the default song with the SID player (runners:sidplay)
+  .mod   play with the MOD player (runners:modplay)
+
+Examples:
+  c64u run hello.prg
+  c64u run game.t64 --entry 0
+  c64u run cart.crt
+  c64u run odd.crt --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+
+		if _, err := os.Stat(path); err != nil {
+			formatter.Error("Local file not found", []string{err.Error()})
+			return
+		}
+
+		var resp *api.Response
+		var err error
+
+		switch ext := strings.ToLower(filepath.Ext(path)); ext {
+		case ".prg":
+			data, readErr := os.ReadFile(path)
+			if readErr != nil {
+				formatter.Error("Failed to read PRG file", []string{readErr.Error()})
+				return
+			}
+			warnPRG(data)
+			resp, err = apiClient.RunPRGUpload(path)
+		case ".t64":
+			resp, err = runT64(path, runEntry)
+		case ".crt":
+			if !checkCartridge(path, runForce) {
+				return
+			}
+			resp, err = apiClient.RunCRTUpload(path)
+		case ".sid":
+			resp, err = playSID(path, "")
+		case ".mod":
+			resp, err = apiClient.ModPlayUpload(path)
+		default:
+			formatter.Error("Unsupported file type", []string{
+				fmt.Sprintf("Don't know how to run %q files", ext),
+				"Supported: .prg, .t64, .crt, .sid, .mod",
+			})
+			return
+		}
+
+		if err != nil {
+			formatter.Error("Failed to run file", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Running %s", filepath.Base(path)))
+	},
+}
+
+// runT64 extracts a T64 entry to a temporary PRG and runs it
+func runT64(path string, index int) (*api.Response, error) {
+	t, err := tape.ReadT64File(path)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, prg, err := extractT64Entry(t, index)
+	if err != nil {
+		return nil, err
+	}
+
+	formatter.Info(fmt.Sprintf("Running entry %d %q (load address $%04X)", entry.Index, entry.Name, entry.Start))
+	warnPRG(prg)
+
+	tmpPath, err := writeTempFile("c64u-t64-*.prg", prg)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmpPath)
+
+	return apiClient.RunPRGUpload(tmpPath)
+}
+
+// writeTempFile writes data to a new temporary file and returns its path
+func writeTempFile(pattern string, data []byte) (string, error) {
+	tmpFile, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	return tmpFile.Name(), nil
+}
+
+func init() {
+	runCmd.Flags().IntVar(&runEntry, "entry", -1, "T64 directory entry index (default: first entry)")
+	runCmd.Flags().BoolVar(&runForce, "force", false, "Upload even if file validation fails")
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// RUNNERS COMMANDS
+// ============================================================================
+
+var (
+	runnersSong  string
+	runnersForce bool
+)
+
+var runnersCmd = &cobra.Command{
+	Use:   "runners",
+	Short: "Media playback and program execution",
+	Long: `Play SID and MOD files, load and run programs, and start cartridges.
+
+Every runner exists in two forms: the plain command uses a file on the
+C64 Ultimate filesystem, the -upload variant sends a local file.`,
+}
+
+// ============================================================================
+// RUNNERS SIDPLAY - Play SID files
+// ============================================================================
+
+var runnersSidplayCmd = &cobra.Command{
+	Use:   "sidplay <file>",
+	Short: "Play a SID file from the C64U filesystem",
+	Long: `Play a SID file stored on the C64 Ultimate with the built-in SID player.
+
+With --song the file is fetched via FTP so the song can be checked against
+the song count in its header. Values with a sign are relative to the
+tune's default song.
+
+Examples:
+  c64u runners sidplay /USB0/music/commando.sid
+  c64u runners sidplay /USB0/music/commando.sid --song 3`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := playRemoteSID(args[0], runnersSong)
+		if err != nil {
+			formatter.Error("Failed to play SID", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Playing %s", args[0]))
+	},
+}
+
+var runnersSidplayUploadCmd = &cobra.Command{
+	Use:   "sidplay-upload <file>",
+	Short: "Upload and play a SID file",
+	Long: `Upload a local SID file and play it with the built-in SID player.
+
+The --song value is checked against the song count in the SID header.
+Values with a sign are relative to the tune's default song.
+
+Examples:
+  c64u runners sidplay-upload commando.sid
+  c64u runners sidplay-upload commando.sid --song 3
+  c64u runners sidplay-upload commando.sid --song +1`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := playSID(args[0], runnersSong)
+		if err != nil {
+			formatter.Error("Failed to play SID", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Playing %s", filepath.Base(args[0])))
+	},
+}
+
+// ============================================================================
+// RUNNERS MODPLAY - Play MOD files
+// ============================================================================
+
+var runnersModplayCmd = &cobra.Command{
+	Use:   "modplay <file>",
+	Short: "Play a MOD file from the C64U filesystem",
+	Long: `Play an Amiga MOD file stored on the C64 Ultimate.
+
+Examples:
+  c64u runners modplay /USB0/music/tune.mod`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.ModPlay(args[0])
+		if err != nil {
+			formatter.Error("Failed to play MOD", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Playing %s", args[0]))
+	},
+}
+
+var runnersModplayUploadCmd = &cobra.Command{
+	Use:   "modplay-upload <file>",
+	Short: "Upload and play a MOD file",
+	Long: `Upload a local Amiga MOD file and play it.
+
+Examples:
+  c64u runners modplay-upload tune.mod`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.ModPlayUpload(args[0])
+		if err != nil {
+			formatter.Error("Failed to play MOD", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Playing %s", filepath.Base(args[0])))
+	},
+}
+
+// ============================================================================
+// RUNNERS LOAD-PRG - Load programs without running them
+// ============================================================================
+
+var runnersLoadPrgCmd = &cobra.Command{
+	Use:   "load-prg <file>",
+	Short: "Load a PRG from the C64U filesystem",
+	Long: `Reset the machine and load a program stored on the C64 Ultimate into
+memory via DMA, without running it.
+
+Examples:
+  c64u runners load-prg /USB0/games/game.prg`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.LoadPRG(args[0])
+		if err != nil {
+			formatter.Error("Failed to load program", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Loaded %s", args[0]))
+	},
+}
+
+var runnersLoadPrgUploadCmd = &cobra.Command{
+	Use:   "load-prg-upload <file>",
+	Short: "Upload and load a PRG",
+	Long: `Reset the machine and load a local program into memory via DMA,
+without running it.
+
+Examples:
+  c64u runners load-prg-upload game.prg`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.LoadPRGUpload(args[0])
+		if err != nil {
+			formatter.Error("Failed to load program", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Loaded %s", filepath.Base(args[0])))
+	},
+}
+
+// ============================================================================
+// RUNNERS RUN-PRG - Load and run programs
+// ============================================================================
+
+var runnersRunPrgCmd = &cobra.Command{
+	Use:   "run-prg <file>",
+	Short: "Load and run a PRG from the C64U filesystem",
+	Long: `Reset the machine, load a program stored on the C64 Ultimate and run it.
+
+Examples:
+  c64u runners run-prg /USB0/games/game.prg`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.RunPRG(args[0])
+		if err != nil {
+			formatter.Error("Failed to run program", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Running %s", args[0]))
+	},
+}
+
+var runnersRunPrgUploadCmd = &cobra.Command{
+	Use:   "run-prg-upload <file>",
+	Short: "Upload and run a PRG",
+	Long: `Reset the machine, upload a local program and run it. Likely placement
+mistakes, such as a SYS stub pointing at an address that holds no code,
+are reported first.
+
+Examples:
+  c64u runners run-prg-upload game.prg`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			formatter.Error("Failed to read PRG file", []string{err.Error()})
+			return
+		}
+		warnPRG(data)
+
+		resp, err := apiClient.RunPRGUpload(args[0])
+		if err != nil {
+			formatter.Error("Failed to run program", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Running %s", filepath.Base(args[0])))
+	},
+}
+
+// ============================================================================
+// RUNNERS RUN-CRT - Start cartridges
+// ============================================================================
+
+var runnersRunCrtCmd = &cobra.Command{
+	Use:   "run-crt <file>",
+	Short: "Start a cartridge from the C64U filesystem",
+	Long: `Start a CRT cartridge image stored on the C64 Ultimate. The machine is
+reset with the cartridge attached.
+
+Examples:
+  c64u runners run-crt /USB0/carts/actionreplay.crt`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := apiClient.RunCRT(args[0])
+		if err != nil {
+			formatter.Error("Failed to start cartridge", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Started %s", args[0]))
+	},
+}
+
+var runnersRunCrtUploadCmd = &cobra.Command{
+	Use:   "run-crt-upload <file>",
+	Short: "Upload and start a cartridge",
+	Long: `Upload a local CRT cartridge image and start it. The machine is reset
+with the cartridge attached.
+
+The image is validated first; an inconsistent cartridge is refused unless
+--force is given.
+
+Examples:
+  c64u runners run-crt-upload actionreplay.crt
+  c64u runners run-crt-upload odd.crt --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !checkCartridge(args[0], runnersForce) {
+			return
+		}
+
+		resp, err := apiClient.RunCRTUpload(args[0])
+		if err != nil {
+			formatter.Error("Failed to start cartridge", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Started %s", filepath.Base(args[0])))
+	},
+}
+
+func init() {
+	runnersSidplayCmd.Flags().StringVar(&runnersSong, "song", "", "Song number, or +N/-N relative to the default song")
+	runnersSidplayUploadCmd.Flags().StringVar(&runnersSong, "song", "", "Song number, or +N/-N relative to the default song")
+	runnersRunCrtUploadCmd.Flags().BoolVar(&runnersForce, "force", false, "Upload even if cartridge validation fails")
+
+	runnersCmd.AddCommand(runnersSidplayCmd)
+	runnersCmd.AddCommand(runnersSidplayUploadCmd)
+	runnersCmd.AddCommand(runnersModplayCmd)
+	runnersCmd.AddCommand(runnersModplayUploadCmd)
+	runnersCmd.AddCommand(runnersLoadPrgCmd)
+	runnersCmd.AddCommand(runnersLoadPrgUploadCmd)
+	runnersCmd.AddCommand(runnersRunPrgCmd)
+	runnersCmd.AddCommand(runnersRunPrgUploadCmd)
+	runnersCmd.AddCommand(runnersRunCrtCmd)
+	runnersCmd.AddCommand(runnersRunCrtUploadCmd)
+}
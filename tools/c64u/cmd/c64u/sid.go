@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/api"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/sid"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// SID COMMANDS
+// ============================================================================
+
+var sidSong string
+
+var sidCmd = &cobra.Command{
+	Use:   "sid",
+	Short: "Inspect and play SID files",
+	Long:  `Show PSID/RSID metadata of local SID files and play them with validated song numbers.`,
+}
+
+// ============================================================================
+// SID INFO - Show SID header
+// ============================================================================
+
+var sidInfoCmd = &cobra.Command{
+	Use:   "info <file.sid>",
+	Short: "Show SID file metadata",
+	Long: `Parse a PSID/RSID file and display its header: version, load/init/play
+addresses, song count, start song, speed flags, SID model and clock,
+additional SID chip addresses, and the title/author/released strings.
+
+Examples:
+  c64u sid info Commando.sid
+  c64u --json sid info Commando.sid`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+
+		h, err := sid.ReadFile(path)
+		if err != nil {
+			formatter.Error("Failed to parse SID file", []string{err.Error()})
+			return
+		}
+
+		if jsonOut {
+			speeds := make([]string, h.Songs)
+			for i := range speeds {
+				speeds[i] = h.SongSpeed(i + 1)
+			}
+			formatter.PrintData(map[string]interface{}{
+				"header":      h,
+				"end_address": h.EndAddress(),
+				"clock":       h.Clock(),
+				"sid_model":   h.SIDModel(0),
+				"song_speeds": speeds,
+			})
+			return
+		}
+
+		formatter.PrintHeader(fmt.Sprintf("🎵 %s", filepath.Base(path)))
+		fmt.Println()
+		formatter.PrintKeyValue("Title", h.Name)
+		formatter.PrintKeyValue("Author", h.Author)
+		formatter.PrintKeyValue("Released", h.Released)
+		fmt.Println()
+		formatter.PrintKeyValue("Format", fmt.Sprintf("%s v%d", h.Type, h.Version))
+		formatter.PrintKeyValue("Load Address", fmt.Sprintf("$%04X-$%04X", h.LoadAddress, h.EndAddress()))
+		formatter.PrintKeyValue("Init Address", fmt.Sprintf("$%04X", h.InitAddress))
+		if h.PlayAddress == 0 {
+			formatter.PrintKeyValue("Play Address", "$0000 (installs own IRQ)")
+		} else {
+			formatter.PrintKeyValue("Play Address", fmt.Sprintf("$%04X", h.PlayAddress))
+		}
+		formatter.PrintKeyValue("Songs", fmt.Sprintf("%d (default %d)", h.Songs, h.StartSong))
+		formatter.PrintKeyValue("Speed", formatSongSpeeds(h))
+
+		if h.Version >= 2 {
+			formatter.PrintKeyValue("Clock", h.Clock())
+			formatter.PrintKeyValue("SID Model", h.SIDModel(0))
+			if h.SecondSID != 0 {
+				formatter.PrintKeyValue("2nd SID", fmt.Sprintf("$%04X (%s)", h.SecondSID, h.SIDModel(1)))
+			}
+			if h.ThirdSID != 0 {
+				formatter.PrintKeyValue("3rd SID", fmt.Sprintf("$%04X (%s)", h.ThirdSID, h.SIDModel(2)))
+			}
+			if h.IsMUS() {
+				formatter.PrintKeyValue("Data", "Compute!'s Sidplayer MUS")
+			}
+			if h.IsBASIC() {
+				formatter.PrintKeyValue("Data", "C64 BASIC program")
+			}
+		}
+	},
+}
+
+// formatSongSpeeds summarizes the per-song speed flags
+func formatSongSpeeds(h *sid.Header) string {
+	first := h.SongSpeed(1)
+	for song := 2; song <= h.Songs; song++ {
+		if h.SongSpeed(song) != first {
+			s := ""
+			for n := 1; n <= h.Songs && n <= 32; n++ {
+				if n > 1 {
+					s += " "
+				}
+				s += fmt.Sprintf("%d:%s", n, h.SongSpeed(n))
+			}
+			return s
+		}
+	}
+	return first
+}
+
+// ============================================================================
+// SID PLAY - Upload and play with a validated song number
+// ============================================================================
+
+var sidPlayCmd = &cobra.Command{
+	Use:   "play <file.sid>",
+	Short: "Upload and play a SID file",
+	Long: `Upload a local SID file and play it on the C64 Ultimate.
+
+The --song value is checked against the song count in the SID header.
+Values with a sign are relative to the tune's default song.
+
+Examples:
+  c64u sid play tune.sid              # Default song
+  c64u sid play tune.sid --song 3     # Song 3
+  c64u sid play tune.sid --song +1    # Song after the default`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := playSID(args[0], sidSong)
+		if err != nil {
+			formatter.Error("Failed to play SID file", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Playing %s", filepath.Base(args[0])))
+	},
+}
+
+// playSID validates the song selection against the SID header and uploads the tune
+func playSID(path, songSpec string) (*api.Response, error) {
+	h, err := sid.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	song, err := h.ResolveSong(songSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	formatter.Info(fmt.Sprintf("%q by %s (%s) - song %d of %d",
+		h.Name, h.Author, h.Released, song, h.Songs))
+
+	return apiClient.SidPlayUpload(path, song)
+}
+
+// playRemoteSID plays a SID file from the C64U filesystem. A song selection
+// is validated against the header of a downloaded copy first.
+func playRemoteSID(remote, songSpec string) (*api.Response, error) {
+	if songSpec == "" {
+		return apiClient.SidPlay(remote, 0)
+	}
+
+	tmp, err := os.CreateTemp("", "c64u-*.sid")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := apiClient.FTPDownload(remote, tmp.Name()); err != nil {
+		return nil, err
+	}
+
+	h, err := sid.ReadFile(tmp.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	song, err := h.ResolveSong(songSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	formatter.Info(fmt.Sprintf("%q by %s (%s) - song %d of %d",
+		h.Name, h.Author, h.Released, song, h.Songs))
+
+	return apiClient.SidPlay(remote, song)
+}
+
+func init() {
+	sidPlayCmd.Flags().StringVar(&sidSong, "song", "", "Song number, or +N/-N relative to the default song")
+
+	sidCmd.AddCommand(sidInfoCmd)
+	sidCmd.AddCommand(sidPlayCmd)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// STREAMS COMMANDS (U64 only)
+// ============================================================================
+
+// validStreams are the stream names the Ultimate 64 accepts
+var validStreams = []string{"video", "audio", "debug"}
+
+var streamsCmd = &cobra.Command{
+	Use:   "streams",
+	Short: "Control data streams (U64 only)",
+	Long: `Start and stop the Ultimate 64's video, audio and debug streams.
+
+Streams are sent over UDP to the given IP address on their default ports:
+  video  11000
+  audio  11001
+  debug  11002`,
+}
+
+// ============================================================================
+// STREAMS START - Start a stream
+// ============================================================================
+
+var streamsStartCmd = &cobra.Command{
+	Use:   "start <stream> <ip>",
+	Short: "Start a stream",
+	Long: `Start sending a video, audio or debug stream to an IP address.
+
+Examples:
+  c64u streams start video 192.168.1.100
+  c64u streams start audio 192.168.1.100`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !isValidStream(args[0]) {
+			formatter.Error("Invalid stream", []string{fmt.Sprintf("%q is not one of video, audio, debug", args[0])})
+			return
+		}
+
+		resp, err := apiClient.StreamsStart(args[0], args[1])
+		if err != nil {
+			formatter.Error("Failed to start stream", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Started %s stream to %s", args[0], args[1]))
+	},
+}
+
+// ============================================================================
+// STREAMS STOP - Stop a stream
+// ============================================================================
+
+var streamsStopCmd = &cobra.Command{
+	Use:   "stop <stream>",
+	Short: "Stop a stream",
+	Long: `Stop a running video, audio or debug stream.
+
+Examples:
+  c64u streams stop video`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !isValidStream(args[0]) {
+			formatter.Error("Invalid stream", []string{fmt.Sprintf("%q is not one of video, audio, debug", args[0])})
+			return
+		}
+
+		resp, err := apiClient.StreamsStop(args[0])
+		if err != nil {
+			formatter.Error("Failed to stop stream", []string{err.Error()})
+			return
+		}
+
+		formatter.PrintResponse(resp, fmt.Sprintf("Stopped %s stream", args[0]))
+	},
+}
+
+// isValidStream reports whether name is a known stream
+func isValidStream(name string) bool {
+	for _, s := range validStreams {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	streamsCmd.AddCommand(streamsStartCmd)
+	streamsCmd.AddCommand(streamsStopCmd)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/api"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/symbols"
+	"github.com/spf13/viper"
+)
+
+// ============================================================================
+// SYMBOLS - Labels for address arguments and output annotation
+// ============================================================================
+
+var (
+	symbolsFile   string
+	symbolTable   *symbols.Table
+	symbolsLoaded bool
+)
+
+// currentSymbols returns the symbol table given with --symbols, the
+// "symbols" config key or C64U_SYMBOLS. It is nil if none is configured.
+func currentSymbols() *symbols.Table {
+	if symbolsLoaded {
+		return symbolTable
+	}
+	symbolsLoaded = true
+
+	path := symbolsFile
+	if path == "" {
+		path = viper.GetString("symbols")
+	}
+	if path == "" {
+		return nil
+	}
+
+	t, err := symbols.Load(path)
+	if err != nil {
+		formatter.Error("Failed to load symbols", []string{err.Error()})
+		return nil
+	}
+	symbolTable = t
+	return symbolTable
+}
+
+// useProgramSymbols loads the .sym or .vs file next to a program unless
+// symbols were configured explicitly
+func useProgramSymbols(prgPath string) {
+	if currentSymbols() != nil {
+		return
+	}
+	if path := symbols.Discover(prgPath); path != "" {
+		t, err := symbols.Load(path)
+		if err != nil {
+			formatter.Warning(fmt.Sprintf("Ignoring symbols: %v", err))
+			return
+		}
+		symbolTable = t
+	}
+}
+
+// describeAddress names an address by its nearest label, or returns ""
+func describeAddress(addr int) string {
+	return currentSymbols().Describe(addr)
+}
+
+// formatAnnotatedDump is api.FormatMemoryDump with the text column in the
+// given charset and the labels defined in each row appended to it
+func formatAnnotatedDump(data []byte, start int, charset string) string {
+	dump := api.FormatMemoryDumpWith(data, start, func(b byte) rune {
+		return petscii.DumpRune(b, charset)
+	})
+	t := currentSymbols()
+	if t.Len() == 0 {
+		return dump
+	}
+
+	lines := strings.SplitAfter(dump, "\n")
+	for i := range lines {
+		if labels := rowLabels(t, start+i*16, len(data)-i*16); labels != "" {
+			lines[i] = strings.TrimSuffix(lines[i], "\n") + "  " + labels + "\n"
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// rowLabels lists the labels defined within a 16-byte dump row
+func rowLabels(t *symbols.Table, addr, remaining int) string {
+	var labels []string
+	for j := 0; j < 16 && j < remaining; j++ {
+		for _, name := range t.At(addr + j) {
+			if j == 0 {
+				labels = append(labels, name)
+			} else {
+				labels = append(labels, fmt.Sprintf("%s@+%d", name, j))
+			}
+		}
+	}
+	return strings.Join(labels, ", ")
+}
+
+func init() {
+	machineCmd.PersistentFlags().StringVar(&symbolsFile, "symbols", "", "KickAssembler .sym or VICE .vs label file (or the .prg next to it)")
+}
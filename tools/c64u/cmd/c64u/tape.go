@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/tape"
+	"github.com/spf13/cobra"
+)
+
+// ============================================================================
+// TAPE IMAGE COMMANDS (T64 / TAP)
+// ============================================================================
+
+var tapeEntry int
+
+var tapeCmd = &cobra.Command{
+	Use:   "tape",
+	Short: "Inspect and extract tape images",
+	Long: `Work with local tape images.
+
+T64 containers can be listed and their files extracted as PRG files
+with the correct load address. TAP pulse files are decoded to show the
+standard CBM ROM loader headers (file names and load addresses).`,
+}
+
+// ============================================================================
+// TAPE LS - List tape contents
+// ============================================================================
+
+var tapeLsCmd = &cobra.Command{
+	Use:   "ls <file.t64|file.tap>",
+	Short: "List tape image contents",
+	Long: `List the entries of a T64 container or the headers found in a TAP file.
+
+Examples:
+  c64u tape ls game.t64
+  c64u tape ls game.tap`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := args[0]
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			formatter.Error("Failed to read tape image", []string{err.Error()})
+			return
+		}
+
+		switch {
+		case tape.IsT64(data):
+			t, err := tape.ParseT64(data)
+			if err != nil {
+				formatter.Error("Failed to parse T64 file", []string{err.Error()})
+				return
+			}
+			printT64(path, t)
+		case tape.IsTAP(data):
+			t, err := tape.ParseTAP(data)
+			if err != nil {
+				formatter.Error("Failed to parse TAP file", []string{err.Error()})
+				return
+			}
+			printTAP(path, t)
+		default:
+			formatter.Error("Unknown tape image format", []string{
+				"Expected a T64 container or a TAP pulse file",
+			})
+		}
+	},
+}
+
+// printT64 prints the directory of a T64 container
+func printT64(path string, t *tape.T64) {
+	if jsonOut {
+		formatter.PrintData(t)
+		return
+	}
+
+	formatter.PrintHeader(fmt.Sprintf("📼 %s", filepath.Base(path)))
+	fmt.Println()
+	formatter.PrintKeyValue("Tape Name", t.Name)
+	formatter.PrintKeyValue("Version", fmt.Sprintf("$%04X", t.Version))
+	formatter.PrintKeyValue("Entries", fmt.Sprintf("%d used / %d max", len(t.Entries), t.MaxEntries))
+	fmt.Println()
+
+	var rows [][]string
+	for _, e := range t.Entries {
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", e.Index),
+			fmt.Sprintf("%q", e.Name),
+			t64EntryTypeName(e.EntryType),
+			fmt.Sprintf("$%04X", e.Start),
+			fmt.Sprintf("$%04X", e.End),
+			fmt.Sprintf("%d", e.Size),
+		})
+	}
+	formatter.PrintTable([]string{"Entry", "Name", "Type", "Start", "End", "Size"}, rows)
+}
+
+// printTAP prints the decoded headers of a TAP file
+func printTAP(path string, t *tape.TAP) {
+	if jsonOut {
+		formatter.PrintData(t)
+		return
+	}
+
+	formatter.PrintHeader(fmt.Sprintf("📼 %s", filepath.Base(path)))
+	fmt.Println()
+	formatter.PrintKeyValue("TAP Version", fmt.Sprintf("%d", t.Version))
+	formatter.PrintKeyValue("Pulses", fmt.Sprintf("%d", t.Pulses))
+	formatter.PrintKeyValue("Blocks", fmt.Sprintf("%d", t.Blocks))
+	fmt.Println()
+
+	if len(t.Headers) == 0 {
+		formatter.Info("No standard CBM headers found (custom turbo loader?)")
+		return
+	}
+
+	var rows [][]string
+	for _, h := range t.Headers {
+		copyStr := "first"
+		if h.Repeat {
+			copyStr = "repeat"
+		}
+		checksum := "ok"
+		if !h.ChecksumOK {
+			checksum = "BAD"
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", h.Block),
+			fmt.Sprintf("%q", h.Name),
+			h.TypeName,
+			fmt.Sprintf("$%04X", h.Start),
+			fmt.Sprintf("$%04X", h.End),
+			copyStr,
+			checksum,
+		})
+	}
+	formatter.PrintTable([]string{"Block", "Name", "Type", "Start", "End", "Copy", "Checksum"}, rows)
+}
+
+// t64EntryTypeName returns a short name for a T64 entry type
+func t64EntryTypeName(t byte) string {
+	switch t {
+	case tape.T64EntryNormal:
+		return "PRG"
+	case tape.T64EntrySnapshot:
+		return "snapshot"
+	default:
+		return fmt.Sprintf("type %d", t)
+	}
+}
+
+// ============================================================================
+// TAPE EXTRACT - Extract a PRG from a T64 container
+// ============================================================================
+
+var tapeExtractCmd = &cobra.Command{
+	Use:   "extract <file.t64> [output.prg]",
+	Short: "Extract a file from a T64 container as PRG",
+	Long: `Extract one entry of a T64 container as a PRG file.
+
+The PRG gets the entry's start address as its load address. If no output
+path is given, the entry's name is used.
+
+Examples:
+  c64u tape extract game.t64
+  c64u tape extract game.t64 game.prg --entry 1`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		t, err := tape.ReadT64File(args[0])
+		if err != nil {
+			formatter.Error("Failed to read T64 file", []string{err.Error()})
+			return
+		}
+
+		entry, prg, err := extractT64Entry(t, tapeEntry)
+		if err != nil {
+			formatter.Error("Failed to extract entry", []string{err.Error()})
+			return
+		}
+
+		outPath := ""
+		if len(args) > 1 {
+			outPath = args[1]
+		} else {
+			outPath = safeFileName(entry.Name, fmt.Sprintf("entry%d", entry.Index)) + ".prg"
+		}
+
+		if err := os.WriteFile(outPath, prg, 0644); err != nil {
+			formatter.Error("Failed to write PRG file", []string{err.Error()})
+			return
+		}
+
+		formatter.Success(fmt.Sprintf("Extracted %q", entry.Name), map[string]interface{}{
+			"file":         outPath,
+			"load_address": fmt.Sprintf("$%04X", entry.Start),
+			"size":         fmt.Sprintf("%d bytes", len(prg)),
+		})
+	},
+}
+
+// extractT64Entry returns the selected entry as PRG data.
+// A negative index selects the first used entry.
+func extractT64Entry(t *tape.T64, index int) (*tape.T64Entry, []byte, error) {
+	if index < 0 {
+		index = t.Entries[0].Index
+	}
+
+	entry, err := t.Entry(index)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prg, err := t.PRG(index)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return entry, prg, nil
+}
+
+// safeFileName turns a C64 file name into a usable local file name
+func safeFileName(name, fallback string) string {
+	name = strings.TrimSpace(name)
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		return fallback
+	}
+	return strings.ToLower(name)
+}
+
+func init() {
+	tapeExtractCmd.Flags().IntVar(&tapeEntry, "entry", -1, "T64 directory entry index (default: first entry)")
+
+	tapeCmd.AddCommand(tapeLsCmd)
+	tapeCmd.AddCommand(tapeExtractCmd)
+}
@@ -3,6 +3,9 @@ package api
 import (
 	"fmt"
 	"os"
+	"path"
+	"strconv"
+	"strings"
 )
 
 // Floppy Drive Operations API
@@ -121,3 +124,69 @@ func (c *Client) DrivesSetMode(drive, mode string) (*Response, error) {
 	endpoint := fmt.Sprintf("/v1/drives/%s:set_mode", drive)
 	return c.Put(endpoint, params)
 }
+
+// DriveInfo describes a drive as reported by DrivesList
+type DriveInfo struct {
+	Name      string // drive identifier used by the API (e.g. "a")
+	Enabled   bool
+	BusID     int
+	Type      string
+	ImageFile string
+	ImagePath string
+	Mode      string // only set if reported by the firmware
+}
+
+// MountedImage returns the full path of the mounted image, or "" if none
+func (d *DriveInfo) MountedImage() string {
+	if d.ImageFile == "" {
+		return ""
+	}
+	if d.ImagePath == "" {
+		return d.ImageFile
+	}
+	if strings.HasSuffix(d.ImagePath, d.ImageFile) {
+		return d.ImagePath
+	}
+	return path.Join(d.ImagePath, d.ImageFile)
+}
+
+// FindDrive looks up a drive by API name ("a", "b") or bus ID ("8", "9")
+func (c *Client) FindDrive(drive string) (*DriveInfo, error) {
+	resp, err := c.DrivesList()
+	if err != nil {
+		return nil, err
+	}
+	if resp.HasErrors() {
+		return nil, fmt.Errorf("failed to list drives: %s", strings.Join(resp.Errors, "; "))
+	}
+
+	list, _ := resp.Data["drives"].([]interface{})
+	for _, item := range list {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for name, raw := range entry {
+			fields, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			info := &DriveInfo{Name: name}
+			info.Enabled, _ = fields["enabled"].(bool)
+			if busID, ok := fields["bus_id"].(float64); ok {
+				info.BusID = int(busID)
+			}
+			info.Type, _ = fields["type"].(string)
+			info.ImageFile, _ = fields["image_file"].(string)
+			info.ImagePath, _ = fields["image_path"].(string)
+			info.Mode, _ = fields["mode"].(string)
+
+			if strings.EqualFold(name, drive) || strconv.Itoa(info.BusID) == drive {
+				return info, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("drive %s not found", drive)
+}
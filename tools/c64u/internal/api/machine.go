@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Machine Control API - System control and memory operations
@@ -79,6 +80,65 @@ func (c *Client) MachineReadMem(address string, length int) (*Response, error) {
 	return c.Get("/v1/machine:readmem", params)
 }
 
+// ReadMemChunk is the default block size used by MachineReadMemRange
+const ReadMemChunk = 1024
+
+// MachineReadMemRange reads an arbitrary memory range (up to the full 64K)
+// by splitting it into DMA reads of at most chunk bytes (ReadMemChunk if 0)
+// and reassembling the result
+func (c *Client) MachineReadMemRange(start, length, chunk int) ([]byte, error) {
+	if start < 0 || length < 0 || start+length > 0x10000 {
+		return nil, fmt.Errorf("range $%04X+%d exceeds 64K", start, length)
+	}
+	if chunk <= 0 {
+		chunk = ReadMemChunk
+	}
+
+	data := make([]byte, 0, length)
+	for addr := start; addr < start+length; addr += chunk {
+		n := chunk
+		if addr+n > start+length {
+			n = start + length - addr
+		}
+
+		resp, err := c.MachineReadMem(fmt.Sprintf("%04X", addr), n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read $%04X: %w", addr, err)
+		}
+		if resp.HasErrors() {
+			return nil, fmt.Errorf("failed to read $%04X: %s", addr, strings.Join(resp.Errors, "; "))
+		}
+		if len(resp.RawBody) < n {
+			return nil, fmt.Errorf("short read at $%04X: got %d of %d bytes", addr, len(resp.RawBody), n)
+		}
+		data = append(data, resp.RawBody[:n]...)
+	}
+
+	return data, nil
+}
+
+// MachineWriteMemRange writes data of any length (up to the full 64K)
+// with a single binary writemem upload
+func (c *Client) MachineWriteMemRange(start int, data []byte) error {
+	if start < 0 || start+len(data) > 0x10000 {
+		return fmt.Errorf("write of %d bytes at $%04X exceeds 64K", len(data), start)
+	}
+
+	params := map[string]string{
+		"address": fmt.Sprintf("%04X", start),
+	}
+
+	resp, err := c.Post("/v1/machine:writemem", bytes.NewReader(data), params)
+	if err != nil {
+		return fmt.Errorf("failed to write $%04X: %w", start, err)
+	}
+	if resp.HasErrors() {
+		return fmt.Errorf("failed to write $%04X: %s", start, strings.Join(resp.Errors, "; "))
+	}
+
+	return nil
+}
+
 // MachineDebugReg reads debug register $D7FF (U64-only)
 func (c *Client) MachineDebugReg() (*Response, error) {
 	return c.Get("/v1/machine:debugreg", nil)
@@ -128,6 +188,17 @@ func bytesToHex(data []byte) string {
 
 // FormatMemoryDump formats binary memory data as hex dump
 func FormatMemoryDump(data []byte, startAddr int) string {
+	return FormatMemoryDumpWith(data, startAddr, func(b byte) rune {
+		if b >= 32 && b <= 126 {
+			return rune(b)
+		}
+		return '.'
+	})
+}
+
+// FormatMemoryDumpWith formats a hex dump, rendering the text column with
+// the given function (e.g. PETSCII or screen codes)
+func FormatMemoryDumpWith(data []byte, startAddr int, render func(byte) rune) string {
 	var buf bytes.Buffer
 
 	for i := 0; i < len(data); i += 16 {
@@ -146,15 +217,10 @@ func FormatMemoryDump(data []byte, startAddr int) string {
 			}
 		}
 
-		// ASCII representation
+		// Text representation
 		buf.WriteString(" |")
 		for j := 0; j < 16 && i+j < len(data); j++ {
-			b := data[i+j]
-			if b >= 32 && b <= 126 {
-				buf.WriteByte(b)
-			} else {
-				buf.WriteByte('.')
-			}
+			buf.WriteRune(render(data[i+j]))
 		}
 		buf.WriteString("|\n")
 	}
@@ -0,0 +1,577 @@
+package asm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/disasm"
+)
+
+// Resolver looks up labels defined outside the snippet, e.g. from a
+// symbol file. *symbols.Table implements it.
+type Resolver interface {
+	Lookup(name string) (int, bool)
+}
+
+// Line is one assembled source line
+type Line struct {
+	Number  int    `json:"line"`
+	Address int    `json:"address"`
+	Bytes   []byte `json:"-"`
+	Hex     string `json:"bytes,omitempty"`
+	Source  string `json:"source"`
+}
+
+// Segment is a contiguous block of assembled bytes
+type Segment struct {
+	Start int    `json:"start"`
+	Data  []byte `json:"-"`
+}
+
+// End returns the last address of the segment
+func (s Segment) End() int {
+	return s.Start + len(s.Data) - 1
+}
+
+// Result is the output of Assemble
+type Result struct {
+	Lines    []Line         `json:"lines"`
+	Segments []Segment      `json:"segments"`
+	Labels   map[string]int `json:"labels,omitempty"`
+}
+
+// Size returns the total number of assembled bytes
+func (r *Result) Size() int {
+	n := 0
+	for _, s := range r.Segments {
+		n += len(s.Data)
+	}
+	return n
+}
+
+// opcodeIndex maps "mnemonic mode" to the opcode byte. Documented opcodes
+// win over undocumented ones with the same name and mode (e.g. nop).
+var opcodeIndex = map[string]byte{}
+
+func init() {
+	for pass := 0; pass < 2; pass++ {
+		for b, op := range disasm.Opcodes {
+			if op.Illegal != (pass == 1) {
+				continue
+			}
+			key := op.Mnemonic + " " + op.Mode
+			if _, ok := opcodeIndex[key]; !ok {
+				opcodeIndex[key] = byte(b)
+			}
+		}
+	}
+}
+
+// assembler holds the state of one Assemble call
+type assembler struct {
+	origin   int
+	pc       int
+	labels   map[string]int
+	external Resolver
+	encoding string
+	final    bool         // second pass: all labels must resolve
+	modes    map[int]bool // lines whose operand was sized as zero page in pass one
+}
+
+// Assemble translates source into machine code starting at origin.
+// Supported are labels ("name:"), all addressing modes, undocumented
+// opcodes by their KickAssembler names, "*=" and the .byte, .word, .text,
+// .const, .label and .encoding directives. Comments start with // or ;.
+func Assemble(source string, origin int, external Resolver) (*Result, error) {
+	a := &assembler{
+		origin:   origin,
+		labels:   make(map[string]int),
+		external: external,
+		modes:    make(map[int]bool),
+	}
+
+	// Pass one collects labels and fixes instruction sizes
+	if _, err := a.pass(source); err != nil {
+		return nil, err
+	}
+	a.final = true
+	res, err := a.pass(source)
+	if err != nil {
+		return nil, err
+	}
+	res.Labels = a.labels
+	return res, nil
+}
+
+// pass runs over the whole source once
+func (a *assembler) pass(source string) (*Result, error) {
+	a.pc = a.origin
+	a.encoding = EncodingScreenMixed
+	res := &Result{}
+	seg := Segment{Start: a.pc}
+
+	for n, raw := range strings.Split(source, "\n") {
+		number := n + 1
+		text := stripComment(raw)
+
+		label, rest := splitLabel(text)
+		if label != "" {
+			if _, ok := a.labels[label]; ok && !a.final {
+				return nil, fmt.Errorf("line %d: label %q defined twice", number, label)
+			}
+			a.labels[label] = a.pc
+		}
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			continue
+		}
+
+		// Origin changes start a new segment
+		if strings.HasPrefix(rest, "*") && strings.Contains(rest, "=") {
+			_, expr, _ := strings.Cut(rest, "=")
+			v, err := a.eval(expr)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", number, err)
+			}
+			if v < 0 || v > 0xFFFF {
+				return nil, fmt.Errorf("line %d: origin %d is outside $0000-$FFFF", number, v)
+			}
+			if len(seg.Data) > 0 {
+				res.Segments = append(res.Segments, seg)
+			}
+			a.pc = v
+			seg = Segment{Start: v}
+			continue
+		}
+
+		var out []byte
+		var err error
+		if strings.HasPrefix(rest, ".") {
+			out, err = a.directive(rest)
+		} else {
+			out, err = a.instruction(rest, number)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+		if a.pc+len(out) > 0x10000 {
+			return nil, fmt.Errorf("line %d: code runs past $FFFF", number)
+		}
+
+		res.Lines = append(res.Lines, Line{
+			Number:  number,
+			Address: a.pc,
+			Bytes:   out,
+			Hex:     fmt.Sprintf("% X", out),
+			Source:  strings.TrimSpace(raw),
+		})
+		seg.Data = append(seg.Data, out...)
+		a.pc += len(out)
+	}
+
+	if len(seg.Data) > 0 {
+		res.Segments = append(res.Segments, seg)
+	}
+	return res, nil
+}
+
+// instruction assembles a mnemonic and its operand
+func (a *assembler) instruction(text string, number int) ([]byte, error) {
+	mnemonic, operand, _ := strings.Cut(text, " ")
+	mnemonic = strings.ToLower(mnemonic)
+	operand = strings.TrimSpace(operand)
+
+	if !knownMnemonic(mnemonic) {
+		return nil, fmt.Errorf("unknown mnemonic %q", mnemonic)
+	}
+
+	mode, expr := parseOperand(operand)
+	switch mode {
+	case disasm.ModeImplied:
+		if _, ok := opcodeIndex[mnemonic+" "+disasm.ModeImplied]; !ok {
+			mode = disasm.ModeAccumulator
+		}
+		return a.emit(mnemonic, mode, 0)
+	case disasm.ModeAccumulator:
+		return a.emit(mnemonic, mode, 0)
+	}
+
+	value, err := a.eval(expr)
+	if err != nil {
+		return nil, err
+	}
+	// Negative addresses wrap around like on the 6502
+	if mode != disasm.ModeImmediate {
+		value &= 0xFFFF
+	}
+
+	switch mode {
+	case disasm.ModeImmediate:
+		if a.final && (value < -0x80 || value > 0xFF) {
+			return nil, fmt.Errorf("immediate value %d does not fit in a byte", value)
+		}
+	case disasm.ModeAbsolute, disasm.ModeAbsoluteX, disasm.ModeAbsoluteY:
+		if _, ok := opcodeIndex[mnemonic+" "+disasm.ModeRelative]; ok && mode == disasm.ModeAbsolute {
+			return a.branch(mnemonic, value)
+		}
+		// Use zero page if the operand was known to fit in pass one
+		zp := map[string]string{
+			disasm.ModeAbsolute:  disasm.ModeZeroPage,
+			disasm.ModeAbsoluteX: disasm.ModeZeroPageX,
+			disasm.ModeAbsoluteY: disasm.ModeZeroPageY,
+		}[mode]
+		if !a.final {
+			a.modes[number] = value <= 0xFF && a.resolved(expr)
+		}
+		if _, ok := opcodeIndex[mnemonic+" "+zp]; ok && a.modes[number] {
+			mode = zp
+		}
+	case disasm.ModeIndirectX, disasm.ModeIndirectY:
+		if a.final && value > 0xFF {
+			return nil, fmt.Errorf("indirect indexed address $%X is not in zero page", value)
+		}
+	}
+	return a.emit(mnemonic, mode, value)
+}
+
+// branch assembles a relative branch
+func (a *assembler) branch(mnemonic string, target int) ([]byte, error) {
+	offset := target - (a.pc + 2)
+	if a.final && (offset < -128 || offset > 127) {
+		return nil, fmt.Errorf("branch target $%04X out of range (%d bytes)", target, offset)
+	}
+	return []byte{opcodeIndex[mnemonic+" "+disasm.ModeRelative], byte(offset)}, nil
+}
+
+// emit encodes an opcode and operand
+func (a *assembler) emit(mnemonic, mode string, value int) ([]byte, error) {
+	op, ok := opcodeIndex[mnemonic+" "+mode]
+	if !ok {
+		return nil, fmt.Errorf("%s does not support %s addressing", mnemonic, modeNames[mode])
+	}
+	switch disasm.Opcodes[op].Size() {
+	case 1:
+		return []byte{op}, nil
+	case 2:
+		return []byte{op, byte(value)}, nil
+	default:
+		return []byte{op, byte(value), byte(value >> 8)}, nil
+	}
+}
+
+// modeNames are used in error messages
+var modeNames = map[string]string{
+	disasm.ModeImplied:     "implied",
+	disasm.ModeAccumulator: "accumulator",
+	disasm.ModeImmediate:   "immediate",
+	disasm.ModeZeroPage:    "zero page",
+	disasm.ModeZeroPageX:   "zero page,x",
+	disasm.ModeZeroPageY:   "zero page,y",
+	disasm.ModeAbsolute:    "absolute",
+	disasm.ModeAbsoluteX:   "absolute,x",
+	disasm.ModeAbsoluteY:   "absolute,y",
+	disasm.ModeIndirect:    "indirect",
+	disasm.ModeIndirectX:   "(indirect,x)",
+	disasm.ModeIndirectY:   "(indirect),y",
+	disasm.ModeRelative:    "relative",
+}
+
+// knownMnemonic reports whether any opcode uses the mnemonic
+func knownMnemonic(m string) bool {
+	for key := range opcodeIndex {
+		if strings.HasPrefix(key, m+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOperand determines the addressing mode from the operand syntax.
+// Zero page and relative modes are chosen later from the value.
+func parseOperand(op string) (string, string) {
+	lower := strings.ToLower(strings.ReplaceAll(op, " ", ""))
+	switch {
+	case lower == "":
+		return disasm.ModeImplied, ""
+	case lower == "a":
+		return disasm.ModeAccumulator, ""
+	case strings.HasPrefix(lower, "#"):
+		return disasm.ModeImmediate, op[strings.Index(op, "#")+1:]
+	case strings.HasPrefix(lower, "(") && strings.HasSuffix(lower, ",x)"):
+		return disasm.ModeIndirectX, strings.TrimSpace(op[strings.Index(op, "(")+1 : strings.LastIndex(op, ",")])
+	case strings.HasPrefix(lower, "(") && strings.HasSuffix(lower, "),y"):
+		return disasm.ModeIndirectY, strings.TrimSpace(op[strings.Index(op, "(")+1 : strings.LastIndex(op, ")")])
+	case strings.HasPrefix(lower, "(") && strings.HasSuffix(lower, ")"):
+		return disasm.ModeIndirect, op[strings.Index(op, "(")+1 : strings.LastIndex(op, ")")]
+	case strings.HasSuffix(lower, ",x"):
+		return disasm.ModeAbsoluteX, op[:strings.LastIndex(op, ",")]
+	case strings.HasSuffix(lower, ",y"):
+		return disasm.ModeAbsoluteY, op[:strings.LastIndex(op, ",")]
+	default:
+		return disasm.ModeAbsolute, op
+	}
+}
+
+// directive handles .byte, .word, .text, .const/.label and .encoding
+func (a *assembler) directive(text string) ([]byte, error) {
+	name, args, _ := strings.Cut(text, " ")
+	args = strings.TrimSpace(args)
+
+	switch strings.ToLower(name) {
+	case ".byte", ".by":
+		var out []byte
+		for _, expr := range splitArgs(args) {
+			if isQuoted(expr) {
+				out = append(out, a.encode(unquote(expr))...)
+				continue
+			}
+			v, err := a.eval(expr)
+			if err != nil {
+				return nil, err
+			}
+			if a.final && (v < -0x80 || v > 0xFF) {
+				return nil, fmt.Errorf(".byte value %d does not fit in a byte", v)
+			}
+			out = append(out, byte(v))
+		}
+		return out, nil
+	case ".word", ".wo":
+		var out []byte
+		for _, expr := range splitArgs(args) {
+			v, err := a.eval(expr)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, byte(v), byte(v>>8))
+		}
+		return out, nil
+	case ".text":
+		var out []byte
+		for _, expr := range splitArgs(args) {
+			if !isQuoted(expr) {
+				return nil, fmt.Errorf(".text expects a quoted string")
+			}
+			out = append(out, a.encode(unquote(expr))...)
+		}
+		return out, nil
+	case ".const", ".label":
+		label, expr, ok := strings.Cut(args, "=")
+		label = strings.TrimSpace(label)
+		if !ok || label == "" {
+			return nil, fmt.Errorf("%s expects NAME = value", name)
+		}
+		if _, ok := a.labels[label]; ok && !a.final {
+			return nil, fmt.Errorf("label %q defined twice", label)
+		}
+		v, err := a.eval(expr)
+		if err != nil {
+			return nil, err
+		}
+		a.labels[label] = v
+		return nil, nil
+	case ".encoding":
+		enc := unquote(args)
+		if !validEncoding(enc) {
+			return nil, fmt.Errorf("unknown encoding %q (use %s)", enc, strings.Join(Encodings, ", "))
+		}
+		a.encoding = enc
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown directive %s", name)
+	}
+}
+
+// eval evaluates an operand expression: numbers ($hex, %binary, decimal,
+// 'c'), labels, * for the current address, + and -, unary minus and the
+// < and > low/high byte operators. The result may be negative (down to
+// -$8000); callers mask it to 8 or 16 bits.
+func (a *assembler) eval(expr string) (int, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return 0, fmt.Errorf("missing operand")
+	}
+
+	switch expr[0] {
+	case '<':
+		v, err := a.eval(expr[1:])
+		return v & 0xFF, err
+	case '>':
+		v, err := a.eval(expr[1:])
+		return (v >> 8) & 0xFF, err
+	}
+
+	total, sign, start := 0, 1, 0
+	for i := 0; i <= len(expr); i++ {
+		if i < len(expr) {
+			c := expr[i]
+			if c == '\'' && i+2 < len(expr) && expr[i+2] == '\'' {
+				i += 2
+				continue
+			}
+			// A leading '*' is the program counter, not an operator
+			if (c != '+' && c != '-') || i == start {
+				continue
+			}
+		}
+		v, err := a.term(strings.TrimSpace(expr[start:i]))
+		if err != nil {
+			return 0, err
+		}
+		total += sign * v
+		if i < len(expr) {
+			sign = 1
+			if expr[i] == '-' {
+				sign = -1
+			}
+		}
+		start = i + 1
+	}
+
+	if total < -0x8000 || total > 0xFFFF {
+		if a.final {
+			return 0, fmt.Errorf("value %d out of range", total)
+		}
+		total &= 0xFFFF
+	}
+	return total, nil
+}
+
+// term evaluates a single number, character or label
+func (a *assembler) term(t string) (int, error) {
+	switch {
+	case t == "":
+		return 0, fmt.Errorf("missing term")
+	case t == "*":
+		return a.pc, nil
+	case t[0] == '-':
+		v, err := a.term(strings.TrimSpace(t[1:]))
+		return -v, err
+	case strings.HasPrefix(t, "$"):
+		return parseUint(t[1:], 16, t)
+	case strings.HasPrefix(t, "%"):
+		return parseUint(t[1:], 2, t)
+	case strings.HasPrefix(strings.ToLower(t), "0x"):
+		return parseUint(t[2:], 16, t)
+	case len(t) == 3 && t[0] == '\'' && t[2] == '\'':
+		return int(a.encode(t[1:2])[0]), nil
+	case t[0] >= '0' && t[0] <= '9':
+		return parseUint(t, 10, t)
+	}
+
+	if v, ok := a.labels[t]; ok {
+		return v, nil
+	}
+	if a.external != nil {
+		if v, ok := a.external.Lookup(t); ok {
+			return v, nil
+		}
+	}
+	if !a.final {
+		// Forward reference, resolved in pass two
+		return 0xFFFF, nil
+	}
+	return 0, fmt.Errorf("unknown label %q", t)
+}
+
+// resolved reports whether all labels in expr are already known, so a
+// zero page decision in pass one is safe
+func (a *assembler) resolved(expr string) bool {
+	for _, f := range strings.FieldsFunc(expr, func(r rune) bool {
+		return strings.ContainsRune("+-<>* ", r)
+	}) {
+		if f == "" || strings.ContainsAny(f[:1], "$%'0123456789") {
+			continue
+		}
+		if _, ok := a.labels[f]; ok {
+			continue
+		}
+		if a.external != nil {
+			if _, ok := a.external.Lookup(f); ok {
+				continue
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// parseUint parses a number in the given base
+func parseUint(s string, base int, orig string) (int, error) {
+	v, err := strconv.ParseUint(s, base, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", orig)
+	}
+	return int(v), nil
+}
+
+// stripComment removes // and ; comments outside of quotes
+func stripComment(line string) string {
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case line[i] == '\'' && i+2 < len(line) && line[i+2] == '\'':
+			// Character literal such as ';'
+			i += 2
+		case line[i] == ';':
+			return line[:i]
+		case line[i] == '/' && i+1 < len(line) && line[i+1] == '/':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitLabel separates a leading "label:" from the rest of the line
+func splitLabel(line string) (string, string) {
+	trimmed := strings.TrimSpace(line)
+	i := strings.Index(trimmed, ":")
+	if i <= 0 {
+		return "", line
+	}
+	name := trimmed[:i]
+	for j, r := range name {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || j > 0 && r >= '0' && r <= '9') {
+			return "", line
+		}
+	}
+	return name, trimmed[i+1:]
+}
+
+// splitArgs splits directive arguments at commas outside of quotes
+func splitArgs(s string) []string {
+	var args []string
+	inQuote, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuote = !inQuote
+		case ',':
+			if !inQuote {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" {
+		args = append(args, rest)
+	}
+	return args
+}
+
+// isQuoted reports whether s is a double-quoted string
+func isQuoted(s string) bool {
+	return len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"'
+}
+
+// unquote removes surrounding double quotes
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if isQuoted(s) {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,161 @@
+package asm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// resolver is a fixed set of external labels
+type resolver map[string]int
+
+func (r resolver) Lookup(name string) (int, bool) {
+	v, ok := r[name]
+	return v, ok
+}
+
+// hexSegments formats the segments as "START: BYTES" lines
+func hexSegments(res *Result) string {
+	var out []string
+	for _, s := range res.Segments {
+		out = append(out, fmt.Sprintf("%04X: % X", s.Start, s.Data))
+	}
+	return strings.Join(out, "\n")
+}
+
+func TestAssemble(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "basic",
+			source: "lda #$00\nsta $d020 // border\nrts",
+			want:   "C000: A9 00 8D 20 D0 60",
+		},
+		{
+			name:   "zero page",
+			source: "lda $10\nlda $10,x\nldx $10,y\nlda ($fb),y\nsta ($fb,x)",
+			want:   "C000: A5 10 B5 10 B6 10 B1 FB 81 FB",
+		},
+		{
+			name:   "forward value stays absolute",
+			source: "lda zp\n.const zp = $10\nlda zp",
+			want:   "C000: AD 10 00 A5 10",
+		},
+		{
+			name:   "branches",
+			source: "loop: dex\nbne loop\nbeq done\nnop\ndone: rts",
+			want:   "C000: CA D0 FD F0 01 EA 60",
+		},
+		{
+			name:   "accumulator and indirect",
+			source: "asl\nasl a\njmp ($fffc)\njmp *",
+			want:   "C000: 0A 0A 6C FC FF 4C 05 C0",
+		},
+		{
+			name:   "undocumented",
+			source: "lax $10\nnop\nsbc #1",
+			want:   "C000: A7 10 EA E9 01",
+		},
+		{
+			name:   "expressions",
+			source: "lda #<target\nldx #>target\nlda target+2-1\nlda #%101\nlda #-1\ntarget: rts",
+			want:   "C000: A9 0B A2 C0 AD 0C C0 A9 05 A9 FF 60",
+		},
+		{
+			name:   "text encodings",
+			source: ".byte \"ab\", 1\n.text \"AB\"\n.encoding \"petscii_upper\"\n.text \"AB\"\nlda #';' ; semicolon",
+			want:   "C000: 01 02 01 41 42 41 42 A9 3B",
+		},
+		{
+			name:   "words and segments",
+			source: ".word $c000, end\n*=$c100\nend: nop",
+			want:   "C000: 00 C0 00 C1\nC100: EA",
+		},
+		{
+			name:   "external labels",
+			source: "jsr chrout\nlda ptr\nlda local\n.label local=$fb",
+			want:   "C000: 20 D2 FF A5 FB AD FB 00",
+		},
+	}
+
+	ext := resolver{"chrout": 0xFFD2, "ptr": 0xFB}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Assemble(tt.source, 0xC000, ext)
+			if err != nil {
+				t.Fatalf("Assemble: %v", err)
+			}
+			if got := hexSegments(res); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssembleLines(t *testing.T) {
+	res, err := Assemble("start: lda #1\n\n  ; comment only\nend: rts", 0x1000, nil)
+	if err != nil {
+		t.Fatalf("Assemble: %v", err)
+	}
+	if len(res.Lines) != 2 || res.Lines[1].Number != 4 || res.Lines[1].Address != 0x1002 || res.Lines[1].Hex != "60" {
+		t.Errorf("lines = %+v", res.Lines)
+	}
+	if res.Labels["start"] != 0x1000 || res.Labels["end"] != 0x1002 || res.Size() != 3 {
+		t.Errorf("labels = %v, size %d", res.Labels, res.Size())
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"unknown mnemonic", "nop\nfoo #1", "line 2: unknown mnemonic"},
+		{"unsupported mode", "sta #1", "sta does not support immediate"},
+		{"immediate too large", "lda #256", "does not fit in a byte"},
+		{"byte too large", ".byte 1, 300", "does not fit in a byte"},
+		{"indirect not zero page", "lda ($100),y", "not in zero page"},
+		{"branch out of range", "bne far\n.text \"" + strings.Repeat("x", 200) + "\"\nfar: rts", "out of range"},
+		{"unknown label", "jmp nowhere", "unknown label \"nowhere\""},
+		{"label defined twice", "a: nop\na: nop", "line 2: label \"a\" defined twice"},
+		{"constant defined twice", ".const x = 1\n.const x = 2", "line 2: label \"x\" defined twice"},
+		{"constant redefines label", "x: nop\n.label x = 1", "line 2: label \"x\" defined twice"},
+		{"origin too high", "*=$10000", "line 1: value 65536 out of range"},
+		{"negative origin", "*=-1", "origin -1 is outside"},
+		{"past end of memory", "*=$ffff\nnop\nnop", "line 3: code runs past $FFFF"},
+		{"unknown directive", ".fill 10", "unknown directive .fill"},
+		{"unknown encoding", ".encoding \"ascii\"", "unknown encoding"},
+		{"bad number", "lda #$zz", "invalid number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Assemble(tt.source, 0xC000, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripComment(t *testing.T) {
+	tests := []struct {
+		line, want string
+	}{
+		{"lda #1 ; load", "lda #1 "},
+		{"lda #1 // load", "lda #1 "},
+		{`.text "a;b" ; c`, `.text "a;b" `},
+		{`.text "http://x"`, `.text "http://x"`},
+		{"lda #';'", "lda #';'"},
+		{"cmp #'/' // slash", "cmp #'/' "},
+	}
+	for _, tt := range tests {
+		if got := stripComment(tt.line); got != tt.want {
+			t.Errorf("stripComment(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
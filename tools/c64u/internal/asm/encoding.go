@@ -0,0 +1,53 @@
+package asm
+
+import "github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+
+// Text encodings for .text and character literals, named as in KickAssembler
+const (
+	EncodingScreenMixed  = "screencode_mixed"
+	EncodingScreenUpper  = "screencode_upper"
+	EncodingPETSCIIMixed = "petscii_mixed"
+	EncodingPETSCIIUpper = "petscii_upper"
+)
+
+// Encodings lists the supported text encodings
+var Encodings = []string{EncodingScreenMixed, EncodingScreenUpper, EncodingPETSCIIMixed, EncodingPETSCIIUpper}
+
+// validEncoding reports whether name is a supported encoding
+func validEncoding(name string) bool {
+	for _, e := range Encodings {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+// encode converts text with the current encoding. Characters without an
+// equivalent become '?'.
+func (a *assembler) encode(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		out = append(out, encodeRune(r, a.encoding))
+	}
+	return out
+}
+
+// encodeRune converts one character
+func encodeRune(r rune, encoding string) byte {
+	cs := petscii.Upper
+	if encoding == EncodingScreenMixed || encoding == EncodingPETSCIIMixed {
+		cs = petscii.Lower
+	}
+	var b byte
+	var ok bool
+	if encoding == EncodingPETSCIIMixed || encoding == EncodingPETSCIIUpper {
+		b, ok = petscii.FromRune(r, cs)
+	} else {
+		b, ok = petscii.ScreenFromRune(r, cs)
+	}
+	if !ok {
+		return '?'
+	}
+	return b
+}
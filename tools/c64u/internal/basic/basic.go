@@ -0,0 +1,201 @@
+package basic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+)
+
+// BASIC V2 program layout
+//
+// Each line is stored as:
+//   link   (2 bytes) address of the next line, 0 ends the program
+//   number (2 bytes) line number
+//   text   tokenised text, terminated by $00
+//
+// Keywords are stored as single byte tokens $80-$CB, π as $FF. Text in
+// quotes is stored unchanged.
+
+// Zero page pointers and defaults
+const (
+	PointerStart = 0x2B // start of BASIC text
+	PointerVars  = 0x2D // start of variables, i.e. end of program
+	DefaultStart = 0x0801
+	MaxLine      = 63999
+)
+
+// Keywords are the BASIC V2 keywords for the tokens $80-$CB
+var Keywords = []string{
+	"END", "FOR", "NEXT", "DATA", "INPUT#", "INPUT", "DIM", "READ",
+	"LET", "GOTO", "RUN", "IF", "RESTORE", "GOSUB", "RETURN", "REM",
+	"STOP", "ON", "WAIT", "LOAD", "SAVE", "VERIFY", "DEF", "POKE",
+	"PRINT#", "PRINT", "CONT", "LIST", "CLR", "CMD", "SYS", "OPEN",
+	"CLOSE", "GET", "NEW", "TAB(", "TO", "FN", "SPC(", "THEN",
+	"NOT", "STEP", "+", "-", "*", "/", "↑", "AND",
+	"OR", ">", "=", "<", "SGN", "INT", "ABS", "USR",
+	"FRE", "POS", "SQR", "RND", "LOG", "EXP", "COS", "SIN",
+	"TAN", "ATN", "PEEK", "LEN", "STR$", "VAL", "ASC", "CHR$",
+	"LEFT$", "RIGHT$", "MID$", "GO",
+}
+
+// Token values
+const (
+	TokenFirst = 0x80
+	TokenLast  = TokenFirst + 75 // GO
+	TokenREM   = 0x8F
+	TokenDATA  = 0x83
+	TokenSYS   = 0x9E
+	TokenPi    = 0xFF
+)
+
+// Keyword returns the keyword of a token
+func Keyword(token byte) (string, bool) {
+	switch {
+	case token == TokenPi:
+		return "π", true
+	case token >= TokenFirst && token <= TokenLast:
+		return Keywords[token-TokenFirst], true
+	}
+	return "", false
+}
+
+// Line is one program line
+type Line struct {
+	Address int    `json:"address"`
+	Number  int    `json:"number"`
+	Text    string `json:"text"`
+}
+
+// Program is a BASIC program walked along its line links
+type Program struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"` // address after the final zero link
+	Lines []Line `json:"lines"`
+	// ExtensionTokens lists tokens beyond V2, used by BASIC extensions
+	ExtensionTokens []byte   `json:"extension_tokens,omitempty"`
+	Warnings        []string `json:"warnings,omitempty"`
+}
+
+// Parse walks the line links of a program in mem, which holds memory from
+// address base on. Broken links end the walk with a warning instead of an
+// error, so whatever is listable is listed.
+func Parse(mem []byte, base, start int, cs petscii.Charset) *Program {
+	p := &Program{Start: start, End: -1}
+	seenTokens := map[byte]bool{}
+	prevNumber := -1
+	addr := start
+
+	for {
+		off := addr - base
+		if off < 0 || off+2 > len(mem) {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("line link $%04X points outside the memory read", addr))
+			return p
+		}
+		link := int(mem[off]) | int(mem[off+1])<<8
+		if link == 0 {
+			p.End = addr + 2
+			return p
+		}
+		if off+4 > len(mem) {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("line at $%04X is truncated", addr))
+			return p
+		}
+		number := int(mem[off+2]) | int(mem[off+3])<<8
+
+		textEnd := off + 4
+		for textEnd < len(mem) && mem[textEnd] != 0 {
+			textEnd++
+		}
+		if textEnd >= len(mem) {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("line %d at $%04X has no end marker", number, addr))
+			return p
+		}
+		text := mem[off+4 : textEnd]
+		for _, t := range extensionTokens(text) {
+			if !seenTokens[t] {
+				seenTokens[t] = true
+				p.ExtensionTokens = append(p.ExtensionTokens, t)
+			}
+		}
+		p.Lines = append(p.Lines, Line{Address: addr, Number: number, Text: Detokenize(text, cs)})
+
+		if number <= prevNumber {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("line %d follows line %d", number, prevNumber))
+		}
+		prevNumber = number
+		if next := base + textEnd + 1; link != next {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("line %d links to $%04X instead of $%04X", number, link, next))
+		}
+		if link <= addr {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("line %d links backwards to $%04X; listing stopped", number, link))
+			return p
+		}
+		addr = link
+	}
+}
+
+// Detokenize converts the text of a line to a string. Like TokenizeLine,
+// keywords are only expanded outside quotes, REM and DATA; everything else
+// is PETSCII text with control codes written as {NAME} escapes. Bytes
+// from $80 up outside quotes, REM and DATA that are not V2 tokens, such as
+// BASIC extension tokens, are written as {$xx}.
+func Detokenize(text []byte, cs petscii.Charset) string {
+	var sb strings.Builder
+	var literal []byte
+	quote, rem, data := false, false, false
+
+	for _, b := range text {
+		switch {
+		case b == '"':
+			quote = !quote
+		case quote || rem:
+		case data && b == ':':
+			data = false
+		case data:
+		default:
+			if kw, ok := Keyword(b); ok {
+				sb.WriteString(petscii.DecodeEscaped(literal, cs))
+				literal = literal[:0]
+				sb.WriteString(kw)
+				rem = b == TokenREM
+				data = b == TokenDATA
+				continue
+			}
+			if b >= 0x80 {
+				// Extension token: keep the byte instead of showing a glyph
+				sb.WriteString(petscii.DecodeEscaped(literal, cs))
+				literal = literal[:0]
+				fmt.Fprintf(&sb, "{$%02X}", b)
+				continue
+			}
+		}
+		literal = append(literal, b)
+	}
+	sb.WriteString(petscii.DecodeEscaped(literal, cs))
+	return sb.String()
+}
+
+// extensionTokens returns the tokens V2 does not know, skipping quotes,
+// REM and DATA where no tokens are stored
+func extensionTokens(text []byte) []byte {
+	var tokens []byte
+	quote, rem, data := false, false, false
+	for _, b := range text {
+		switch {
+		case b == '"':
+			quote = !quote
+		case quote || rem:
+		case data && b == ':':
+			data = false
+		case data:
+		case b >= 0x80:
+			if _, ok := Keyword(b); !ok {
+				tokens = append(tokens, b)
+			}
+			rem = b == TokenREM
+			data = b == TokenDATA
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,95 @@
+package basic
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+)
+
+// TestRoundTrip tokenises listings and parses them back; the listing must
+// come out unchanged in both character sets
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		cs      petscii.Charset
+		listing []string
+	}{
+		{
+			name: "upper",
+			cs:   petscii.Upper,
+			listing: []string{
+				`10 REM TEST HARNESS`,
+				`20 DATA ALPHA,BETA,"A:B":PRINT "{CLR}{DOWN*3}HELLO"`,
+				`30 FOR I=1 TO 10:PRINT I;:NEXT`,
+				`40 IF A<>B THEN POKE 53280,0:GOTO 10`,
+				`50 A=π*2↑3:REM PRINT GOTO`,
+				`60 SYS 49152`,
+			},
+		},
+		{
+			name: "mixed",
+			cs:   petscii.Lower,
+			listing: []string{
+				`10 REM Test Harness`,
+				`20 DATA Alpha,Beta,"Gamma: Delta":PRINT "{CLR}Hello World"`,
+				`30 PRINT "{RVS ON}Menu{RVS OFF}":GOSUB 100`,
+				`40 REM {DOWN*3}Quiet Zone`,
+				`100 RETURN`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := strings.Join(tt.listing, "\n")
+			program, err := Tokenize(source, DefaultStart, tt.cs)
+			if err != nil {
+				t.Fatalf("Tokenize: %v", err)
+			}
+
+			p := Parse(program, DefaultStart, DefaultStart, tt.cs)
+			if len(p.Warnings) > 0 {
+				t.Errorf("Parse warnings: %v", p.Warnings)
+			}
+			if len(p.ExtensionTokens) > 0 {
+				t.Errorf("unexpected extension tokens % X", p.ExtensionTokens)
+			}
+			if want := DefaultStart + len(program); p.End != want {
+				t.Errorf("End = $%04X, want $%04X", p.End, want)
+			}
+
+			var got []string
+			for _, l := range p.Lines {
+				got = append(got, fmt.Sprintf("%d %s", l.Number, l.Text))
+			}
+			if strings.Join(got, "\n") != source {
+				t.Errorf("round trip mismatch\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), source)
+			}
+		})
+	}
+}
+
+// TestDetokenizeLiterals checks that bytes after REM and in DATA are not
+// expanded as keywords, and that unknown tokens in code stay escaped
+func TestDetokenizeLiterals(t *testing.T) {
+	// $D4 and $C1 are T and A in the lower case set, but also the tokens
+	// LEFT$ and ATN
+	text := []byte{TokenREM, ' ', 0xD4, 'E', 'S', 'T'}
+	if got, want := Detokenize(text, petscii.Lower), "REM Test"; got != want {
+		t.Errorf("REM: got %q, want %q", got, want)
+	}
+
+	text = []byte{TokenDATA, ' ', 0xC1, 'L', 'P', 'H', 'A', ':', 0x99, ' ', 'X'}
+	if got, want := Detokenize(text, petscii.Lower), "DATA Alpha:PRINT x"; got != want {
+		t.Errorf("DATA: got %q, want %q", got, want)
+	}
+
+	// $DB is no V2 token: outside quotes it is an extension token, inside
+	// them $C1 is text again
+	text = []byte{0xDB, '"', 0xC1, '"'}
+	if got, want := Detokenize(text, petscii.Lower), `{$DB}"A"`; got != want {
+		t.Errorf("extension token: got %q, want %q", got, want)
+	}
+}
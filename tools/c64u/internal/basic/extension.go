@@ -0,0 +1,44 @@
+package basic
+
+import "fmt"
+
+// Vector is one of the BASIC indirection vectors at $0300-$030B. BASIC
+// extensions hook into the interpreter by changing them.
+type Vector struct {
+	Address int
+	Name    string
+	Purpose string
+	Default int
+}
+
+// Vectors are the BASIC vectors with their power-on values
+var Vectors = []Vector{
+	{0x0300, "IERROR", "error messages", 0xE38B},
+	{0x0302, "IMAIN", "main loop", 0xA483},
+	{0x0304, "ICRNCH", "tokeniser", 0xA57C},
+	{0x0306, "IQPLOP", "LIST", 0xA71A},
+	{0x0308, "IGONE", "statement execution", 0xA7E4},
+	{0x030A, "IEVAL", "expression evaluation", 0xAE86},
+}
+
+// VectorsStart and VectorsSize give the memory to read for ChangedVectors
+const (
+	VectorsStart = 0x0300
+	VectorsSize  = 12
+)
+
+// ChangedVectors describes the vectors that no longer hold their default,
+// given the memory read from VectorsStart
+func ChangedVectors(mem []byte) []string {
+	var changed []string
+	for _, v := range Vectors {
+		off := v.Address - VectorsStart
+		if off+2 > len(mem) {
+			break
+		}
+		if value := int(mem[off]) | int(mem[off+1])<<8; value != v.Default {
+			changed = append(changed, fmt.Sprintf("%s ($%04X, %s) points to $%04X instead of $%04X", v.Name, v.Address, v.Purpose, value, v.Default))
+		}
+	}
+	return changed
+}
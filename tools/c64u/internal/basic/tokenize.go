@@ -0,0 +1,145 @@
+package basic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+)
+
+// maxLineBytes is the longest tokenised line text BASIC can handle
+const maxLineBytes = 250
+
+// Tokenize converts a text listing to a program stored at start. Every
+// line needs a line number; blank lines are skipped. Keywords are matched
+// without regard to case outside strings, REM and DATA, "?" is PRINT and
+// {NAME} escapes insert control codes.
+func Tokenize(source string, start int, cs petscii.Charset) ([]byte, error) {
+	var out []byte
+	addr := start
+	prev := -1
+
+	for n, raw := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		text := strings.TrimSpace(raw)
+		if text == "" {
+			continue
+		}
+
+		digits := 0
+		for digits < len(text) && text[digits] >= '0' && text[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 {
+			return nil, fmt.Errorf("line %d: missing line number", n+1)
+		}
+		number, err := strconv.Atoi(text[:digits])
+		if err != nil || number > MaxLine {
+			return nil, fmt.Errorf("line %d: line number %s out of range (0-%d)", n+1, text[:digits], MaxLine)
+		}
+		if number <= prev {
+			return nil, fmt.Errorf("line %d: line number %d follows %d", n+1, number, prev)
+		}
+		prev = number
+
+		tokens, err := TokenizeLine(strings.TrimLeft(text[digits:], " "), cs)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		if len(tokens) > maxLineBytes {
+			return nil, fmt.Errorf("line %d: line %d is %d bytes long, BASIC allows %d", n+1, number, len(tokens), maxLineBytes)
+		}
+
+		next := addr + 4 + len(tokens) + 1
+		out = append(out, byte(next), byte(next>>8), byte(number), byte(number>>8))
+		out = append(out, tokens...)
+		out = append(out, 0)
+		addr = next
+	}
+
+	out = append(out, 0, 0)
+	if start+len(out) > 0xA000 {
+		return nil, fmt.Errorf("program runs past the end of BASIC RAM ($%04X)", start+len(out)-1)
+	}
+	return out, nil
+}
+
+// TokenizeLine converts the text of one line, without line number
+func TokenizeLine(text string, cs petscii.Charset) ([]byte, error) {
+	var out []byte
+	quote, rem, data := false, false, false
+
+	for len(text) > 0 {
+		if text[0] == '{' {
+			end := strings.IndexByte(text, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated escape %q", text)
+			}
+			codes, err := petscii.EncodeEscaped(text[:end+1], cs)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, codes...)
+			text = text[end+1:]
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(text)
+		switch {
+		case r == '"':
+			quote = !quote
+		case quote || rem:
+		case data && r == ':':
+			data = false
+		case data:
+		default:
+			if token, length := matchKeyword(text); length > 0 {
+				out = append(out, token)
+				text = text[length:]
+				rem = token == TokenREM
+				data = token == TokenDATA
+				continue
+			}
+			switch r {
+			case '?':
+				out = append(out, 0x99) // PRINT
+				text = text[size:]
+				continue
+			case 'π':
+				out = append(out, TokenPi)
+				text = text[size:]
+				continue
+			}
+		}
+
+		b, ok := petscii.FromRune(r, cs)
+		if !ok {
+			return nil, fmt.Errorf("no PETSCII equivalent for %q", r)
+		}
+		out = append(out, b)
+		text = text[size:]
+	}
+	return out, nil
+}
+
+// matchKeyword finds the keyword at the start of text. Like the C64 the
+// table is searched in token order and the first match wins, so GOTO is
+// found before GO.
+func matchKeyword(text string) (byte, int) {
+	for i, kw := range Keywords {
+		if kw == "↑" {
+			if strings.HasPrefix(text, "↑") {
+				return byte(TokenFirst + i), len("↑")
+			}
+			if strings.HasPrefix(text, "^") {
+				return byte(TokenFirst + i), 1
+			}
+			continue
+		}
+		if len(text) >= len(kw) && strings.EqualFold(text[:len(kw)], kw) {
+			return byte(TokenFirst + i), len(kw)
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,325 @@
+package crt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CRT cartridge image format
+//
+// Header (big-endian, usually 64 bytes):
+//   $00-$0F signature "C64 CARTRIDGE   "
+//   $10-$13 header length
+//   $14-$15 version
+//   $16-$17 hardware type
+//   $18     EXROM line status
+//   $19     GAME line status
+//   $1A     hardware subtype (version 1.01+)
+//   $20-$3F cartridge name (zero padded)
+//
+// CHIP packets follow the header:
+//   $00-$03 "CHIP"
+//   $04-$07 total packet length (header + data)
+//   $08-$09 chip type (0=ROM, 1=RAM, 2=Flash, 3=EEPROM)
+//   $0A-$0B bank number
+//   $0C-$0D load address
+//   $0E-$0F image size
+
+const (
+	// Signature is the header signature of C64 cartridge images
+	Signature = "C64 CARTRIDGE   "
+
+	headerSize     = 0x40
+	chipHeaderSize = 0x10
+)
+
+// Signatures of cartridge images for other machines
+var foreignSignatures = map[string]string{
+	"C128 CARTRIDGE  ": "C128",
+	"C64TPCARTRIDGE  ": "TPC64",
+	"VIC20 CARTRIDGE ": "VIC-20",
+	"PLUS4 CARTRIDGE ": "Plus/4",
+	"CBM2 CARTRIDGE  ": "CBM-II",
+}
+
+// Chip types
+const (
+	ChipROM    = 0
+	ChipRAM    = 1
+	ChipFlash  = 2
+	ChipEEPROM = 3
+)
+
+// Cartridge represents a parsed CRT file
+type Cartridge struct {
+	HeaderLength uint32 `json:"header_length"`
+	Version      uint16 `json:"version"`
+	HardwareType uint16 `json:"hardware_type"`
+	HardwareName string `json:"hardware_name"`
+	Subtype      byte   `json:"subtype"`
+	EXROM        byte   `json:"exrom"`
+	GAME         byte   `json:"game"`
+	Name         string `json:"name"`
+	Chips        []Chip `json:"chips"`
+	Size         int    `json:"size"`
+
+	// Problems found while parsing; see Validate for the full check
+	parseIssues []Issue
+}
+
+// Chip represents a single CHIP packet
+type Chip struct {
+	Offset       int    `json:"offset"`
+	PacketLength uint32 `json:"packet_length"`
+	Type         uint16 `json:"type"`
+	TypeName     string `json:"type_name"`
+	Bank         uint16 `json:"bank"`
+	LoadAddress  uint16 `json:"load_address"`
+	Size         uint16 `json:"size"`
+	Data         []byte `json:"-"`
+}
+
+// Severity of a validation issue
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a single validation finding
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// ReadFile loads and parses a CRT file from disk
+func ReadFile(path string) (*Cartridge, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses a CRT image. Structural problems that still allow the
+// file to be read are recorded and reported by Validate.
+func Parse(data []byte) (*Cartridge, error) {
+	if len(data) < 0x20 {
+		return nil, fmt.Errorf("file too small for a CRT header (%d bytes)", len(data))
+	}
+
+	sig := string(data[:16])
+	if sig != Signature {
+		if machine, ok := foreignSignatures[sig]; ok {
+			return nil, fmt.Errorf("this is a %s cartridge image, not a C64 one", machine)
+		}
+		return nil, fmt.Errorf("not a CRT file (bad signature)")
+	}
+
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("truncated CRT header (%d bytes)", len(data))
+	}
+
+	c := &Cartridge{
+		HeaderLength: binary.BigEndian.Uint32(data[0x10:]),
+		Version:      binary.BigEndian.Uint16(data[0x14:]),
+		HardwareType: binary.BigEndian.Uint16(data[0x16:]),
+		EXROM:        data[0x18],
+		GAME:         data[0x19],
+		Subtype:      data[0x1A],
+		Name:         strings.TrimRight(string(bytes.TrimRight(data[0x20:0x40], "\x00")), " "),
+		Size:         len(data),
+	}
+	c.HardwareName = HardwareName(c.HardwareType)
+
+	// Some old tools write $20 instead of $40; the chips still start at $40
+	pos := int(c.HeaderLength)
+	if pos < headerSize {
+		c.addIssue(SeverityWarning, fmt.Sprintf("header length $%X is smaller than $40, assuming $40", c.HeaderLength))
+		pos = headerSize
+	}
+
+	for pos < len(data) {
+		if pos+chipHeaderSize > len(data) {
+			c.addIssue(SeverityWarning, fmt.Sprintf("%d trailing bytes after last CHIP packet", len(data)-pos))
+			break
+		}
+		if string(data[pos:pos+4]) != "CHIP" {
+			c.addIssue(SeverityError, fmt.Sprintf("missing CHIP signature at offset $%X", pos))
+			break
+		}
+
+		chip := Chip{
+			Offset:       pos,
+			PacketLength: binary.BigEndian.Uint32(data[pos+4:]),
+			Type:         binary.BigEndian.Uint16(data[pos+8:]),
+			Bank:         binary.BigEndian.Uint16(data[pos+10:]),
+			LoadAddress:  binary.BigEndian.Uint16(data[pos+12:]),
+			Size:         binary.BigEndian.Uint16(data[pos+14:]),
+		}
+		chip.TypeName = ChipTypeName(chip.Type)
+
+		dataStart := pos + chipHeaderSize
+		dataEnd := dataStart + int(chip.Size)
+		if dataEnd > len(data) {
+			c.addIssue(SeverityError, fmt.Sprintf("CHIP packet at $%X is truncated (%d of %d bytes)",
+				pos, len(data)-dataStart, chip.Size))
+			dataEnd = len(data)
+		}
+		chip.Data = data[dataStart:dataEnd]
+		c.Chips = append(c.Chips, chip)
+
+		next := pos + int(chip.PacketLength)
+		if chip.PacketLength < chipHeaderSize {
+			c.addIssue(SeverityError, fmt.Sprintf("CHIP packet at $%X has invalid length $%X", pos, chip.PacketLength))
+			next = dataEnd
+		}
+		pos = next
+	}
+
+	return c, nil
+}
+
+func (c *Cartridge) addIssue(severity Severity, message string) {
+	c.parseIssues = append(c.parseIssues, Issue{Severity: severity, Message: message})
+}
+
+// Validate checks the cartridge for consistency and returns all findings
+func (c *Cartridge) Validate() []Issue {
+	issues := append([]Issue(nil), c.parseIssues...)
+	add := func(severity Severity, format string, args ...interface{}) {
+		issues = append(issues, Issue{Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if major := c.Version >> 8; major != 1 && major != 2 {
+		add(SeverityWarning, "unusual CRT version %d.%02d", major, c.Version&0xFF)
+	}
+
+	if c.EXROM > 1 || c.GAME > 1 {
+		add(SeverityWarning, "EXROM/GAME values should be 0 or 1 (got %d/%d)", c.EXROM, c.GAME)
+	}
+
+	if len(c.Chips) == 0 {
+		add(SeverityError, "no CHIP packets found")
+		return issues
+	}
+
+	if !IsKnownHardware(c.HardwareType) {
+		add(SeverityWarning, "unknown hardware type %d", c.HardwareType)
+	} else if !IsSupported(c.HardwareType) {
+		add(SeverityWarning, "hardware type %d (%s) is not known to be supported by the Ultimate",
+			c.HardwareType, c.HardwareName)
+	}
+
+	type bankAddr struct{ bank, addr uint16 }
+	seen := make(map[bankAddr]bool)
+
+	for _, chip := range c.Chips {
+		if chip.Type > ChipEEPROM {
+			add(SeverityWarning, "CHIP at $%X has unknown chip type %d", chip.Offset, chip.Type)
+		}
+		if int(chip.PacketLength) != chipHeaderSize+int(chip.Size) && chip.PacketLength >= chipHeaderSize {
+			add(SeverityWarning, "CHIP at $%X: packet length $%X does not match image size $%X",
+				chip.Offset, chip.PacketLength, chip.Size)
+		}
+		if chip.Size == 0 {
+			add(SeverityWarning, "CHIP at $%X is empty", chip.Offset)
+		}
+
+		end := int(chip.LoadAddress) + int(chip.Size)
+		switch {
+		case chip.LoadAddress >= 0x8000 && end <= 0xC000:
+		case chip.LoadAddress >= 0xE000 && end <= 0x10000:
+		default:
+			add(SeverityWarning, "CHIP at $%X loads at $%04X-$%04X, outside the ROML/ROMH areas",
+				chip.Offset, chip.LoadAddress, end-1)
+		}
+
+		key := bankAddr{chip.Bank, chip.LoadAddress}
+		if seen[key] {
+			add(SeverityWarning, "duplicate CHIP for bank %d at $%04X", chip.Bank, chip.LoadAddress)
+		}
+		seen[key] = true
+	}
+
+	if c.HardwareType == 0 {
+		issues = append(issues, c.validateNormal()...)
+	}
+
+	return issues
+}
+
+// validateNormal checks that a plain cartridge's memory mode matches its chips
+func (c *Cartridge) validateNormal() []Issue {
+	var issues []Issue
+
+	total := 0
+	hasHigh := false
+	for _, chip := range c.Chips {
+		total += int(chip.Size)
+		if chip.LoadAddress >= 0xA000 {
+			hasHigh = true
+		}
+	}
+
+	mode := c.Mode()
+	switch {
+	case len(c.Chips) > 2:
+		issues = append(issues, Issue{SeverityWarning,
+			fmt.Sprintf("normal cartridge with %d CHIP packets (expected 1 or 2)", len(c.Chips))})
+	case mode == "8K" && (total > 0x2000 || hasHigh):
+		issues = append(issues, Issue{SeverityWarning,
+			"8K mode (EXROM=0, GAME=1) but ROM data extends beyond $9FFF"})
+	case mode == "16K" && total <= 0x2000 && !hasHigh:
+		issues = append(issues, Issue{SeverityWarning,
+			"16K mode (EXROM=0, GAME=0) but only 8K of ROM data"})
+	case mode == "off":
+		issues = append(issues, Issue{SeverityError,
+			"EXROM=1 and GAME=1 disable the cartridge ROM"})
+	}
+
+	return issues
+}
+
+// Mode returns the memory configuration selected by the EXROM/GAME lines
+func (c *Cartridge) Mode() string {
+	switch {
+	case c.EXROM == 0 && c.GAME == 1:
+		return "8K"
+	case c.EXROM == 0 && c.GAME == 0:
+		return "16K"
+	case c.EXROM == 1 && c.GAME == 0:
+		return "Ultimax"
+	default:
+		return "off"
+	}
+}
+
+// Banks returns the number of distinct banks
+func (c *Cartridge) Banks() int {
+	banks := make(map[uint16]bool)
+	for _, chip := range c.Chips {
+		banks[chip.Bank] = true
+	}
+	return len(banks)
+}
+
+// ChipTypeName returns the name of a CHIP packet type
+func ChipTypeName(t uint16) string {
+	switch t {
+	case ChipROM:
+		return "ROM"
+	case ChipRAM:
+		return "RAM"
+	case ChipFlash:
+		return "Flash"
+	case ChipEEPROM:
+		return "EEPROM"
+	default:
+		return fmt.Sprintf("unknown (%d)", t)
+	}
+}
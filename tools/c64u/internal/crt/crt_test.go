@@ -0,0 +1,196 @@
+package crt
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// testChip describes a CHIP packet for buildCRT
+type testChip struct {
+	bank, load, size uint16
+	packetLength     uint32 // 0 means header plus size
+}
+
+// buildCRT assembles a CRT image with the given hardware type, lines and chips
+func buildCRT(hwType uint16, exrom, game byte, chips []testChip) []byte {
+	img := make([]byte, headerSize)
+	copy(img, Signature)
+	binary.BigEndian.PutUint32(img[0x10:], headerSize)
+	binary.BigEndian.PutUint16(img[0x14:], 0x0100)
+	binary.BigEndian.PutUint16(img[0x16:], hwType)
+	img[0x18] = exrom
+	img[0x19] = game
+	copy(img[0x20:], "TEST CART")
+
+	for _, c := range chips {
+		packet := make([]byte, chipHeaderSize+int(c.size))
+		copy(packet, "CHIP")
+		length := c.packetLength
+		if length == 0 {
+			length = uint32(len(packet))
+		}
+		binary.BigEndian.PutUint32(packet[4:], length)
+		binary.BigEndian.PutUint16(packet[10:], c.bank)
+		binary.BigEndian.PutUint16(packet[12:], c.load)
+		binary.BigEndian.PutUint16(packet[14:], c.size)
+		img = append(img, packet...)
+	}
+	return img
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		errors []string // substrings of expected errors
+		warns  []string // substrings of expected warnings
+	}{
+		{
+			name: "8K normal cartridge",
+			data: buildCRT(0, 0, 1, []testChip{{load: 0x8000, size: 0x2000}}),
+		},
+		{
+			name: "16K normal cartridge",
+			data: buildCRT(0, 0, 0, []testChip{{load: 0x8000, size: 0x4000}}),
+		},
+		{
+			name:  "8K mode with 16K of data",
+			data:  buildCRT(0, 0, 1, []testChip{{load: 0x8000, size: 0x4000}}),
+			warns: []string{"8K mode"},
+		},
+		{
+			name:  "16K mode with 8K of data",
+			data:  buildCRT(0, 0, 0, []testChip{{load: 0x8000, size: 0x2000}}),
+			warns: []string{"16K mode"},
+		},
+		{
+			name:   "cartridge switched off",
+			data:   buildCRT(0, 1, 1, []testChip{{load: 0x8000, size: 0x2000}}),
+			errors: []string{"disable the cartridge ROM"},
+		},
+		{
+			name:   "no chips",
+			data:   buildCRT(0, 0, 1, nil),
+			errors: []string{"no CHIP packets"},
+		},
+		{
+			name: "banked Ocean cartridge",
+			data: buildCRT(5, 0, 0, []testChip{
+				{bank: 0, load: 0x8000, size: 0x2000},
+				{bank: 1, load: 0x8000, size: 0x2000},
+			}),
+		},
+		{
+			name: "duplicate bank",
+			data: buildCRT(5, 0, 0, []testChip{
+				{bank: 0, load: 0x8000, size: 0x2000},
+				{bank: 0, load: 0x8000, size: 0x2000},
+			}),
+			warns: []string{"duplicate CHIP for bank 0"},
+		},
+		{
+			name:  "load address outside ROML/ROMH",
+			data:  buildCRT(0, 0, 1, []testChip{{load: 0x4000, size: 0x2000}}),
+			warns: []string{"outside the ROML/ROMH areas"},
+		},
+		{
+			name:  "packet length mismatch",
+			data:  buildCRT(0, 0, 1, []testChip{{load: 0x8000, size: 0x2000, packetLength: chipHeaderSize + 0x2000 + 2}}),
+			warns: []string{"does not match image size"},
+		},
+		{
+			name:   "truncated chip",
+			data:   buildCRT(0, 0, 1, []testChip{{load: 0x8000, size: 0x2000}})[:headerSize+chipHeaderSize+0x100],
+			errors: []string{"truncated"},
+		},
+		{
+			name:   "invalid packet length",
+			data:   buildCRT(0, 0, 1, []testChip{{load: 0x8000, size: 0x2000, packetLength: 4}}),
+			errors: []string{"invalid length"},
+		},
+		{
+			name:  "unknown hardware type",
+			data:  buildCRT(999, 0, 1, []testChip{{load: 0x8000, size: 0x2000}}),
+			warns: []string{"unknown hardware type 999"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse(tt.data)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			var errors, warns []string
+			for _, issue := range c.Validate() {
+				if issue.Severity == SeverityError {
+					errors = append(errors, issue.Message)
+				} else {
+					warns = append(warns, issue.Message)
+				}
+			}
+			checkIssues(t, "errors", errors, tt.errors)
+			checkIssues(t, "warnings", warns, tt.warns)
+		})
+	}
+}
+
+// checkIssues compares messages with the expected substrings, in order
+func checkIssues(t *testing.T, kind string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %q, want %d matching %q", kind, got, len(want), want)
+		return
+	}
+	for i := range want {
+		if !strings.Contains(got[i], want[i]) {
+			t.Errorf("%s[%d] = %q, want it to contain %q", kind, i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	c128 := make([]byte, headerSize)
+	copy(c128, "C128 CARTRIDGE  ")
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"too small", []byte("C64 CARTRIDGE"), "too small"},
+		{"bad signature", make([]byte, headerSize), "bad signature"},
+		{"C128 image", c128, "C128 cartridge"},
+		{"truncated header", []byte(Signature + strings.Repeat("\x00", 20)), "truncated CRT header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMode(t *testing.T) {
+	tests := []struct {
+		exrom, game byte
+		want        string
+	}{
+		{0, 1, "8K"},
+		{0, 0, "16K"},
+		{1, 0, "Ultimax"},
+		{1, 1, "off"},
+	}
+
+	for _, tt := range tests {
+		c := &Cartridge{EXROM: tt.exrom, GAME: tt.game}
+		if got := c.Mode(); got != tt.want {
+			t.Errorf("EXROM=%d GAME=%d: Mode = %q, want %q", tt.exrom, tt.game, got, tt.want)
+		}
+	}
+}
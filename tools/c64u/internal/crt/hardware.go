@@ -0,0 +1,141 @@
+package crt
+
+import "fmt"
+
+// Hardware type names as assigned in the CRT specification
+var hardwareNames = map[uint16]string{
+	0:  "Normal cartridge",
+	1:  "Action Replay",
+	2:  "KCS Power Cartridge",
+	3:  "Final Cartridge III",
+	4:  "Simons' BASIC",
+	5:  "Ocean type 1",
+	6:  "Expert Cartridge",
+	7:  "Fun Play, Power Play",
+	8:  "Super Games",
+	9:  "Atomic Power",
+	10: "Epyx Fastload",
+	11: "Westermann Learning",
+	12: "Rex Utility",
+	13: "Final Cartridge I",
+	14: "Magic Formel",
+	15: "C64 Game System, System 3",
+	16: "WarpSpeed",
+	17: "Dinamic",
+	18: "Zaxxon, Super Zaxxon (SEGA)",
+	19: "Magic Desk, Domark, HES Australia",
+	20: "Super Snapshot V5",
+	21: "Comal-80",
+	22: "Structured BASIC",
+	23: "Ross",
+	24: "Dela EP64",
+	25: "Dela EP7x8",
+	26: "Dela EP256",
+	27: "Rex EP256",
+	28: "Mikro Assembler",
+	29: "Final Cartridge Plus",
+	30: "Action Replay 4",
+	31: "Stardos",
+	32: "EasyFlash",
+	33: "EasyFlash Xbank",
+	34: "Capture",
+	35: "Action Replay 3",
+	36: "Retro Replay",
+	37: "MMC64",
+	38: "MMC Replay",
+	39: "IDE64",
+	40: "Super Snapshot V4",
+	41: "IEEE-488",
+	42: "Game Killer",
+	43: "Prophet64",
+	44: "EXOS",
+	45: "Freeze Frame",
+	46: "Freeze Machine",
+	47: "Snapshot64",
+	48: "Super Explode V5.0",
+	49: "Magic Voice",
+	50: "Action Replay 2",
+	51: "MACH 5",
+	52: "Diashow-Maker",
+	53: "Pagefox",
+	54: "Kingsoft",
+	55: "Silverrock 128K Cartridge",
+	56: "Formel 64",
+	57: "RGCD",
+	58: "RR-Net MK3",
+	59: "EasyCalc",
+	60: "GMod2",
+	61: "MAX Basic",
+	62: "GMod3",
+	63: "ZIPP-CODE 48",
+	64: "Blackbox V8",
+	65: "Blackbox V3",
+	66: "Blackbox V4",
+	67: "REX RAM-Floppy",
+	68: "BIS-Plus",
+	69: "SD-BOX",
+	70: "MultiMAX",
+	71: "Blackbox V9",
+	72: "Lt. Kernal Host Adaptor",
+	73: "RAMLink",
+	74: "H.E.R.O.",
+	75: "IEEE Flash! 64",
+	76: "Turtle Graphics II",
+	77: "Freeze Frame MK2",
+	78: "Partner 64",
+	79: "Hyper-BASIC MK2",
+	80: "Universal Cartridge 1",
+	81: "Universal Cartridge 1.5",
+	82: "Universal Cartridge 2",
+	83: "BMP Data Turbo 2000",
+	84: "Profi-DOS",
+	85: "Magic Desk 16",
+}
+
+// Hardware types the Ultimate cartridge emulation is known to handle
+var supportedHardware = map[uint16]bool{
+	0:  true, // Normal 8K/16K/Ultimax
+	1:  true, // Action Replay
+	2:  true, // KCS Power Cartridge
+	3:  true, // Final Cartridge III
+	4:  true, // Simons' BASIC
+	5:  true, // Ocean type 1
+	7:  true, // Fun Play, Power Play
+	8:  true, // Super Games
+	10: true, // Epyx Fastload
+	11: true, // Westermann Learning
+	13: true, // Final Cartridge I
+	15: true, // System 3
+	16: true, // WarpSpeed
+	17: true, // Dinamic
+	18: true, // Zaxxon
+	19: true, // Magic Desk
+	20: true, // Super Snapshot V5
+	21: true, // Comal-80
+	32: true, // EasyFlash
+	35: true, // Action Replay 3
+	36: true, // Retro Replay
+	51: true, // MACH 5
+	53: true, // Pagefox
+	57: true, // RGCD
+	60: true, // GMod2
+}
+
+// HardwareName returns the name of a cartridge hardware type
+func HardwareName(t uint16) string {
+	if name, ok := hardwareNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown (%d)", t)
+}
+
+// IsKnownHardware reports whether the hardware type is defined in the CRT specification
+func IsKnownHardware(t uint16) bool {
+	_, ok := hardwareNames[t]
+	return ok
+}
+
+// IsSupported reports whether the hardware type is known to run on the Ultimate
+func IsSupported(t uint16) bool {
+	return supportedHardware[t]
+}
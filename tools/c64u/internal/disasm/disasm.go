@@ -0,0 +1,151 @@
+package disasm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Namer resolves addresses to project labels. *symbols.Table implements it.
+type Namer interface {
+	Describe(addr int) string
+	At(addr int) []string
+}
+
+// Options control disassembly
+type Options struct {
+	Illegal bool  // decode undocumented opcodes instead of showing .byte
+	Labels  Namer // optional project labels
+}
+
+// Line is one disassembled instruction
+type Line struct {
+	Address  int      `json:"address"`
+	Bytes    []byte   `json:"-"`
+	Hex      string   `json:"bytes"`
+	Mnemonic string   `json:"mnemonic"`
+	Operand  string   `json:"operand,omitempty"`
+	Mode     string   `json:"mode"`
+	Illegal  bool     `json:"illegal,omitempty"`
+	Target   *int     `json:"target,omitempty"` // address an operand refers to
+	Labels   []string `json:"labels,omitempty"` // labels defined at Address
+	Comment  string   `json:"comment,omitempty"`
+}
+
+// Disassemble decodes instructions starting at start until length bytes
+// are covered. data may extend past length so that the last instruction
+// is complete; instructions cut off by the end of data become .byte.
+func Disassemble(data []byte, start, length int, opts Options) []Line {
+	var lines []Line
+	for pc := 0; pc < length && pc < len(data); {
+		line := decode(data[pc:], start+pc, opts)
+		lines = append(lines, line)
+		pc += len(line.Bytes)
+	}
+	return lines
+}
+
+// decode disassembles the instruction at the start of data
+func decode(data []byte, addr int, opts Options) Line {
+	op := Opcodes[data[0]]
+	size := op.Size()
+
+	if (op.Illegal && !opts.Illegal) || size > len(data) {
+		return dataLine(data[0], addr, opts)
+	}
+
+	line := Line{
+		Address:  addr,
+		Bytes:    data[:size],
+		Hex:      fmt.Sprintf("% X", data[:size]),
+		Mnemonic: op.Mnemonic,
+		Mode:     op.Mode,
+		Illegal:  op.Illegal,
+	}
+	if opts.Labels != nil {
+		line.Labels = opts.Labels.At(addr)
+	}
+
+	var value int
+	switch size {
+	case 2:
+		value = int(data[1])
+	case 3:
+		value = int(data[1]) | int(data[2])<<8
+	}
+
+	target := value
+	switch op.Mode {
+	case ModeImplied:
+	case ModeAccumulator:
+		line.Operand = "a"
+	case ModeImmediate:
+		line.Operand = fmt.Sprintf("#$%02X", value)
+	case ModeZeroPage:
+		line.Operand = fmt.Sprintf("$%02X", value)
+	case ModeZeroPageX:
+		line.Operand = fmt.Sprintf("$%02X,x", value)
+	case ModeZeroPageY:
+		line.Operand = fmt.Sprintf("$%02X,y", value)
+	case ModeAbsolute:
+		line.Operand = fmt.Sprintf("$%04X", value)
+	case ModeAbsoluteX:
+		line.Operand = fmt.Sprintf("$%04X,x", value)
+	case ModeAbsoluteY:
+		line.Operand = fmt.Sprintf("$%04X,y", value)
+	case ModeIndirect:
+		line.Operand = fmt.Sprintf("($%04X)", value)
+	case ModeIndirectX:
+		line.Operand = fmt.Sprintf("($%02X,x)", value)
+	case ModeIndirectY:
+		line.Operand = fmt.Sprintf("($%02X),y", value)
+	case ModeRelative:
+		target = (addr + 2 + int(int8(value))) & 0xFFFF
+		line.Operand = fmt.Sprintf("$%04X", target)
+	}
+
+	if op.Mode != ModeImplied && op.Mode != ModeAccumulator && op.Mode != ModeImmediate {
+		line.Target = &target
+		line.Comment = describe(target, opts)
+	}
+	return line
+}
+
+// dataLine shows a byte that is not decoded as an instruction
+func dataLine(b byte, addr int, opts Options) Line {
+	line := Line{
+		Address:  addr,
+		Bytes:    []byte{b},
+		Hex:      fmt.Sprintf("%02X", b),
+		Mnemonic: ".byte",
+		Operand:  fmt.Sprintf("$%02X", b),
+		Mode:     "data",
+	}
+	if opts.Labels != nil {
+		line.Labels = opts.Labels.At(addr)
+	}
+	return line
+}
+
+// describe names a target address: project labels first, then the
+// well-known ROM and I/O addresses
+func describe(addr int, opts Options) string {
+	if opts.Labels != nil {
+		if name := opts.Labels.Describe(addr); name != "" {
+			return name
+		}
+	}
+	return Known[addr]
+}
+
+// Format renders a line as text: address, bytes, instruction and comment
+func (l Line) Format() string {
+	instr := l.Mnemonic
+	if l.Operand != "" {
+		instr += " " + l.Operand
+	}
+	text := fmt.Sprintf("%04X  %-9s  %s", l.Address, l.Hex, instr)
+	if l.Comment != "" {
+		text = fmt.Sprintf("%-36s // %s", text, l.Comment)
+	}
+	return strings.TrimRight(text, " ")
+}
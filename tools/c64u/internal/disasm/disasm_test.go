@@ -0,0 +1,155 @@
+package disasm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// labels is a minimal Namer with exact-address labels only
+type labels map[int]string
+
+func (l labels) Describe(addr int) string { return l[addr] }
+
+func (l labels) At(addr int) []string {
+	if name, ok := l[addr]; ok {
+		return []string{name}
+	}
+	return nil
+}
+
+func TestOpcodeTable(t *testing.T) {
+	documented := 0
+	for _, op := range Opcodes {
+		if op.Mnemonic == "" || op.Mode == "" {
+			t.Fatalf("incomplete opcode %+v", op)
+		}
+		if !op.Illegal {
+			documented++
+		}
+	}
+	if documented != 151 {
+		t.Errorf("%d documented opcodes, want 151", documented)
+	}
+
+	tests := []struct {
+		op       byte
+		mnemonic string
+		mode     string
+		size     int
+	}{
+		{0x00, "brk", ModeImplied, 1},
+		{0x0A, "asl", ModeAccumulator, 1},
+		{0x6C, "jmp", ModeIndirect, 3},
+		{0x96, "stx", ModeZeroPageY, 2},
+		{0xB1, "lda", ModeIndirectY, 2},
+		{0xA7, "lax", ModeZeroPage, 2},
+		{0xEB, "sbc2", ModeImmediate, 2},
+	}
+	for _, tt := range tests {
+		op := Opcodes[tt.op]
+		if op.Mnemonic != tt.mnemonic || op.Mode != tt.mode || op.Size() != tt.size {
+			t.Errorf("$%02X = %+v size %d, want %s %s size %d", tt.op, op, op.Size(), tt.mnemonic, tt.mode, tt.size)
+		}
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		start int
+		opts  Options
+		want  []string
+	}{
+		{
+			name:  "documented",
+			data:  []byte{0xA9, 0x00, 0x8D, 0x20, 0xD0, 0x20, 0xD2, 0xFF, 0x60},
+			start: 0xC000,
+			want: []string{
+				"C000  A9 00      lda #$00",
+				"C002  8D 20 D0   sta $D020           // border colour",
+				"C005  20 D2 FF   jsr $FFD2           // CHROUT (KERNAL)",
+				"C008  60         rts",
+			},
+		},
+		{
+			name:  "branches",
+			data:  []byte{0xD0, 0xFE, 0x10, 0x80, 0xF0, 0x7F},
+			start: 0x1000,
+			want: []string{
+				"1000  D0 FE      bne $1000",
+				"1002  10 80      bpl $0F84",
+				"1004  F0 7F      beq $1085",
+			},
+		},
+		{
+			name:  "illegal as data",
+			data:  []byte{0xA7, 0x10, 0xEA},
+			start: 0x2000,
+			want: []string{
+				"2000  A7         .byte $A7",
+				"2001  10 EA      bpl $1FED",
+			},
+		},
+		{
+			name:  "illegal decoded",
+			data:  []byte{0xA7, 0x10, 0xEA},
+			start: 0x2000,
+			opts:  Options{Illegal: true},
+			want: []string{
+				"2000  A7 10      lax $10",
+				"2002  EA         nop",
+			},
+		},
+		{
+			name:  "cut off instruction",
+			data:  []byte{0xEA, 0x4C, 0x00},
+			start: 0x3000,
+			want: []string{
+				"3000  EA         nop",
+				"3001  4C         .byte $4C",
+				"3002  00         brk",
+			},
+		},
+		{
+			name:  "labels",
+			data:  []byte{0x4C, 0x00, 0x40, 0x6C, 0xFC, 0xFF},
+			start: 0x4000,
+			opts:  Options{Labels: labels{0x4000: "loop"}},
+			want: []string{
+				"4000  4C 00 40   jmp $4000           // loop",
+				"4003  6C FC FF   jmp ($FFFC)         // CPU reset vector",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, l := range Disassemble(tt.data, tt.start, len(tt.data), tt.opts) {
+				got = append(got, l.Format())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+// TestDisassembleLength checks that the last instruction may extend past
+// length and that labels at an address are reported
+func TestDisassembleLength(t *testing.T) {
+	data := []byte{0xEA, 0xAD, 0x00, 0xC0}
+	lines := Disassemble(data, 0x0800, 2, Options{Labels: labels{0x0801: "read"}})
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	l := lines[1]
+	if l.Hex != "AD 00 C0" || l.Target == nil || *l.Target != 0xC000 {
+		t.Errorf("line = %+v", l)
+	}
+	if fmt.Sprint(l.Labels) != "[read]" {
+		t.Errorf("labels = %v, want [read]", l.Labels)
+	}
+}
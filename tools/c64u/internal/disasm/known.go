@@ -0,0 +1,89 @@
+package disasm
+
+// Known names well-known ROM entry points, vectors and I/O registers.
+// Values are short descriptions used as comments.
+var Known = map[int]string{
+	// KERNAL jump table
+	0xFF81: "CINT (KERNAL)",
+	0xFF84: "IOINIT (KERNAL)",
+	0xFF87: "RAMTAS (KERNAL)",
+	0xFF8A: "RESTOR (KERNAL)",
+	0xFF8D: "VECTOR (KERNAL)",
+	0xFF90: "SETMSG (KERNAL)",
+	0xFF93: "SECOND (KERNAL)",
+	0xFF96: "TKSA (KERNAL)",
+	0xFF99: "MEMTOP (KERNAL)",
+	0xFF9C: "MEMBOT (KERNAL)",
+	0xFF9F: "SCNKEY (KERNAL)",
+	0xFFA2: "SETTMO (KERNAL)",
+	0xFFA5: "ACPTR (KERNAL)",
+	0xFFA8: "CIOUT (KERNAL)",
+	0xFFAB: "UNTLK (KERNAL)",
+	0xFFAE: "UNLSN (KERNAL)",
+	0xFFB1: "LISTEN (KERNAL)",
+	0xFFB4: "TALK (KERNAL)",
+	0xFFB7: "READST (KERNAL)",
+	0xFFBA: "SETLFS (KERNAL)",
+	0xFFBD: "SETNAM (KERNAL)",
+	0xFFC0: "OPEN (KERNAL)",
+	0xFFC3: "CLOSE (KERNAL)",
+	0xFFC6: "CHKIN (KERNAL)",
+	0xFFC9: "CHKOUT (KERNAL)",
+	0xFFCC: "CLRCHN (KERNAL)",
+	0xFFCF: "CHRIN (KERNAL)",
+	0xFFD2: "CHROUT (KERNAL)",
+	0xFFD5: "LOAD (KERNAL)",
+	0xFFD8: "SAVE (KERNAL)",
+	0xFFDB: "SETTIM (KERNAL)",
+	0xFFDE: "RDTIM (KERNAL)",
+	0xFFE1: "STOP (KERNAL)",
+	0xFFE4: "GETIN (KERNAL)",
+	0xFFE7: "CLALL (KERNAL)",
+	0xFFEA: "UDTIM (KERNAL)",
+	0xFFED: "SCREEN (KERNAL)",
+	0xFFF0: "PLOT (KERNAL)",
+	0xFFF3: "IOBASE (KERNAL)",
+
+	// KERNAL internals commonly called directly
+	0xE544: "clear screen (KERNAL)",
+	0xE566: "cursor home (KERNAL)",
+	0xE716: "print char to screen (KERNAL)",
+	0xEA31: "default IRQ handler (KERNAL)",
+	0xEA81: "IRQ return (KERNAL)",
+	0xFCE2: "reset (KERNAL)",
+	0xFE47: "NMI handler (KERNAL)",
+
+	// BASIC
+	0xA474: "READY (BASIC)",
+	0xA533: "relink program lines (BASIC)",
+	0xA871: "RUN (BASIC)",
+	0xAAD7: "print CR (BASIC)",
+	0xAB1E: "print string A/Y (BASIC)",
+	0xBDCD: "print number X/A (BASIC)",
+
+	// Vectors
+	0x0314: "IRQ vector",
+	0x0316: "BRK vector",
+	0x0318: "NMI vector",
+	0xFFFA: "CPU NMI vector",
+	0xFFFC: "CPU reset vector",
+	0xFFFE: "CPU IRQ vector",
+
+	// I/O
+	0x0001: "CPU port",
+	0xD011: "VIC control 1",
+	0xD012: "VIC raster",
+	0xD015: "VIC sprite enable",
+	0xD016: "VIC control 2",
+	0xD018: "VIC memory setup",
+	0xD019: "VIC IRQ status",
+	0xD01A: "VIC IRQ enable",
+	0xD020: "border colour",
+	0xD021: "background colour",
+	0xD418: "SID volume/filter",
+	0xDC00: "CIA 1 port A",
+	0xDC01: "CIA 1 port B",
+	0xDC0D: "CIA 1 interrupt control",
+	0xDD00: "CIA 2 port A",
+	0xDD0D: "CIA 2 interrupt control",
+}
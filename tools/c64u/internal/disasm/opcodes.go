@@ -0,0 +1,77 @@
+package disasm
+
+import "strings"
+
+// Addressing modes
+const (
+	ModeImplied     = "imp"
+	ModeAccumulator = "acc"
+	ModeImmediate   = "imm"
+	ModeZeroPage    = "zp"
+	ModeZeroPageX   = "zpx"
+	ModeZeroPageY   = "zpy"
+	ModeAbsolute    = "abs"
+	ModeAbsoluteX   = "abx"
+	ModeAbsoluteY   = "aby"
+	ModeIndirect    = "ind"
+	ModeIndirectX   = "izx"
+	ModeIndirectY   = "izy"
+	ModeRelative    = "rel"
+)
+
+// Opcode describes one of the 256 6510 opcodes
+type Opcode struct {
+	Mnemonic string
+	Mode     string
+	Illegal  bool // undocumented NMOS opcode
+}
+
+// Size returns the instruction length in bytes
+func (o Opcode) Size() int {
+	switch o.Mode {
+	case ModeImplied, ModeAccumulator:
+		return 1
+	case ModeAbsolute, ModeAbsoluteX, ModeAbsoluteY, ModeIndirect:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// opcodeMatrix lists opcodes $00-$FF, one row per high nibble. Entries
+// marked with * are undocumented; the names follow KickAssembler.
+var opcodeMatrix = [16]string{
+	"brk imp,ora izx,*jam imp,*slo izx,*nop zp,ora zp,asl zp,*slo zp,php imp,ora imm,asl acc,*anc imm,*nop abs,ora abs,asl abs,*slo abs",
+	"bpl rel,ora izy,*jam imp,*slo izy,*nop zpx,ora zpx,asl zpx,*slo zpx,clc imp,ora aby,*nop imp,*slo aby,*nop abx,ora abx,asl abx,*slo abx",
+	"jsr abs,and izx,*jam imp,*rla izx,bit zp,and zp,rol zp,*rla zp,plp imp,and imm,rol acc,*anc2 imm,bit abs,and abs,rol abs,*rla abs",
+	"bmi rel,and izy,*jam imp,*rla izy,*nop zpx,and zpx,rol zpx,*rla zpx,sec imp,and aby,*nop imp,*rla aby,*nop abx,and abx,rol abx,*rla abx",
+	"rti imp,eor izx,*jam imp,*sre izx,*nop zp,eor zp,lsr zp,*sre zp,pha imp,eor imm,lsr acc,*alr imm,jmp abs,eor abs,lsr abs,*sre abs",
+	"bvc rel,eor izy,*jam imp,*sre izy,*nop zpx,eor zpx,lsr zpx,*sre zpx,cli imp,eor aby,*nop imp,*sre aby,*nop abx,eor abx,lsr abx,*sre abx",
+	"rts imp,adc izx,*jam imp,*rra izx,*nop zp,adc zp,ror zp,*rra zp,pla imp,adc imm,ror acc,*arr imm,jmp ind,adc abs,ror abs,*rra abs",
+	"bvs rel,adc izy,*jam imp,*rra izy,*nop zpx,adc zpx,ror zpx,*rra zpx,sei imp,adc aby,*nop imp,*rra aby,*nop abx,adc abx,ror abx,*rra abx",
+	"*nop imm,sta izx,*nop imm,*sax izx,sty zp,sta zp,stx zp,*sax zp,dey imp,*nop imm,txa imp,*xaa imm,sty abs,sta abs,stx abs,*sax abs",
+	"bcc rel,sta izy,*jam imp,*ahx izy,sty zpx,sta zpx,stx zpy,*sax zpy,tya imp,sta aby,txs imp,*tas aby,*shy abx,sta abx,*shx aby,*ahx aby",
+	"ldy imm,lda izx,ldx imm,*lax izx,ldy zp,lda zp,ldx zp,*lax zp,tay imp,lda imm,tax imp,*lax imm,ldy abs,lda abs,ldx abs,*lax abs",
+	"bcs rel,lda izy,*jam imp,*lax izy,ldy zpx,lda zpx,ldx zpy,*lax zpy,clv imp,lda aby,tsx imp,*las aby,ldy abx,lda abx,ldx aby,*lax aby",
+	"cpy imm,cmp izx,*nop imm,*dcp izx,cpy zp,cmp zp,dec zp,*dcp zp,iny imp,cmp imm,dex imp,*axs imm,cpy abs,cmp abs,dec abs,*dcp abs",
+	"bne rel,cmp izy,*jam imp,*dcp izy,*nop zpx,cmp zpx,dec zpx,*dcp zpx,cld imp,cmp aby,*nop imp,*dcp aby,*nop abx,cmp abx,dec abx,*dcp abx",
+	"cpx imm,sbc izx,*nop imm,*isc izx,cpx zp,sbc zp,inc zp,*isc zp,inx imp,sbc imm,nop imp,*sbc2 imm,cpx abs,sbc abs,inc abs,*isc abs",
+	"beq rel,sbc izy,*jam imp,*isc izy,*nop zpx,sbc zpx,inc zpx,*isc zpx,sed imp,sbc aby,*nop imp,*isc aby,*nop abx,sbc abx,inc abx,*isc abx",
+}
+
+// Opcodes is the decoded opcode table, indexed by opcode byte
+var Opcodes [256]Opcode
+
+func init() {
+	for hi, row := range opcodeMatrix {
+		for lo, entry := range strings.Split(row, ",") {
+			mnemonic, mode, _ := strings.Cut(entry, " ")
+			illegal := strings.HasPrefix(mnemonic, "*")
+			Opcodes[hi<<4|lo] = Opcode{
+				Mnemonic: strings.TrimPrefix(mnemonic, "*"),
+				Mode:     mode,
+				Illegal:  illegal,
+			}
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package diskimage
+
+import "fmt"
+
+// bamEntry locates the BAM data of a track: the sector holding the free
+// counter, the counter offset (-1 if none), and the sector and offset of
+// the bitmap. Bits are stored LSB first unless msbFirst is set; a set bit
+// means free.
+type bamEntry struct {
+	count      []byte
+	countOff   int
+	bitmap     []byte
+	bitmapOff  int
+	bitmapSize int
+	msbFirst   bool
+}
+
+// mask returns the bitmap byte offset and bit mask of a sector
+func (e bamEntry) mask(sector int) (int, byte) {
+	bit := uint(sector % 8)
+	if e.msbFirst {
+		bit = 7 - bit
+	}
+	return e.bitmapOff + sector/8, 1 << bit
+}
+
+// bamFor returns the BAM location for a track
+func (img *Image) bamFor(track int) bamEntry {
+	f := img.Format
+
+	switch f.Name {
+	case "dnp":
+		// 32 bytes per track, 8 tracks per block starting at 1/2; the
+		// slot of track 0 holds the BAM header. There are no counters.
+		bam := img.mustSector(1, 2+track/8)
+		return bamEntry{nil, -1, bam, 32 * (track % 8), 32, true}
+
+	case "d81":
+		bam := img.mustSector(40, 1+(track-1)/40)
+		off := 0x10 + 6*((track-1)%40)
+		return bamEntry{bam, off, bam, off + 1, 5, false}
+
+	case "d71":
+		if track > 35 {
+			return bamEntry{
+				img.mustSector(18, 0), 0xDD + (track - 36),
+				img.mustSector(53, 0), 3 * (track - 36), 3, false,
+			}
+		}
+
+	default:
+		if track > 35 {
+			// 40-track images use the SpeedDOS BAM extension
+			bam := img.mustSector(18, 0)
+			off := 0xC0 + 4*(track-36)
+			return bamEntry{bam, off, bam, off + 1, 3, false}
+		}
+	}
+
+	bam := img.mustSector(18, 0)
+	off := 4 * track
+	return bamEntry{bam, off, bam, off + 1, 3, false}
+}
+
+// IsFree reports whether a sector is marked free in the BAM
+func (img *Image) IsFree(track, sector int) bool {
+	if sector < 0 || sector >= img.Format.SectorsPerTrack(track) {
+		return false
+	}
+	e := img.bamFor(track)
+	off, mask := e.mask(sector)
+	return e.bitmap[off]&mask != 0
+}
+
+// setFree marks a sector as free or allocated and keeps the free counter in sync
+func (img *Image) setFree(track, sector int, free bool) {
+	e := img.bamFor(track)
+	off, mask := e.mask(sector)
+	b := &e.bitmap[off]
+
+	wasFree := *b&mask != 0
+	if wasFree == free {
+		return
+	}
+
+	if free {
+		*b |= mask
+	} else {
+		*b &^= mask
+	}
+
+	if e.countOff >= 0 {
+		if free {
+			e.count[e.countOff]++
+		} else {
+			e.count[e.countOff]--
+		}
+	}
+}
+
+// Allocate marks a sector as used
+func (img *Image) Allocate(track, sector int) error {
+	if _, err := img.Offset(track, sector); err != nil {
+		return err
+	}
+	if !img.IsFree(track, sector) {
+		return fmt.Errorf("sector %d/%d is already allocated", track, sector)
+	}
+	img.setFree(track, sector, false)
+	return nil
+}
+
+// Free marks a sector as unused
+func (img *Image) Free(track, sector int) error {
+	if _, err := img.Offset(track, sector); err != nil {
+		return err
+	}
+	img.setFree(track, sector, true)
+	return nil
+}
+
+// FreeOnTrack counts the free sectors of a track from the bitmap
+func (img *Image) FreeOnTrack(track int) int {
+	free := 0
+	for s := 0; s < img.Format.SectorsPerTrack(track); s++ {
+		if img.IsFree(track, s) {
+			free++
+		}
+	}
+	return free
+}
+
+// FreeBlocks returns the number of free blocks as shown by the DOS,
+// which excludes the directory track
+func (img *Image) FreeBlocks() int {
+	free := 0
+	for t := 1; t <= img.Format.Tracks; t++ {
+		if img.Format.IsSystemTrack(t) {
+			continue
+		}
+		free += img.FreeOnTrack(t)
+	}
+	return free
+}
+
+// allocationOrder returns the order in which tracks are tried for file
+// data: outwards from the directory track, like the CBM DOS does
+func (img *Image) allocationOrder() []int {
+	f := img.Format
+	var order []int
+
+	// Native partitions fill the disk from the start
+	if f.Name == "dnp" {
+		for t := 1; t <= f.Tracks; t++ {
+			order = append(order, t)
+		}
+		return order
+	}
+
+	for dist := 1; dist <= f.Tracks; dist++ {
+		for _, t := range []int{f.DirTrack - dist, f.DirTrack + dist} {
+			if t >= 1 && t <= f.Tracks && !f.IsSystemTrack(t) {
+				order = append(order, t)
+			}
+		}
+	}
+	return order
+}
+
+// allocateChain allocates n sectors for file data using the format's
+// interleave and returns them in chain order
+func (img *Image) allocateChain(n int) ([][2]int, error) {
+	if n > img.FreeBlocks() {
+		return nil, fmt.Errorf("disk full: need %d blocks, %d free", n, img.FreeBlocks())
+	}
+
+	var chain [][2]int
+	order := img.allocationOrder()
+	ti := 0
+	sector := 0
+
+	for len(chain) < n {
+		for ti < len(order) && img.FreeOnTrack(order[ti]) == 0 {
+			ti++
+			sector = 0
+		}
+		if ti >= len(order) {
+			return nil, fmt.Errorf("disk full")
+		}
+
+		track := order[ti]
+		spt := img.Format.SectorsPerTrack(track)
+		s := sector % spt
+		for !img.IsFree(track, s) {
+			s = (s + 1) % spt
+		}
+
+		img.setFree(track, s, false)
+		chain = append(chain, [2]int{track, s})
+		sector = s + img.Format.Interleave
+	}
+
+	return chain, nil
+}
@@ -0,0 +1,180 @@
+package diskimage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Block roles reported by Inspect
+const (
+	BlockHeader    = "header"
+	BlockBAM       = "bam"
+	BlockDirectory = "directory"
+	BlockFile      = "file"
+	BlockFree      = "free"
+	BlockUnknown   = "unknown"
+)
+
+// BlockInfo describes what a single block holds
+type BlockInfo struct {
+	Track      int          `json:"track"`
+	Sector     int          `json:"sector"`
+	Offset     int          `json:"offset"`
+	Roles      []string     `json:"roles"`
+	Free       bool         `json:"free"`
+	NextTrack  int          `json:"next_track"`
+	NextSector int          `json:"next_sector"`
+	BytesUsed  int          `json:"bytes_used,omitempty"` // last block of a chain only
+	Owner      string       `json:"owner,omitempty"`      // file whose chain holds the block
+	ChainIndex int          `json:"chain_index,omitempty"`
+	DiskName   string       `json:"disk_name,omitempty"`
+	DiskID     string       `json:"disk_id,omitempty"`
+	DOSType    string       `json:"dos_type,omitempty"`
+	Entries    []DirEntry   `json:"entries,omitempty"`
+	BAM        []TrackUsage `json:"bam,omitempty"`
+}
+
+// TrackUsage is the decoded BAM information for one track
+type TrackUsage struct {
+	Track     int    `json:"track"`
+	FreeCount int    `json:"free_count"` // as stored in the BAM, if it has counters
+	Bitmap    string `json:"bitmap"`     // one character per sector: '.' free, '#' used
+}
+
+// IsLast reports whether the block ends a chain
+func (b *BlockInfo) IsLast() bool {
+	return b.NextTrack == 0
+}
+
+// Inspect describes a block: its link bytes, BAM state and, where the
+// block is part of the disk structure, the decoded header, directory
+// entries or BAM bitmaps it contains
+func (img *Image) Inspect(track, sector int) (*BlockInfo, error) {
+	data, err := img.Sector(track, sector)
+	if err != nil {
+		return nil, err
+	}
+	offset, _ := img.Offset(track, sector)
+
+	info := &BlockInfo{
+		Track:      track,
+		Sector:     sector,
+		Offset:     offset,
+		Free:       img.IsFree(track, sector),
+		NextTrack:  int(data[0]),
+		NextSector: int(data[1]),
+	}
+	if info.IsLast() {
+		info.BytesUsed = int(data[1]) - 1
+		if info.BytesUsed < 0 {
+			info.BytesUsed = 0
+		}
+	}
+
+	f := img.Format
+	if track == f.DirTrack && sector == f.HeaderSector {
+		info.Roles = append(info.Roles, BlockHeader)
+		info.DiskName = DisplayName(img.DiskName())
+		info.DiskID = DisplayName(img.DiskID())
+		info.DOSType = DisplayName(img.DOSType())
+	}
+
+	info.BAM = img.bamUsage(data)
+	if len(info.BAM) > 0 {
+		info.Roles = append(info.Roles, BlockBAM)
+	}
+
+	dirChain, _ := img.dirSectors()
+	for _, ts := range dirChain {
+		if ts == [2]int{track, sector} {
+			info.Roles = append(info.Roles, BlockDirectory)
+			index := 0
+			for off := 0; off < SectorSize; off += entrySize {
+				raw := data[off : off+entrySize]
+				if raw[2] != 0 {
+					info.Entries = append(info.Entries, parseEntry(raw, index, track, sector, off))
+				}
+				index++
+			}
+			break
+		}
+	}
+
+	if len(info.Roles) == 0 {
+		if owner, index := img.blockOwner(track, sector); owner != nil {
+			info.Roles = append(info.Roles, BlockFile)
+			info.Owner = owner.Name
+			info.ChainIndex = index
+		} else if info.Free {
+			info.Roles = append(info.Roles, BlockFree)
+		} else {
+			info.Roles = append(info.Roles, BlockUnknown)
+		}
+	}
+
+	return info, nil
+}
+
+// bamUsage decodes the BAM entries of every track whose count or bitmap
+// is stored in the given block
+func (img *Image) bamUsage(block []byte) []TrackUsage {
+	var usage []TrackUsage
+	for t := 1; t <= img.Format.Tracks; t++ {
+		e := img.bamFor(t)
+		inCount := e.countOff >= 0 && &e.count[0] == &block[0]
+		if !inCount && &e.bitmap[0] != &block[0] {
+			continue
+		}
+
+		var sb strings.Builder
+		free := 0
+		for s := 0; s < img.Format.SectorsPerTrack(t); s++ {
+			if img.IsFree(t, s) {
+				sb.WriteByte('.')
+				free++
+			} else {
+				sb.WriteByte('#')
+			}
+		}
+
+		// Formats without counters report the bitmap count
+		if e.countOff >= 0 {
+			free = int(e.count[e.countOff])
+		}
+		usage = append(usage, TrackUsage{
+			Track:     t,
+			FreeCount: free,
+			Bitmap:    sb.String(),
+		})
+	}
+	return usage
+}
+
+// blockOwner finds the file that occupies a block and the block's
+// position among the file's sectors
+func (img *Image) blockOwner(track, sector int) (*DirEntry, int) {
+	entries, _ := img.Directory()
+	for i := range entries {
+		e := &entries[i]
+		sectors, _ := img.FileSectors(e)
+		for n, ts := range sectors {
+			if ts == [2]int{track, sector} {
+				return e, n
+			}
+		}
+	}
+	return nil, 0
+}
+
+// WriteBlock patches bytes of a block starting at offset
+func (img *Image) WriteBlock(track, sector, offset int, patch []byte) error {
+	data, err := img.Sector(track, sector)
+	if err != nil {
+		return err
+	}
+	if offset < 0 || offset+len(patch) > SectorSize {
+		return fmt.Errorf("patch of %d bytes at offset %d exceeds the block", len(patch), offset)
+	}
+	copy(data[offset:], patch)
+	return nil
+}
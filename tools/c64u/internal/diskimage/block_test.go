@@ -0,0 +1,111 @@
+package diskimage
+
+import (
+	"testing"
+)
+
+func TestInspectRoles(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        string
+		track, sector int
+		roles         []string
+	}{
+		{"d64 header and BAM", "d64", 18, 0, []string{BlockHeader, BlockBAM}},
+		{"d64 directory", "d64", 18, 1, []string{BlockDirectory}},
+		{"d64 file", "d64", 17, 10, []string{BlockFile}},
+		{"d64 free", "d64", 1, 0, []string{BlockFree}},
+		{"d81 header", "d81", 40, 0, []string{BlockHeader}},
+		{"d81 BAM", "d81", 40, 1, []string{BlockBAM}},
+		{"d81 directory", "d81", 40, 3, []string{BlockDirectory}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := newImage(t, tt.format, 0)
+			addFile(t, img, "FILE", 3*254)
+
+			info, err := img.Inspect(tt.track, tt.sector)
+			if err != nil {
+				t.Fatalf("Inspect: %v", err)
+			}
+			if len(info.Roles) != len(tt.roles) {
+				t.Fatalf("roles = %v, want %v", info.Roles, tt.roles)
+			}
+			for i := range tt.roles {
+				if info.Roles[i] != tt.roles[i] {
+					t.Errorf("roles = %v, want %v", info.Roles, tt.roles)
+				}
+			}
+		})
+	}
+}
+
+func TestInspectDetails(t *testing.T) {
+	img := newImage(t, "d64", 35)
+	addFile(t, img, "FILE", 3*254)
+
+	header, err := img.Inspect(18, 0)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if header.DiskName != "TEST DISK" || header.DiskID != "TD" {
+		t.Errorf("header name %q id %q", header.DiskName, header.DiskID)
+	}
+	if len(header.BAM) != 35 {
+		t.Fatalf("BAM tracks = %d, want 35", len(header.BAM))
+	}
+	if u := header.BAM[16]; u.Track != 17 || u.FreeCount != 18 || u.Bitmap[:11] != "#.........#" {
+		t.Errorf("track 17 usage = %+v", u)
+	}
+
+	dir, _ := img.Inspect(18, 1)
+	if len(dir.Entries) != 1 || dir.Entries[0].Name != "FILE" {
+		t.Errorf("directory entries = %+v", dir.Entries)
+	}
+
+	// The chain runs 17/0, 17/10, 17/20; the last block holds 254 bytes
+	last, _ := img.Inspect(17, 20)
+	if last.Owner != "FILE" || last.ChainIndex != 2 || !last.IsLast() || last.BytesUsed != 254 {
+		t.Errorf("last block = %+v", last)
+	}
+
+	if _, err := img.Inspect(36, 0); err == nil {
+		t.Error("Inspect(36, 0) succeeded")
+	}
+}
+
+func TestWriteBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		track   int
+		offset  int
+		patch   []byte
+		wantErr bool
+	}{
+		{"start", 1, 0, []byte{1, 2, 3}, false},
+		{"end", 1, 255, []byte{9}, false},
+		{"past end", 1, 255, []byte{9, 9}, true},
+		{"negative offset", 1, -1, []byte{0}, true},
+		{"bad track", 36, 0, []byte{0}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := newImage(t, "d64", 35)
+			err := img.WriteBlock(tt.track, 0, tt.offset, tt.patch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WriteBlock error = %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			data, _ := img.Sector(tt.track, 0)
+			for i, b := range tt.patch {
+				if data[tt.offset+i] != b {
+					t.Errorf("byte %d = %02X, want %02X", tt.offset+i, data[tt.offset+i], b)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package diskimage
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+)
+
+// File diff states
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+	DiffBroken  = "broken" // unreadable in either image
+)
+
+// Diff is the result of comparing two images
+type Diff struct {
+	DiskNameA string `json:"disk_name_a"`
+	DiskNameB string `json:"disk_name_b"`
+	DiskIDA   string `json:"disk_id_a"`
+	DiskIDB   string `json:"disk_id_b"`
+
+	Files []FileDiff `json:"files"`
+
+	// BAM and sector comparisons need matching geometry
+	SameGeometry bool       `json:"same_geometry"`
+	BAM          []BAMDiff  `json:"bam,omitempty"`
+	Sectors      []TrackSec `json:"sectors,omitempty"`
+	FreeA        int        `json:"blocks_free_a"`
+	FreeB        int        `json:"blocks_free_b"`
+}
+
+// FileDiff describes a directory entry that differs between two images
+type FileDiff struct {
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	TypeA   string   `json:"type_a,omitempty"`
+	TypeB   string   `json:"type_b,omitempty"`
+	BlocksA int      `json:"blocks_a,omitempty"`
+	BlocksB int      `json:"blocks_b,omitempty"`
+	HashA   string   `json:"hash_a,omitempty"`
+	HashB   string   `json:"hash_b,omitempty"`
+	ErrorA  string   `json:"error_a,omitempty"`
+	ErrorB  string   `json:"error_b,omitempty"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+// BAMDiff lists the sectors of one track whose allocation state differs
+type BAMDiff struct {
+	Track     int   `json:"track"`
+	Allocated []int `json:"allocated,omitempty"` // used in B, free in A
+	Freed     []int `json:"freed,omitempty"`     // free in B, used in A
+}
+
+// TrackSec identifies a sector
+type TrackSec struct {
+	Track  int `json:"track"`
+	Sector int `json:"sector"`
+}
+
+// IsEmpty reports whether the images are identical in every compared aspect
+func (d *Diff) IsEmpty() bool {
+	return d.DiskNameA == d.DiskNameB && d.DiskIDA == d.DiskIDB &&
+		len(d.Files) == 0 && len(d.BAM) == 0 && len(d.Sectors) == 0 && d.SameGeometry
+}
+
+// fileState is what Compare needs to know about one file. A file whose
+// chain or GEOS structure cannot be read has err set instead of hash.
+type fileState struct {
+	entry DirEntry
+	hash  string
+	err   string
+}
+
+// Compare compares two images. Files are matched by raw name; content is
+// compared by hash so moved but identical files are not reported. Files
+// that cannot be read are reported as broken instead of failing the diff.
+func Compare(a, b *Image) (*Diff, error) {
+	d := &Diff{
+		DiskNameA:    DisplayName(a.DiskName()),
+		DiskNameB:    DisplayName(b.DiskName()),
+		DiskIDA:      DisplayName(a.DiskID()),
+		DiskIDB:      DisplayName(b.DiskID()),
+		SameGeometry: a.Format.Name == b.Format.Name && a.Format.Tracks == b.Format.Tracks,
+		FreeA:        a.FreeBlocks(),
+		FreeB:        b.FreeBlocks(),
+	}
+
+	filesA, orderA, err := fileStates(a)
+	if err != nil {
+		return nil, fmt.Errorf("first image: %w", err)
+	}
+	filesB, orderB, err := fileStates(b)
+	if err != nil {
+		return nil, fmt.Errorf("second image: %w", err)
+	}
+
+	for _, key := range orderA {
+		fa := filesA[key]
+		fb, ok := filesB[key]
+		if !ok {
+			d.Files = append(d.Files, FileDiff{
+				Name: fa.entry.Name, Status: DiffRemoved,
+				TypeA: fa.entry.Type, BlocksA: fa.entry.Blocks, HashA: fa.hash,
+				ErrorA: fa.err,
+			})
+			continue
+		}
+
+		var changes []string
+		if fa.err == "" && fb.err == "" && fa.hash != fb.hash {
+			changes = append(changes, "content")
+		}
+		if fa.entry.TypeByte != fb.entry.TypeByte {
+			changes = append(changes, "type")
+		}
+		if fa.entry.Blocks != fb.entry.Blocks {
+			changes = append(changes, "blocks")
+		}
+		status := DiffChanged
+		if fa.err != "" || fb.err != "" {
+			status = DiffBroken
+		}
+		if len(changes) > 0 || status == DiffBroken {
+			d.Files = append(d.Files, FileDiff{
+				Name: fa.entry.Name, Status: status,
+				TypeA: fa.entry.Type, TypeB: fb.entry.Type,
+				BlocksA: fa.entry.Blocks, BlocksB: fb.entry.Blocks,
+				HashA: fa.hash, HashB: fb.hash,
+				ErrorA: fa.err, ErrorB: fb.err,
+				Changes: changes,
+			})
+		}
+	}
+	for _, key := range orderB {
+		if _, ok := filesA[key]; ok {
+			continue
+		}
+		fb := filesB[key]
+		d.Files = append(d.Files, FileDiff{
+			Name: fb.entry.Name, Status: DiffAdded,
+			TypeB: fb.entry.Type, BlocksB: fb.entry.Blocks, HashB: fb.hash,
+			ErrorB: fb.err,
+		})
+	}
+
+	if !d.SameGeometry {
+		return d, nil
+	}
+
+	for t := 1; t <= a.Format.Tracks; t++ {
+		bd := BAMDiff{Track: t}
+		for s := 0; s < a.Format.SectorsPerTrack(t); s++ {
+			freeA, freeB := a.IsFree(t, s), b.IsFree(t, s)
+			switch {
+			case freeA && !freeB:
+				bd.Allocated = append(bd.Allocated, s)
+			case !freeA && freeB:
+				bd.Freed = append(bd.Freed, s)
+			}
+			if !bytes.Equal(a.mustSector(t, s), b.mustSector(t, s)) {
+				d.Sectors = append(d.Sectors, TrackSec{t, s})
+			}
+		}
+		if len(bd.Allocated) > 0 || len(bd.Freed) > 0 {
+			d.BAM = append(d.BAM, bd)
+		}
+	}
+
+	return d, nil
+}
+
+// fileStates reads all files of an image, keyed by raw name.
+// Duplicate names get a numeric suffix so each entry is compared. Read
+// errors are recorded on the file; only an unreadable directory fails.
+func fileStates(img *Image) (map[string]fileState, []string, error) {
+	entries, err := img.Directory()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	states := make(map[string]fileState)
+	var order []string
+	for _, e := range entries {
+		var data []byte
+		var err error
+		switch {
+		case e.IsGEOS():
+			// Hash the CVT form so info block and VLIR records count too
+			data, err = img.ExportCVT(&e)
+		case e.Track != 0 && e.IsFile():
+			data, err = img.ReadFile(&e)
+		}
+		key := hex.EncodeToString(e.RawName)
+		for n := 2; ; n++ {
+			if _, dup := states[key]; !dup {
+				break
+			}
+			key = fmt.Sprintf("%s#%d", hex.EncodeToString(e.RawName), n)
+		}
+
+		state := fileState{entry: e}
+		if err != nil {
+			state.err = err.Error()
+		} else {
+			sum := sha1.Sum(data)
+			state.hash = hex.EncodeToString(sum[:8])
+		}
+		states[key] = state
+		order = append(order, key)
+	}
+
+	return states, order, nil
+}
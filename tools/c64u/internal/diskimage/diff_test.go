@@ -0,0 +1,134 @@
+package diskimage
+
+import (
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, img *Image)
+		want   map[string]string // file name -> status
+		bam    bool              // BAM differences expected
+	}{
+		{
+			name:   "identical",
+			modify: func(t *testing.T, img *Image) {},
+		},
+		{
+			name:   "added",
+			modify: func(t *testing.T, img *Image) { addFile(t, img, "NEW", 10) },
+			want:   map[string]string{"NEW": DiffAdded},
+			bam:    true,
+		},
+		{
+			name: "removed",
+			modify: func(t *testing.T, img *Image) {
+				e, _ := img.Find("SAME")
+				if err := img.DeleteFile(e); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: map[string]string{"SAME": DiffRemoved},
+			bam:  true,
+		},
+		{
+			name: "content changed in place",
+			modify: func(t *testing.T, img *Image) {
+				e, _ := img.Find("SAME")
+				if err := img.WriteBlock(e.Track, e.Sector, 2, []byte{0xFF}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: map[string]string{"SAME": DiffChanged},
+		},
+		{
+			name: "broken chain",
+			modify: func(t *testing.T, img *Image) {
+				e, _ := img.Find("SAME")
+				if err := img.WriteBlock(e.Track, e.Sector, 0, []byte{99, 0}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: map[string]string{"SAME": DiffBroken},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newImage(t, "d64", 35)
+			addFile(t, a, "SAME", 600)
+			b, err := Parse(append([]byte(nil), a.Bytes()...), "d64")
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			tt.modify(t, b)
+
+			d, err := Compare(a, b)
+			if err != nil {
+				t.Fatalf("Compare: %v", err)
+			}
+			if !d.SameGeometry {
+				t.Error("SameGeometry = false")
+			}
+
+			got := map[string]string{}
+			for _, f := range d.Files {
+				got[f.Name] = f.Status
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+			for name, status := range tt.want {
+				if got[name] != status {
+					t.Errorf("%s: status %q, want %q", name, got[name], status)
+				}
+			}
+			if (len(d.BAM) > 0) != tt.bam {
+				t.Errorf("BAM diff = %v, want differences: %v", d.BAM, tt.bam)
+			}
+			if len(tt.want) == 0 && !d.IsEmpty() {
+				t.Errorf("IsEmpty = false for %+v", d)
+			}
+		})
+	}
+}
+
+func TestCompareGeometry(t *testing.T) {
+	a := newImage(t, "d64", 35)
+	b := newImage(t, "d64", 40)
+
+	d, err := Compare(a, b)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if d.SameGeometry || d.IsEmpty() {
+		t.Errorf("SameGeometry = %v, IsEmpty = %v", d.SameGeometry, d.IsEmpty())
+	}
+	if len(d.BAM) != 0 || len(d.Sectors) != 0 {
+		t.Error("BAM or sectors compared across geometries")
+	}
+	if d.FreeA != 664 || d.FreeB != 749 {
+		t.Errorf("free = %d/%d, want 664/749", d.FreeA, d.FreeB)
+	}
+}
+
+func TestCompareAllocatedSectors(t *testing.T) {
+	a := newImage(t, "d64", 35)
+	b := newImage(t, "d64", 35)
+	addFile(t, b, "FILE", 2*254)
+
+	d, err := Compare(a, b)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if len(d.BAM) != 1 || d.BAM[0].Track != 17 {
+		t.Fatalf("BAM = %+v, want track 17 only", d.BAM)
+	}
+	if got := d.BAM[0].Allocated; len(got) != 2 || got[0] != 0 || got[1] != 10 {
+		t.Errorf("allocated = %v, want [0 10]", got)
+	}
+	if len(d.BAM[0].Freed) != 0 {
+		t.Errorf("freed = %v", d.BAM[0].Freed)
+	}
+}
@@ -0,0 +1,544 @@
+package diskimage
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+)
+
+// CBM file types (low nibble of the directory type byte)
+const (
+	TypeDEL = 0
+	TypeSEQ = 1
+	TypePRG = 2
+	TypeUSR = 3
+	TypeREL = 4
+	TypeCBM = 5
+	TypeDIR = 6 // CMD native partition subdirectory
+)
+
+// Directory type byte flags
+const (
+	flagClosed = 0x80
+	flagLocked = 0x40
+)
+
+const entrySize = 32
+
+// DirEntry is a single directory entry
+type DirEntry struct {
+	Index       int    `json:"index"`
+	Name        string `json:"name"`
+	RawName     []byte `json:"-"`
+	Type        string `json:"type"`
+	TypeByte    byte   `json:"type_byte"`
+	Closed      bool   `json:"closed"`
+	Locked      bool   `json:"locked"`
+	Track       int    `json:"track"`
+	Sector      int    `json:"sector"`
+	Blocks      int    `json:"blocks"`
+	RecordLen   int    `json:"record_length,omitempty"`
+	GEOSType    int    `json:"geos_type,omitempty"`
+	VLIR        bool   `json:"vlir,omitempty"`
+	InfoTrack   int    `json:"-"`
+	InfoSector  int    `json:"-"`
+	EntryTrack  int    `json:"-"`
+	EntrySector int    `json:"-"`
+	EntryOffset int    `json:"-"`
+}
+
+// FileType returns the CBM file type (low nibble of the type byte)
+func (e *DirEntry) FileType() int {
+	return int(e.TypeByte & 0x0F)
+}
+
+// IsFile reports whether the entry holds file data in a sector chain,
+// as opposed to a CBM partition or a subdirectory
+func (e *DirEntry) IsFile() bool {
+	return e.FileType() != TypeCBM && e.FileType() != TypeDIR
+}
+
+// TypeName returns the three-letter name of a CBM file type
+func TypeName(t int) string {
+	switch t {
+	case TypeDEL:
+		return "DEL"
+	case TypeSEQ:
+		return "SEQ"
+	case TypePRG:
+		return "PRG"
+	case TypeUSR:
+		return "USR"
+	case TypeREL:
+		return "REL"
+	case TypeCBM:
+		return "CBM"
+	case TypeDIR:
+		return "DIR"
+	default:
+		return fmt.Sprintf("?%d", t)
+	}
+}
+
+// ParseTypeName returns the CBM file type for a three-letter name
+func ParseTypeName(name string) (int, error) {
+	for t := TypeDEL; t <= TypeCBM; t++ {
+		if strings.EqualFold(name, TypeName(t)) {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown file type %q", name)
+}
+
+// dirStart returns the first directory block of the current directory
+func (img *Image) dirStart() (int, int) {
+	if img.dir != [2]int{} {
+		hdr := img.mustSector(img.dir[0], img.dir[1])
+		return int(hdr[0]), int(hdr[1])
+	}
+	return img.Format.DirTrack, img.Format.FirstDirSector
+}
+
+// ChDir changes the current directory of a DNP image. Paths use "/" as
+// separator; a leading "/" starts at the root and ".." goes up one level.
+// Other formats only have the root directory. On error the current
+// directory is left unchanged.
+func (img *Image) ChDir(path string) (err error) {
+	saved := img.dir
+	defer func() {
+		if err != nil {
+			img.dir = saved
+		}
+	}()
+
+	if strings.HasPrefix(path, "/") {
+		img.dir = [2]int{}
+	}
+
+	for _, part := range strings.Split(path, "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if img.dir != [2]int{} {
+				hdr := img.mustSector(img.dir[0], img.dir[1])
+				img.dir = [2]int{int(hdr[0x22]), int(hdr[0x23])}
+				if img.dir == [2]int{img.Format.DirTrack, img.Format.HeaderSector} {
+					img.dir = [2]int{}
+				}
+			}
+			continue
+		}
+
+		if img.Format.Name != "dnp" {
+			return fmt.Errorf("%s images have no subdirectories", img.Format.Name)
+		}
+
+		e, err := img.Find(part)
+		if err != nil {
+			return fmt.Errorf("directory not found: %s", part)
+		}
+		if e.FileType() != TypeDIR {
+			return fmt.Errorf("%s is not a directory", e.Name)
+		}
+		if _, err := img.Sector(e.Track, e.Sector); err != nil {
+			return fmt.Errorf("broken directory %s: %w", e.Name, err)
+		}
+		img.dir = [2]int{e.Track, e.Sector}
+	}
+
+	return nil
+}
+
+// SplitPath splits "DIR/SUB/NAME" into the directory path and the name
+func SplitPath(path string) (string, string) {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i+1], path[i+1:]
+	}
+	return "", path
+}
+
+// Lookup finds a file by path, relative to the current directory. The
+// current directory is unchanged afterwards.
+func (img *Image) Lookup(path string) (*DirEntry, error) {
+	saved := img.dir
+	defer func() { img.dir = saved }()
+
+	dir, name := SplitPath(path)
+	if err := img.ChDir(dir); err != nil {
+		return nil, err
+	}
+	return img.Find(name)
+}
+
+// dirSectors returns the chain of directory blocks
+func (img *Image) dirSectors() ([][2]int, error) {
+	track, sector := img.dirStart()
+	var chain [][2]int
+	seen := make(map[[2]int]bool)
+
+	for track != 0 {
+		ts := [2]int{track, sector}
+		if seen[ts] {
+			return chain, fmt.Errorf("directory chain loops at %d/%d", track, sector)
+		}
+		seen[ts] = true
+
+		data, err := img.Sector(track, sector)
+		if err != nil {
+			return chain, fmt.Errorf("broken directory chain: %w", err)
+		}
+		chain = append(chain, ts)
+		track, sector = int(data[0]), int(data[1])
+	}
+
+	return chain, nil
+}
+
+// Directory returns all used directory entries in directory order
+func (img *Image) Directory() ([]DirEntry, error) {
+	chain, err := img.dirSectors()
+
+	var entries []DirEntry
+	index := 0
+	for _, ts := range chain {
+		data := img.mustSector(ts[0], ts[1])
+		for off := 0; off < SectorSize; off += entrySize {
+			raw := data[off : off+entrySize]
+			if raw[2] == 0 {
+				continue
+			}
+			entries = append(entries, parseEntry(raw, index, ts[0], ts[1], off))
+			index++
+		}
+	}
+
+	return entries, err
+}
+
+// parseEntry decodes a 32-byte directory entry
+func parseEntry(raw []byte, index, track, sector, off int) DirEntry {
+	name := TrimName(raw[5:21])
+	e := DirEntry{
+		Index:       index,
+		Name:        DisplayName(name),
+		RawName:     name,
+		TypeByte:    raw[2],
+		Closed:      raw[2]&flagClosed != 0,
+		Locked:      raw[2]&flagLocked != 0,
+		Track:       int(raw[3]),
+		Sector:      int(raw[4]),
+		Blocks:      int(raw[30]) | int(raw[31])<<8,
+		EntryTrack:  track,
+		EntrySector: sector,
+		EntryOffset: off,
+	}
+	e.Type = TypeName(e.FileType())
+	if e.FileType() == TypeREL {
+		e.RecordLen = int(raw[23])
+	} else if raw[24] != 0 {
+		// GEOS files reuse the REL fields for the info block and structure
+		e.GEOSType = int(raw[24])
+		e.VLIR = raw[23] == 1
+		e.InfoTrack, e.InfoSector = int(raw[21]), int(raw[22])
+	}
+	return e
+}
+
+// Find returns the first entry whose name matches (case-insensitive for
+// letters). The pattern may use CBM wildcards * and ?.
+func (img *Image) Find(name string) (*DirEntry, error) {
+	return img.FindRaw(petscii.FromASCII(name))
+}
+
+// FindRaw returns the first entry whose raw PETSCII name matches the pattern
+func (img *Image) FindRaw(pattern []byte) (*DirEntry, error) {
+	entries, err := img.Directory()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		if MatchName(pattern, entries[i].RawName) {
+			return &entries[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("file not found: %s", DisplayName(pattern))
+}
+
+// MatchName matches a PETSCII name against a pattern with CBM wildcards
+func MatchName(pattern, name []byte) bool {
+	for i, c := range pattern {
+		switch {
+		case c == '*':
+			return true
+		case i >= len(name):
+			return false
+		case c == '?':
+			continue
+		case c != name[i]:
+			return false
+		}
+	}
+	return len(pattern) == len(name)
+}
+
+// ReadFile returns the data of a file by following its sector chain
+func (img *Image) ReadFile(e *DirEntry) ([]byte, error) {
+	return img.ReadChain(e.Track, e.Sector)
+}
+
+// ReadChain follows a sector chain and returns the data it holds
+func (img *Image) ReadChain(track, sector int) ([]byte, error) {
+	var buf bytes.Buffer
+	seen := make(map[[2]int]bool)
+
+	for track != 0 {
+		ts := [2]int{track, sector}
+		if seen[ts] {
+			return buf.Bytes(), fmt.Errorf("sector chain loops at %d/%d", track, sector)
+		}
+		seen[ts] = true
+
+		data, err := img.Sector(track, sector)
+		if err != nil {
+			return buf.Bytes(), fmt.Errorf("broken sector chain: %w", err)
+		}
+
+		if data[0] == 0 {
+			last := int(data[1])
+			if last < 2 {
+				last = 1
+			}
+			buf.Write(data[2 : last+1])
+			break
+		}
+
+		buf.Write(data[2:])
+		track, sector = int(data[0]), int(data[1])
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ChainSectors returns the sectors of a chain in order
+func (img *Image) ChainSectors(track, sector int) ([][2]int, error) {
+	var chain [][2]int
+	seen := make(map[[2]int]bool)
+
+	for track != 0 {
+		ts := [2]int{track, sector}
+		if seen[ts] {
+			return chain, fmt.Errorf("sector chain loops at %d/%d", track, sector)
+		}
+		seen[ts] = true
+
+		data, err := img.Sector(track, sector)
+		if err != nil {
+			return chain, fmt.Errorf("broken sector chain: %w", err)
+		}
+		chain = append(chain, ts)
+		track, sector = int(data[0]), int(data[1])
+	}
+
+	return chain, nil
+}
+
+// NewFile describes a file to be written to an image
+type NewFile struct {
+	Name   []byte // raw PETSCII name, at most 16 bytes
+	Type   int    // CBM file type
+	Locked bool
+	Closed bool
+	Data   []byte
+}
+
+// AddFile writes a file's data and appends a directory entry
+func (img *Image) AddFile(nf NewFile) (*DirEntry, error) {
+	if len(nf.Name) > 16 {
+		return nil, fmt.Errorf("file name too long (%d > 16 characters)", len(nf.Name))
+	}
+	switch nf.Type {
+	case TypeREL:
+		return nil, fmt.Errorf("REL files are not supported")
+	case TypeCBM, TypeDIR:
+		return nil, fmt.Errorf("%s entries cannot be written as files", TypeName(nf.Type))
+	}
+
+	// DOS never writes an empty chain; a DEL separator may have no data
+	blocks := (len(nf.Data) + 253) / 254
+	if blocks == 0 && nf.Type != TypeDEL {
+		blocks = 1
+	}
+
+	// Allocate the data first: a new directory block is linked into the
+	// directory as soon as it is allocated, so it must not be left behind
+	// on a full disk
+	chain, err := img.allocateChain(blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	slotTrack, slotSector, slotOff, err := img.freeDirSlot()
+	if err != nil {
+		img.freeChain(chain)
+		return nil, err
+	}
+	img.writeChain(chain, nf.Data)
+
+	raw := img.mustSector(slotTrack, slotSector)[slotOff : slotOff+entrySize]
+	for i := 2; i < entrySize; i++ {
+		raw[i] = 0
+	}
+
+	typeByte := byte(nf.Type)
+	if nf.Closed {
+		typeByte |= flagClosed
+	}
+	if nf.Locked {
+		typeByte |= flagLocked
+	}
+	raw[2] = typeByte
+	if len(chain) > 0 {
+		raw[3], raw[4] = byte(chain[0][0]), byte(chain[0][1])
+	}
+	copy(raw[5:21], PadName(nf.Name))
+	raw[30], raw[31] = byte(blocks), byte(blocks>>8)
+
+	e := parseEntry(raw, -1, slotTrack, slotSector, slotOff)
+	return &e, nil
+}
+
+// writeChain writes data into the allocated sectors and links them
+func (img *Image) writeChain(chain [][2]int, data []byte) {
+	for i, ts := range chain {
+		sec := img.mustSector(ts[0], ts[1])
+		for j := range sec {
+			sec[j] = 0
+		}
+
+		start := i * 254
+		end := start + 254
+		if end > len(data) {
+			end = len(data)
+		}
+		n := 0
+		if start < len(data) {
+			n = copy(sec[2:], data[start:end])
+		}
+
+		if i+1 < len(chain) {
+			sec[0], sec[1] = byte(chain[i+1][0]), byte(chain[i+1][1])
+		} else {
+			sec[0], sec[1] = 0, byte(n+1)
+		}
+	}
+}
+
+// freeChain releases sectors allocated for a file that was not written
+func (img *Image) freeChain(chain [][2]int) {
+	for _, ts := range chain {
+		img.setFree(ts[0], ts[1], true)
+	}
+}
+
+// freeDirSlot finds an unused directory entry, extending the directory
+// with a new block if all existing ones are full
+func (img *Image) freeDirSlot() (int, int, int, error) {
+	chain, err := img.dirSectors()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	for _, ts := range chain {
+		data := img.mustSector(ts[0], ts[1])
+		for off := 0; off < SectorSize; off += entrySize {
+			if data[off+2] == 0 {
+				return ts[0], ts[1], off, nil
+			}
+		}
+	}
+
+	last := chain[len(chain)-1]
+	track, sector, err := img.allocateDirBlock(last[1])
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	prev := img.mustSector(last[0], last[1])
+	prev[0], prev[1] = byte(track), byte(sector)
+
+	blk := img.mustSector(track, sector)
+	for i := range blk {
+		blk[i] = 0
+	}
+	blk[1] = 0xFF
+
+	return track, sector, 0, nil
+}
+
+// allocateDirBlock allocates a new directory block on the directory track.
+// Native partitions take the next free block anywhere on the disk.
+func (img *Image) allocateDirBlock(after int) (int, int, error) {
+	f := img.Format
+	if f.Name == "dnp" {
+		chain, err := img.allocateChain(1)
+		if err != nil {
+			return 0, 0, fmt.Errorf("directory full: %w", err)
+		}
+		return chain[0][0], chain[0][1], nil
+	}
+
+	spt := f.SectorsPerTrack(f.DirTrack)
+	for i := 0; i < spt; i++ {
+		s := (after + f.DirInterleave + i) % spt
+		if img.IsFree(f.DirTrack, s) {
+			img.setFree(f.DirTrack, s, false)
+			return f.DirTrack, s, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("directory full")
+}
+
+// DeleteFile scratches a file: its sectors (including GEOS info and VLIR
+// blocks) are freed and the entry cleared
+func (img *Image) DeleteFile(e *DirEntry) error {
+	if !e.IsFile() {
+		return fmt.Errorf("cannot scratch %s entry %s", e.Type, e.Name)
+	}
+
+	sectors, err := img.FileSectors(e)
+	if err != nil {
+		return err
+	}
+	for _, ts := range sectors {
+		img.setFree(ts[0], ts[1], true)
+	}
+
+	img.entryBytes(e)[2] = 0
+	return nil
+}
+
+// TrimName strips shifted-space padding from a raw name
+func TrimName(raw []byte) []byte {
+	end := len(raw)
+	for end > 0 && raw[end-1] == 0xA0 {
+		end--
+	}
+	return append([]byte(nil), raw[:end]...)
+}
+
+// PadName pads a raw name to 16 bytes with shifted spaces
+func PadName(name []byte) []byte {
+	out := bytes.Repeat([]byte{0xA0}, 16)
+	copy(out, name)
+	return out
+}
+
+// DisplayName converts a PETSCII name to printable text
+func DisplayName(raw []byte) string {
+	return petscii.ToASCII(raw)
+}
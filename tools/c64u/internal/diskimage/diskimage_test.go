@@ -0,0 +1,382 @@
+package diskimage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+)
+
+// newImage formats an empty image of the named format
+func newImage(t *testing.T, format string, tracks int) *Image {
+	t.Helper()
+	f, err := FormatByName(format, tracks)
+	if err != nil {
+		t.Fatalf("FormatByName(%q, %d): %v", format, tracks, err)
+	}
+	return New(f, petscii.FromASCII("TEST DISK"), petscii.FromASCII("TD"))
+}
+
+// addFile writes a PRG with the given name and size
+func addFile(t *testing.T, img *Image, name string, size int) *DirEntry {
+	t.Helper()
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	e, err := img.AddFile(NewFile{Name: petscii.FromASCII(name), Type: TypePRG, Closed: true, Data: data})
+	if err != nil {
+		t.Fatalf("AddFile(%q): %v", name, err)
+	}
+	return e
+}
+
+func TestNewFreeBlocks(t *testing.T) {
+	tests := []struct {
+		format string
+		tracks int
+		free   int
+	}{
+		{"d64", 35, 664},
+		{"d64", 40, 749},
+		{"d71", 0, 1328},
+		{"d81", 0, 3160},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			img := newImage(t, tt.format, tt.tracks)
+			if got := img.FreeBlocks(); got != tt.free {
+				t.Errorf("FreeBlocks = %d, want %d", got, tt.free)
+			}
+
+			// The stored free counters must agree with the bitmaps
+			for track := 1; track <= img.Format.Tracks; track++ {
+				e := img.bamFor(track)
+				if e.countOff < 0 {
+					continue
+				}
+				if got, want := int(e.count[e.countOff]), img.FreeOnTrack(track); got != want {
+					t.Errorf("track %d: counter %d, bitmap %d", track, got, want)
+				}
+			}
+
+			reopened, err := Parse(img.Bytes(), tt.format)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := DisplayName(reopened.DiskName()); got != "TEST DISK" {
+				t.Errorf("disk name = %q, want %q", got, "TEST DISK")
+			}
+		})
+	}
+}
+
+func TestAllocateAndFree(t *testing.T) {
+	img := newImage(t, "d64", 35)
+
+	if err := img.Allocate(1, 0); err != nil {
+		t.Fatalf("Allocate: %v", err)
+	}
+	if img.IsFree(1, 0) || img.FreeOnTrack(1) != 20 {
+		t.Errorf("1/0 still free or wrong count (%d free on track 1)", img.FreeOnTrack(1))
+	}
+	if err := img.Allocate(1, 0); err == nil {
+		t.Error("second Allocate of 1/0 succeeded")
+	}
+	if err := img.Free(1, 0); err != nil {
+		t.Fatalf("Free: %v", err)
+	}
+	if img.FreeBlocks() != 664 {
+		t.Errorf("FreeBlocks after Free = %d, want 664", img.FreeBlocks())
+	}
+
+	tests := []struct {
+		track, sector int
+	}{
+		{0, 0},
+		{36, 0},
+		{18, 19},
+		{31, 17},
+	}
+	for _, tt := range tests {
+		if err := img.Allocate(tt.track, tt.sector); err == nil {
+			t.Errorf("Allocate(%d, %d) succeeded, want error", tt.track, tt.sector)
+		}
+	}
+}
+
+func TestAddFileChain(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		size   int
+		chain  [][2]int
+	}{
+		{
+			name:   "d64 interleave",
+			format: "d64",
+			size:   4 * 254,
+			chain:  [][2]int{{17, 0}, {17, 10}, {17, 20}, {17, 9}},
+		},
+		{
+			name:   "d64 empty file takes one block",
+			format: "d64",
+			size:   0,
+			chain:  [][2]int{{17, 0}},
+		},
+		{
+			name:   "d81 interleave",
+			format: "d81",
+			size:   3 * 254,
+			chain:  [][2]int{{39, 0}, {39, 1}, {39, 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := newImage(t, tt.format, 0)
+			free := img.FreeBlocks()
+
+			e := addFile(t, img, "FILE", tt.size)
+			chain, err := img.ChainSectors(e.Track, e.Sector)
+			if err != nil {
+				t.Fatalf("ChainSectors: %v", err)
+			}
+			if len(chain) != len(tt.chain) {
+				t.Fatalf("chain = %v, want %v", chain, tt.chain)
+			}
+			for i := range chain {
+				if chain[i] != tt.chain[i] {
+					t.Errorf("chain = %v, want %v", chain, tt.chain)
+					break
+				}
+			}
+			if e.Blocks != len(tt.chain) || img.FreeBlocks() != free-len(tt.chain) {
+				t.Errorf("blocks %d, free %d -> %d", e.Blocks, free, img.FreeBlocks())
+			}
+
+			data, err := img.ReadFile(e)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if len(data) != tt.size {
+				t.Errorf("read %d bytes, want %d", len(data), tt.size)
+			}
+		})
+	}
+}
+
+func TestAddFileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		nf   NewFile
+	}{
+		{"name too long", NewFile{Name: bytes.Repeat([]byte{'A'}, 17), Type: TypePRG}},
+		{"REL file", NewFile{Name: []byte("REL"), Type: TypeREL}},
+		{"DIR entry", NewFile{Name: []byte("DIR"), Type: TypeDIR}},
+		{"disk full", NewFile{Name: []byte("BIG"), Type: TypePRG, Data: make([]byte, 665*254)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := newImage(t, "d64", 35)
+			before := append([]byte(nil), img.Bytes()...)
+
+			if _, err := img.AddFile(tt.nf); err == nil {
+				t.Fatal("AddFile succeeded, want error")
+			}
+			if !bytes.Equal(img.Bytes(), before) {
+				t.Error("failed AddFile changed the image")
+			}
+		})
+	}
+}
+
+func TestDirectoryGrows(t *testing.T) {
+	img := newImage(t, "d64", 35)
+
+	// A directory block holds 8 entries; the ninth file needs a new block
+	// at the directory interleave of 3
+	var last *DirEntry
+	for i := 0; i < 9; i++ {
+		last = addFile(t, img, string(rune('A'+i)), 10)
+	}
+	if last.EntryTrack != 18 || last.EntrySector != 4 || last.EntryOffset != 0 {
+		t.Errorf("ninth entry at %d/%d+%d, want 18/4+0", last.EntryTrack, last.EntrySector, last.EntryOffset)
+	}
+	if img.IsFree(18, 4) {
+		t.Error("new directory block 18/4 not allocated")
+	}
+
+	entries, err := img.Directory()
+	if err != nil {
+		t.Fatalf("Directory: %v", err)
+	}
+	if len(entries) != 9 {
+		t.Errorf("got %d entries, want 9", len(entries))
+	}
+}
+
+func TestDeleteFile(t *testing.T) {
+	img := newImage(t, "d64", 35)
+	addFile(t, img, "KEEP", 300)
+	e := addFile(t, img, "GONE", 1000)
+
+	if err := img.DeleteFile(e); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if got := img.FreeBlocks(); got != 662 {
+		t.Errorf("FreeBlocks = %d, want 662", got)
+	}
+	if _, err := img.Find("GONE"); err == nil {
+		t.Error("deleted file still found")
+	}
+	if _, err := img.Find("KEEP"); err != nil {
+		t.Errorf("Find(KEEP): %v", err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	img := newImage(t, "d64", 35)
+	addFile(t, img, "GAME", 10)
+	addFile(t, img, "GAME LOADER", 10)
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"GAME", "GAME"},
+		{"game", "GAME"},
+		{"GAME*", "GAME"},
+		{"GAME ?OADER", "GAME LOADER"},
+		{"G*", "GAME"},
+		{"LOADER", ""},
+	}
+
+	for _, tt := range tests {
+		e, err := img.Lookup(tt.pattern)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("Lookup(%q) found %q, want error", tt.pattern, e.Name)
+		case tt.want != "" && err != nil:
+			t.Errorf("Lookup(%q): %v", tt.pattern, err)
+		case tt.want != "" && e.Name != tt.want:
+			t.Errorf("Lookup(%q) = %q, want %q", tt.pattern, e.Name, tt.want)
+		}
+	}
+
+	if err := img.ChDir("SUB"); err == nil {
+		t.Error("ChDir on a D64 succeeded")
+	}
+}
+
+func TestPackUnpackRoundTrip(t *testing.T) {
+	img := newImage(t, "d64", 35)
+	files := []NewFile{
+		{Name: petscii.FromASCII("HELLO"), Type: TypePRG, Closed: true, Data: bytes.Repeat([]byte{1, 8}, 200)},
+		{Name: petscii.FromASCII("NOTES"), Type: TypeSEQ, Closed: true, Locked: true, Data: []byte("HELLO\r")},
+		{Name: []byte{0x41, 0xC1, 0x2F}, Type: TypeUSR, Closed: true, Data: []byte{0xFF}},
+		{Name: petscii.FromASCII("----------------"), Type: TypeDEL, Closed: true},
+	}
+	for _, nf := range files {
+		if _, err := img.AddFile(nf); err != nil {
+			t.Fatalf("AddFile: %v", err)
+		}
+	}
+
+	// A REL file cannot be packed again, so it is skipped
+	rel := addFile(t, img, "RECORDS", 10)
+	img.entryBytes(rel)[2] = flagClosed | TypeREL
+
+	dir := t.TempDir()
+	m, err := Unpack(img, dir)
+	if err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+	if len(m.Files) != len(files) || len(m.Skipped) != 1 {
+		t.Fatalf("unpacked %d files, skipped %q", len(m.Files), m.Skipped)
+	}
+
+	packed, _, err := Pack(dir, PackOptions{Format: img.Format})
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+	if got := DisplayName(packed.DiskName()); got != "TEST DISK" {
+		t.Errorf("disk name = %q", got)
+	}
+	if got := string(packed.DiskID()); got != "TD" {
+		t.Errorf("disk ID = %q", got)
+	}
+
+	entries, err := packed.Directory()
+	if err != nil {
+		t.Fatalf("Directory: %v", err)
+	}
+	if len(entries) != len(files) {
+		t.Fatalf("packed %d entries, want %d", len(entries), len(files))
+	}
+	for i, e := range entries {
+		nf := files[i]
+		if !bytes.Equal(e.RawName, nf.Name) || e.FileType() != nf.Type || e.Locked != nf.Locked {
+			t.Errorf("entry %d = %q %s locked=%v", i, e.Name, e.Type, e.Locked)
+		}
+		if nf.Type == TypeDEL {
+			continue
+		}
+		data, err := packed.ReadFile(&e)
+		if err != nil || !bytes.Equal(data, nf.Data) {
+			t.Errorf("entry %d: data differs (%v)", i, err)
+		}
+	}
+}
+
+func TestPackRejectsOutsidePaths(t *testing.T) {
+	tests := []string{"../secret.prg", "/etc/passwd", "sub/../../secret.prg"}
+
+	for _, file := range tests {
+		t.Run(file, func(t *testing.T) {
+			dir := t.TempDir()
+			m := &Manifest{Files: []ManifestEntry{{File: file, Name: "X", Type: "prg"}}}
+			if err := m.Save(filepath.Join(dir, ManifestFile)); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+
+			_, _, err := Pack(dir, PackOptions{Format: FormatD64})
+			if err == nil || !strings.Contains(err.Error(), "inside the packed directory") {
+				t.Errorf("Pack error = %v, want path rejection", err)
+			}
+		})
+	}
+}
+
+func TestPackDiskFull(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "big.prg"), make([]byte, 700*254), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Pack(dir, PackOptions{Format: FormatD64}); err == nil {
+		t.Error("Pack of an oversized file succeeded")
+	}
+}
+
+func TestLocalFileName(t *testing.T) {
+	used := map[string]bool{}
+	tests := []struct {
+		name, fileType, want string
+	}{
+		{"GAME", "PRG", "game.prg"},
+		{"GAME", "PRG", "game_2.prg"},
+		{"HI SCORE/1", "SEQ", "hi_score_1.seq"},
+		{"..", "USR", "unnamed.usr"},
+	}
+
+	for _, tt := range tests {
+		if got := LocalFileName(tt.name, tt.fileType, used); got != tt.want {
+			t.Errorf("LocalFileName(%q, %q) = %q, want %q", tt.name, tt.fileType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package diskimage
+
+import (
+	"testing"
+)
+
+// makeSubdir turns a new one-block file into a DNP subdirectory: its
+// block becomes the header, pointing at an empty directory block and
+// back at the root header
+func makeSubdir(t *testing.T, img *Image, name string, dirTrack, dirSector int) {
+	t.Helper()
+	e := addFile(t, img, name, 0)
+	img.entryBytes(e)[2] = flagClosed | TypeDIR
+
+	if err := img.Allocate(dirTrack, dirSector); err != nil {
+		t.Fatalf("Allocate: %v", err)
+	}
+	hdr := img.mustSector(e.Track, e.Sector)
+	hdr[0], hdr[1] = byte(dirTrack), byte(dirSector)
+	hdr[0x22], hdr[0x23] = 1, 1
+
+	dir := img.mustSector(dirTrack, dirSector)
+	dir[0], dir[1] = 0, 0xFF
+}
+
+func TestFormatDNP(t *testing.T) {
+	tests := []struct {
+		tracks int
+		free   int
+	}{
+		{1, 256 - 35},
+		{4, 4*256 - 35},
+		{255, 255*256 - 35},
+	}
+
+	for _, tt := range tests {
+		img := newImage(t, "dnp", tt.tracks)
+		if got := img.FreeBlocks(); got != tt.free {
+			t.Errorf("%d tracks: FreeBlocks = %d, want %d", tt.tracks, got, tt.free)
+		}
+		for s := 0; s <= 34; s++ {
+			if img.IsFree(1, s) {
+				t.Errorf("%d tracks: reserved block 1/%d is free", tt.tracks, s)
+				break
+			}
+		}
+	}
+}
+
+func TestDNPAllocation(t *testing.T) {
+	img := newImage(t, "dnp", 4)
+
+	// Native partitions fill the disk from the start without interleave
+	e := addFile(t, img, "FIRST", 3*254)
+	chain, err := img.ChainSectors(e.Track, e.Sector)
+	if err != nil {
+		t.Fatalf("ChainSectors: %v", err)
+	}
+	want := [][2]int{{1, 35}, {1, 36}, {1, 37}}
+	for i := range want {
+		if i >= len(chain) || chain[i] != want[i] {
+			t.Fatalf("chain = %v, want %v", chain, want)
+		}
+	}
+
+	// 218 blocks are left on track 1; the rest continues on track 2
+	big := addFile(t, img, "BIG", 300*254)
+	chain, _ = img.ChainSectors(big.Track, big.Sector)
+	if last := chain[len(chain)-1]; last != [2]int{2, 81} {
+		t.Errorf("last block = %v, want [2 81]", last)
+	}
+
+	if err := img.DeleteFile(big); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if got := img.FreeBlocks(); got != 4*256-35-3 {
+		t.Errorf("FreeBlocks after delete = %d", got)
+	}
+}
+
+func TestDNPSubdirectories(t *testing.T) {
+	img := newImage(t, "dnp", 4)
+	makeSubdir(t, img, "SUB", 2, 0)
+	addFile(t, img, "ROOTFILE", 10)
+
+	if err := img.ChDir("SUB"); err != nil {
+		t.Fatalf("ChDir: %v", err)
+	}
+	addFile(t, img, "INNER", 10)
+	entries, err := img.Directory()
+	if err != nil || len(entries) != 1 || entries[0].Name != "INNER" {
+		t.Fatalf("SUB directory = %v (%v)", entries, err)
+	}
+
+	if err := img.ChDir(".."); err != nil {
+		t.Fatalf("ChDir(..): %v", err)
+	}
+	if img.dir != [2]int{} {
+		t.Errorf("ChDir(..) left dir at %v, want root", img.dir)
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"SUB/INNER", "INNER", false},
+		{"/SUB/INNER", "INNER", false},
+		{"SUB/../ROOTFILE", "ROOTFILE", false},
+		{"INNER", "", true},
+		{"ROOTFILE/INNER", "", true},
+		{"MISSING/INNER", "", true},
+	}
+	for _, tt := range tests {
+		e, err := img.Lookup(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Lookup(%q) error = %v, want error: %v", tt.path, err, tt.wantErr)
+		} else if err == nil && e.Name != tt.want {
+			t.Errorf("Lookup(%q) = %q, want %q", tt.path, e.Name, tt.want)
+		}
+		if img.dir != [2]int{} {
+			t.Fatalf("Lookup(%q) changed the current directory", tt.path)
+		}
+	}
+
+	// A failing ChDir keeps the current directory
+	if err := img.ChDir("SUB"); err != nil {
+		t.Fatalf("ChDir: %v", err)
+	}
+	saved := img.dir
+	if err := img.ChDir("/MISSING"); err == nil {
+		t.Fatal("ChDir(/MISSING) succeeded")
+	}
+	if img.dir != saved {
+		t.Errorf("failed ChDir moved to %v, want %v", img.dir, saved)
+	}
+}
@@ -0,0 +1,169 @@
+package diskimage
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SectorSize is the size of one disk block
+const SectorSize = 256
+
+// Format describes the geometry and layout of a disk image type
+type Format struct {
+	Name           string // "d64", "d71", "d81", "dnp"
+	Tracks         int
+	DirTrack       int // track holding the directory
+	HeaderSector   int // sector with disk name and ID
+	FirstDirSector int // first directory block
+	Interleave     int // sector interleave used for file data
+	DirInterleave  int // sector interleave used for directory blocks
+}
+
+// Supported formats
+var (
+	FormatD64    = &Format{Name: "d64", Tracks: 35, DirTrack: 18, HeaderSector: 0, FirstDirSector: 1, Interleave: 10, DirInterleave: 3}
+	FormatD64Ext = &Format{Name: "d64", Tracks: 40, DirTrack: 18, HeaderSector: 0, FirstDirSector: 1, Interleave: 10, DirInterleave: 3}
+	FormatD71    = &Format{Name: "d71", Tracks: 70, DirTrack: 18, HeaderSector: 0, FirstDirSector: 1, Interleave: 6, DirInterleave: 3}
+	FormatD81    = &Format{Name: "d81", Tracks: 80, DirTrack: 40, HeaderSector: 0, FirstDirSector: 3, Interleave: 1, DirInterleave: 1}
+)
+
+// DNP images (CMD native partitions) have 256 sectors on every track
+const (
+	dnpSectors   = 256
+	dnpMaxTracks = 255
+)
+
+// FormatDNP returns the format of a CMD native partition with the given
+// number of tracks. The header is at 1/1, the BAM at 1/2-1/33 and the root
+// directory starts at 1/34.
+func FormatDNP(tracks int) *Format {
+	return &Format{Name: "dnp", Tracks: tracks, DirTrack: 1, HeaderSector: 1, FirstDirSector: 34, Interleave: 1, DirInterleave: 1}
+}
+
+// SectorsPerTrack returns the number of sectors on a track
+func (f *Format) SectorsPerTrack(track int) int {
+	if track < 1 || track > f.Tracks {
+		return 0
+	}
+
+	switch f.Name {
+	case "dnp":
+		return dnpSectors
+	case "d81":
+		return 40
+	case "d71":
+		if track > 35 {
+			track -= 35
+		}
+	}
+
+	switch {
+	case track <= 17:
+		return 21
+	case track <= 24:
+		return 19
+	case track <= 30:
+		return 18
+	default:
+		return 17
+	}
+}
+
+// TotalSectors returns the number of sectors in the image
+func (f *Format) TotalSectors() int {
+	total := 0
+	for t := 1; t <= f.Tracks; t++ {
+		total += f.SectorsPerTrack(t)
+	}
+	return total
+}
+
+// Size returns the image size in bytes, without error information
+func (f *Format) Size() int {
+	return f.TotalSectors() * SectorSize
+}
+
+// IsSystemTrack reports whether a track is reserved for directory and BAM
+func (f *Format) IsSystemTrack(track int) bool {
+	// Native partitions only reserve single blocks on track 1
+	if f.Name == "dnp" {
+		return false
+	}
+	if track == f.DirTrack {
+		return true
+	}
+	// The 1571 keeps the second side's BAM on track 53
+	return f.Name == "d71" && track == 53
+}
+
+// String returns a description like "d64 (35 tracks)"
+func (f *Format) String() string {
+	return fmt.Sprintf("%s (%d tracks)", f.Name, f.Tracks)
+}
+
+// detectFormat determines the format from the image size.
+// It returns the format and whether the image carries error bytes.
+func detectFormat(size int, ext string) (*Format, bool, error) {
+	for _, f := range []*Format{FormatD64, FormatD64Ext, FormatD71, FormatD81} {
+		if ext != "" && ext != f.Name {
+			continue
+		}
+		sectors := f.TotalSectors()
+		switch size {
+		case sectors * SectorSize:
+			return f, false, nil
+		case sectors * (SectorSize + 1):
+			return f, true, nil
+		}
+	}
+
+	if ext == "" || ext == "dnp" {
+		tracks := size / (dnpSectors * SectorSize)
+		if size%(dnpSectors*SectorSize) == 0 && tracks >= 1 && tracks <= dnpMaxTracks {
+			return FormatDNP(tracks), false, nil
+		}
+	}
+
+	if ext != "" {
+		return nil, false, fmt.Errorf("unexpected %s image size: %d bytes", ext, size)
+	}
+	return nil, false, fmt.Errorf("unrecognised disk image size: %d bytes", size)
+}
+
+// FormatByName returns the format for a type name and track count.
+// A track count of 0 selects the default for the type.
+func FormatByName(name string, tracks int) (*Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "d64":
+		switch tracks {
+		case 0, 35:
+			return FormatD64, nil
+		case 40:
+			return FormatD64Ext, nil
+		}
+		return nil, fmt.Errorf("d64 images have 35 or 40 tracks, not %d", tracks)
+	case "d71":
+		if tracks != 0 && tracks != 70 {
+			return nil, fmt.Errorf("d71 images have 70 tracks, not %d", tracks)
+		}
+		return FormatD71, nil
+	case "d81":
+		if tracks != 0 && tracks != 80 {
+			return nil, fmt.Errorf("d81 images have 80 tracks, not %d", tracks)
+		}
+		return FormatD81, nil
+	case "dnp":
+		if tracks < 1 || tracks > dnpMaxTracks {
+			return nil, fmt.Errorf("dnp images need a track count of 1-%d", dnpMaxTracks)
+		}
+		return FormatDNP(tracks), nil
+	default:
+		return nil, fmt.Errorf("unsupported disk image type %q", name)
+	}
+}
+
+// FormatFromPath returns the default format for a file name extension
+func FormatFromPath(path string) (*Format, error) {
+	return FormatByName(filepath.Ext(path), 0)
+}
@@ -0,0 +1,424 @@
+package diskimage
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// GEOS file types (directory entry byte $18)
+var geosTypeNames = []string{
+	"Non-GEOS", "BASIC", "Assembler", "Data", "System", "Desk Accessory",
+	"Application", "Application Data", "Font", "Printer Driver",
+	"Input Driver", "Disk Driver", "System Boot", "Temporary",
+	"Auto-Execute", "Input 128",
+}
+
+// GEOSTypeName returns the name of a GEOS file type
+func GEOSTypeName(t int) string {
+	if t >= 0 && t < len(geosTypeNames) {
+		return geosTypeNames[t]
+	}
+	return fmt.Sprintf("Unknown (%d)", t)
+}
+
+// Signatures that follow the directory entry in the first CVT block
+const (
+	cvtSignatureVLIR = "PRG formatted GEOS file V1.0"
+	cvtSignatureSeq  = "SEQ formatted GEOS file V1.0"
+	cvtBlock         = 254
+	vlirMaxRecords   = 127
+)
+
+// GEOSInfo is the decoded GEOS info block of a file
+type GEOSInfo struct {
+	FileType     int          `json:"geos_type"`
+	FileTypeName string       `json:"geos_type_name"`
+	Structure    string       `json:"structure"`
+	ClassName    string       `json:"class_name"`
+	Author       string       `json:"author,omitempty"`
+	Parent       string       `json:"parent,omitempty"`
+	Description  string       `json:"description,omitempty"`
+	LoadAddress  int          `json:"load_address"`
+	EndAddress   int          `json:"end_address"`
+	StartAddress int          `json:"start_address"`
+	Date         string       `json:"date,omitempty"`
+	InfoTrack    int          `json:"info_track"`
+	InfoSector   int          `json:"info_sector"`
+	Icon         []byte       `json:"-"`
+	Records      []VLIRRecord `json:"records,omitempty"`
+}
+
+// VLIRRecord is one entry of a VLIR index block
+type VLIRRecord struct {
+	Index  int  `json:"index"`
+	Empty  bool `json:"empty,omitempty"`
+	Track  int  `json:"track,omitempty"`
+	Sector int  `json:"sector,omitempty"`
+	Blocks int  `json:"blocks,omitempty"`
+	Size   int  `json:"size,omitempty"`
+}
+
+// IsGEOS reports whether the entry describes a GEOS file
+func (e *DirEntry) IsGEOS() bool {
+	return e.GEOSType != 0
+}
+
+// entryBytes returns the 32 raw bytes of a directory entry
+func (img *Image) entryBytes(e *DirEntry) []byte {
+	return img.mustSector(e.EntryTrack, e.EntrySector)[e.EntryOffset : e.EntryOffset+entrySize]
+}
+
+// GEOSInfo reads the info block and, for VLIR files, the record index
+func (img *Image) GEOSInfo(e *DirEntry) (*GEOSInfo, error) {
+	if !e.IsGEOS() {
+		return nil, fmt.Errorf("%s is not a GEOS file", e.Name)
+	}
+
+	info, err := img.Sector(e.InfoTrack, e.InfoSector)
+	if err != nil {
+		return nil, fmt.Errorf("bad info block: %w", err)
+	}
+
+	g := &GEOSInfo{
+		FileType:     e.GEOSType,
+		FileTypeName: GEOSTypeName(e.GEOSType),
+		Structure:    "sequential",
+		ClassName:    geosString(info[0x4D:0x61]),
+		Author:       geosString(info[0x61:0x75]),
+		Parent:       geosString(info[0x75:0x89]),
+		Description:  geosString(info[0xA0:]),
+		LoadAddress:  int(info[0x47]) | int(info[0x48])<<8,
+		EndAddress:   int(info[0x49]) | int(info[0x4A])<<8,
+		StartAddress: int(info[0x4B]) | int(info[0x4C])<<8,
+		InfoTrack:    e.InfoTrack,
+		InfoSector:   e.InfoSector,
+		Icon:         append([]byte(nil), info[0x05:0x44]...),
+	}
+
+	raw := img.entryBytes(e)
+	if raw[25] != 0 || raw[26] != 0 {
+		g.Date = fmt.Sprintf("%04d-%02d-%02d %02d:%02d",
+			1900+int(raw[25]), raw[26], raw[27], raw[28], raw[29])
+	}
+
+	if e.VLIR {
+		g.Structure = "VLIR"
+		g.Records, err = img.vlirRecords(e)
+		if err != nil {
+			return g, err
+		}
+	}
+
+	return g, nil
+}
+
+// IconRows renders the 24x21 icon as text, '#' for set pixels
+func (g *GEOSInfo) IconRows() []string {
+	var rows []string
+	for y := 0; y < 21 && y*3+2 < len(g.Icon); y++ {
+		var sb strings.Builder
+		for x := 0; x < 24; x++ {
+			if g.Icon[y*3+x/8]&(0x80>>uint(x%8)) != 0 {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte(' ')
+			}
+		}
+		rows = append(rows, sb.String())
+	}
+	return rows
+}
+
+// geosString decodes a zero-terminated ASCII string from an info block
+func geosString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7E {
+			return -1
+		}
+		return r
+	}, string(b)))
+}
+
+// vlirRecords decodes the VLIR index block of a file
+func (img *Image) vlirRecords(e *DirEntry) ([]VLIRRecord, error) {
+	index, err := img.Sector(e.Track, e.Sector)
+	if err != nil {
+		return nil, fmt.Errorf("bad VLIR index block: %w", err)
+	}
+
+	var records []VLIRRecord
+	for i := 0; i < vlirMaxRecords; i++ {
+		t, s := int(index[2+2*i]), int(index[3+2*i])
+		if t == 0 && s == 0 {
+			break
+		}
+		if t == 0 {
+			records = append(records, VLIRRecord{Index: i, Empty: true})
+			continue
+		}
+
+		chain, err := img.ChainSectors(t, s)
+		if err != nil {
+			return records, fmt.Errorf("record %d: %w", i, err)
+		}
+		last := img.mustSector(chain[len(chain)-1][0], chain[len(chain)-1][1])
+		records = append(records, VLIRRecord{
+			Index:  i,
+			Track:  t,
+			Sector: s,
+			Blocks: len(chain),
+			Size:   (len(chain)-1)*cvtBlock + lastBlockBytes(last),
+		})
+	}
+
+	return records, nil
+}
+
+// lastBlockBytes returns the number of data bytes in the last block of a chain
+func lastBlockBytes(sec []byte) int {
+	if sec[1] < 2 {
+		return 1
+	}
+	return int(sec[1]) - 1
+}
+
+// FileSectors returns every block a file occupies: for GEOS files the info
+// block and, for VLIR files, the index block and all record chains
+func (img *Image) FileSectors(e *DirEntry) ([][2]int, error) {
+	if e.Track == 0 || !e.IsFile() {
+		return nil, nil
+	}
+	if !e.IsGEOS() {
+		return img.ChainSectors(e.Track, e.Sector)
+	}
+
+	sectors := [][2]int{{e.InfoTrack, e.InfoSector}}
+	if !e.VLIR {
+		chain, err := img.ChainSectors(e.Track, e.Sector)
+		return append(sectors, chain...), err
+	}
+
+	sectors = append(sectors, [2]int{e.Track, e.Sector})
+	records, err := img.vlirRecords(e)
+	for _, r := range records {
+		if r.Empty {
+			continue
+		}
+		chain, _ := img.ChainSectors(r.Track, r.Sector)
+		sectors = append(sectors, chain...)
+	}
+	return sectors, err
+}
+
+// IsCVT reports whether data is a GEOS file in Convert (CVT) format
+func IsCVT(data []byte) bool {
+	if len(data) < 2*cvtBlock {
+		return false
+	}
+	return bytes.Contains(data[30:cvtBlock], []byte("formatted GEOS file"))
+}
+
+// CVTName returns the raw file name stored in a CVT file's directory entry
+func CVTName(data []byte) []byte {
+	if !IsCVT(data) {
+		return nil
+	}
+	return TrimName(data[3:19])
+}
+
+// ExportCVT converts a GEOS file to Convert format: the directory entry
+// and signature, the info block, for VLIR files the record table with
+// block counts, then the file data in whole blocks
+func (img *Image) ExportCVT(e *DirEntry) ([]byte, error) {
+	if !e.IsGEOS() {
+		return nil, fmt.Errorf("%s is not a GEOS file", e.Name)
+	}
+
+	info, err := img.Sector(e.InfoTrack, e.InfoSector)
+	if err != nil {
+		return nil, fmt.Errorf("bad info block: %w", err)
+	}
+
+	var buf bytes.Buffer
+	header := make([]byte, cvtBlock)
+	copy(header, img.entryBytes(e)[2:])
+	if e.VLIR {
+		copy(header[30:], cvtSignatureVLIR)
+	} else {
+		copy(header[30:], cvtSignatureSeq)
+	}
+	buf.Write(header)
+	buf.Write(info[2:])
+
+	if !e.VLIR {
+		data, err := img.ReadChain(e.Track, e.Sector)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		return buf.Bytes(), nil
+	}
+
+	records, err := img.vlirRecords(e)
+	if err != nil {
+		return nil, err
+	}
+
+	table := make([]byte, cvtBlock)
+	var body bytes.Buffer
+	for _, r := range records {
+		if r.Empty {
+			table[2*r.Index], table[2*r.Index+1] = 0, 0xFF
+			continue
+		}
+
+		data, err := img.ReadChain(r.Track, r.Sector)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", r.Index, err)
+		}
+		// The record table holds the block count in a single byte
+		if r.Blocks > 255 {
+			return nil, fmt.Errorf("record %d: %d blocks do not fit in a CVT record table (max 255)", r.Index, r.Blocks)
+		}
+		table[2*r.Index] = byte(r.Blocks)
+		table[2*r.Index+1] = byte(r.Size - (r.Blocks-1)*cvtBlock + 1)
+
+		padded := make([]byte, r.Blocks*cvtBlock)
+		copy(padded, data)
+		body.Write(padded)
+	}
+
+	buf.Write(table)
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+// ImportCVT writes a Convert format GEOS file into the image. name
+// replaces the file name stored in the CVT when set.
+func (img *Image) ImportCVT(data, name []byte) (*DirEntry, error) {
+	if !IsCVT(data) {
+		return nil, fmt.Errorf("not a CVT file")
+	}
+
+	entry := data[:30]
+	info := data[cvtBlock : 2*cvtBlock]
+	vlir := entry[21] == 1
+	if name == nil {
+		name = CVTName(data)
+	}
+	if len(name) > 16 {
+		return nil, fmt.Errorf("file name too long (%d > 16 characters)", len(name))
+	}
+
+	// Split the payload into records before anything is allocated
+	var records [][]byte
+	empty := make(map[int]bool)
+	if vlir {
+		if len(data) < 3*cvtBlock {
+			return nil, fmt.Errorf("truncated CVT file: missing VLIR record table")
+		}
+		table := data[2*cvtBlock : 3*cvtBlock]
+		offset := 3 * cvtBlock
+		for i := 0; i < vlirMaxRecords; i++ {
+			blocks, last := int(table[2*i]), int(table[2*i+1])
+			if blocks == 0 && last == 0 {
+				break
+			}
+			if blocks == 0 {
+				empty[i] = true
+				records = append(records, nil)
+				continue
+			}
+
+			size := (blocks-1)*cvtBlock + last - 1
+			if last < 2 || offset+size > len(data) {
+				return nil, fmt.Errorf("truncated CVT file: record %d", i)
+			}
+			records = append(records, data[offset:offset+size])
+			offset += blocks * cvtBlock
+		}
+	}
+
+	// Everything allocated is released again if the disk or directory
+	// turns out to be full
+	var allocated [][2]int
+	allocate := func(n int) ([][2]int, error) {
+		chain, err := img.allocateChain(n)
+		allocated = append(allocated, chain...)
+		return chain, err
+	}
+
+	infoChain, err := allocate(1)
+	if err != nil {
+		img.freeChain(allocated)
+		return nil, err
+	}
+	infoBlock := img.mustSector(infoChain[0][0], infoChain[0][1])
+	infoBlock[0], infoBlock[1] = 0, 0xFF
+	copy(infoBlock[2:], info)
+	total := 1
+
+	var start [2]int
+	if vlir {
+		indexChain, err := allocate(1)
+		if err != nil {
+			img.freeChain(allocated)
+			return nil, err
+		}
+		start = indexChain[0]
+		total++
+
+		index := make([]byte, SectorSize)
+		index[1] = 0xFF
+		for i, rec := range records {
+			if empty[i] {
+				index[2+2*i], index[3+2*i] = 0, 0xFF
+				continue
+			}
+			chain, err := allocate((len(rec) + 253) / 254)
+			if err != nil {
+				img.freeChain(allocated)
+				return nil, err
+			}
+			img.writeChain(chain, rec)
+			index[2+2*i], index[3+2*i] = byte(chain[0][0]), byte(chain[0][1])
+			total += len(chain)
+		}
+		copy(img.mustSector(start[0], start[1]), index)
+	} else {
+		payload := data[2*cvtBlock:]
+		blocks := (len(payload) + 253) / 254
+		if blocks == 0 {
+			blocks = 1
+		}
+		chain, err := allocate(blocks)
+		if err != nil {
+			img.freeChain(allocated)
+			return nil, err
+		}
+		img.writeChain(chain, payload)
+		start = chain[0]
+		total += len(chain)
+	}
+
+	slotTrack, slotSector, slotOff, err := img.freeDirSlot()
+	if err != nil {
+		img.freeChain(allocated)
+		return nil, err
+	}
+
+	raw := img.mustSector(slotTrack, slotSector)[slotOff : slotOff+entrySize]
+	copy(raw[2:], entry)
+	raw[2] |= flagClosed
+	raw[3], raw[4] = byte(start[0]), byte(start[1])
+	copy(raw[5:21], PadName(name))
+	raw[21], raw[22] = byte(infoChain[0][0]), byte(infoChain[0][1])
+	raw[30], raw[31] = byte(total), byte(total>>8)
+
+	e := parseEntry(raw, -1, slotTrack, slotSector, slotOff)
+	return &e, nil
+}
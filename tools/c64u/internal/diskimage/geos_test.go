@@ -0,0 +1,183 @@
+package diskimage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+)
+
+// buildCVT assembles a CVT file. With records set the file is VLIR (nil
+// records are empty), otherwise payload is the sequential file data.
+func buildCVT(name string, records [][]byte, payload []byte) []byte {
+	header := make([]byte, cvtBlock)
+	header[0] = flagClosed | TypeUSR
+	copy(header[3:19], PadName(petscii.FromASCII(name)))
+	header[22] = 6 // Application
+	copy(header[23:28], []byte{88, 7, 14, 12, 30})
+	if records != nil {
+		header[21] = 1
+		copy(header[30:], cvtSignatureVLIR)
+	} else {
+		copy(header[30:], cvtSignatureSeq)
+	}
+
+	info := make([]byte, cvtBlock)
+	info[0], info[1], info[2] = 3, 21, 0xBF
+	copy(info[0x4B:], "Test Class  V1.0")
+
+	out := append(header, info...)
+	if records == nil {
+		return append(out, payload...)
+	}
+
+	table := make([]byte, cvtBlock)
+	var body []byte
+	for i, r := range records {
+		if r == nil {
+			table[2*i], table[2*i+1] = 0, 0xFF
+			continue
+		}
+		blocks := (len(r) + cvtBlock - 1) / cvtBlock
+		table[2*i] = byte(blocks)
+		table[2*i+1] = byte(len(r) - (blocks-1)*cvtBlock + 1)
+		padded := make([]byte, blocks*cvtBlock)
+		copy(padded, r)
+		body = append(body, padded...)
+	}
+	return append(append(out, table...), body...)
+}
+
+// pattern returns n bytes of recognisable data
+func pattern(n int, seed byte) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = seed + byte(i*7)
+	}
+	return data
+}
+
+func TestCVTRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		cvt     []byte
+		blocks  int
+		records int
+	}{
+		{
+			name:   "sequential",
+			cvt:    buildCVT("SEQ APP", nil, pattern(600, 1)),
+			blocks: 1 + 3,
+		},
+		{
+			name:    "vlir",
+			cvt:     buildCVT("VLIR APP", [][]byte{pattern(300, 2), nil, pattern(254, 3), pattern(1, 4)}, nil),
+			blocks:  1 + 1 + 2 + 1 + 1,
+			records: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := newImage(t, "d64", 35)
+			free := img.FreeBlocks()
+
+			if !IsCVT(tt.cvt) {
+				t.Fatal("IsCVT = false")
+			}
+			e, err := img.ImportCVT(tt.cvt, nil)
+			if err != nil {
+				t.Fatalf("ImportCVT: %v", err)
+			}
+			if e.Blocks != tt.blocks || img.FreeBlocks() != free-tt.blocks {
+				t.Errorf("blocks = %d, free %d -> %d, want %d blocks", e.Blocks, free, img.FreeBlocks(), tt.blocks)
+			}
+			if DisplayName(CVTName(tt.cvt)) != e.Name || !e.IsGEOS() || e.VLIR != (tt.records > 0) {
+				t.Errorf("entry = %+v", e)
+			}
+
+			info, err := img.GEOSInfo(e)
+			if err != nil {
+				t.Fatalf("GEOSInfo: %v", err)
+			}
+			if info.FileType != 6 || !strings.HasPrefix(info.ClassName, "Test Class") || len(info.Records) != tt.records {
+				t.Errorf("info = %+v", info)
+			}
+
+			out, err := img.ExportCVT(e)
+			if err != nil {
+				t.Fatalf("ExportCVT: %v", err)
+			}
+			// The entry's block pointers differ; signature, info block,
+			// record table and data must match
+			if !bytes.Equal(out[30:], tt.cvt[30:]) {
+				t.Errorf("exported CVT differs (%d vs %d bytes)", len(out), len(tt.cvt))
+			}
+
+			sectors, err := img.FileSectors(e)
+			if err != nil || len(sectors) != tt.blocks {
+				t.Errorf("FileSectors = %d sectors (%v), want %d", len(sectors), err, tt.blocks)
+			}
+			if err := img.DeleteFile(e); err != nil {
+				t.Fatalf("DeleteFile: %v", err)
+			}
+			if img.FreeBlocks() != free {
+				t.Errorf("FreeBlocks after delete = %d, want %d", img.FreeBlocks(), free)
+			}
+		})
+	}
+}
+
+func TestImportCVTErrors(t *testing.T) {
+	vlir := buildCVT("APP", [][]byte{pattern(600, 1)}, nil)
+
+	tests := []struct {
+		name string
+		data []byte
+		file []byte
+	}{
+		{"not a CVT", make([]byte, 1000), nil},
+		{"truncated record", vlir[:len(vlir)-300], nil},
+		{"name too long", vlir, bytes.Repeat([]byte{'A'}, 17)},
+		{"disk full", buildCVT("BIG", nil, make([]byte, 700*254)), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := newImage(t, "d64", 35)
+
+			if _, err := img.ImportCVT(tt.data, tt.file); err == nil {
+				t.Fatal("ImportCVT succeeded, want error")
+			}
+			// Blocks allocated before the failure are released again
+			if img.FreeBlocks() != 664 {
+				t.Errorf("FreeBlocks = %d, want 664", img.FreeBlocks())
+			}
+			if entries, _ := img.Directory(); len(entries) != 0 {
+				t.Errorf("directory = %v, want empty", entries)
+			}
+		})
+	}
+}
+
+func TestExportCVTRecordLimit(t *testing.T) {
+	img := newImage(t, "d64", 35)
+	e, err := img.ImportCVT(buildCVT("APP", [][]byte{pattern(10, 1)}, nil), nil)
+	if err != nil {
+		t.Fatalf("ImportCVT: %v", err)
+	}
+
+	// Replace record 0 with a chain whose block count does not fit in a byte
+	chain, err := img.allocateChain(256)
+	if err != nil {
+		t.Fatalf("allocateChain: %v", err)
+	}
+	img.writeChain(chain, pattern(256*cvtBlock, 5))
+	index := img.mustSector(e.Track, e.Sector)
+	index[2], index[3] = byte(chain[0][0]), byte(chain[0][1])
+
+	if _, err := img.ExportCVT(e); err == nil || !strings.Contains(err.Error(), "max 255") {
+		t.Errorf("ExportCVT error = %v, want record size error", err)
+	}
+}
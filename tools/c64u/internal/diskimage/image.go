@@ -0,0 +1,280 @@
+package diskimage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Image is a disk image held in memory
+type Image struct {
+	Format *Format
+
+	data      []byte
+	errorInfo []byte
+
+	// header block of the current subdirectory (DNP only), zero for the root
+	dir [2]int
+}
+
+// Open reads a disk image from disk. The format is detected from the
+// file extension and size.
+func Open(path string) (*Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	switch ext {
+	case "d64", "d71", "d81", "dnp":
+	default:
+		ext = ""
+	}
+
+	return Parse(data, ext)
+}
+
+// Parse wraps raw image data. ext may be empty to detect by size only.
+func Parse(data []byte, ext string) (*Image, error) {
+	f, hasErrors, err := detectFormat(len(data), ext)
+	if err != nil {
+		return nil, err
+	}
+
+	img := &Image{
+		Format: f,
+		data:   append([]byte(nil), data[:f.Size()]...),
+	}
+	if hasErrors {
+		img.errorInfo = append([]byte(nil), data[f.Size():]...)
+	}
+
+	return img, nil
+}
+
+// Bytes returns the complete image contents including error information
+func (img *Image) Bytes() []byte {
+	out := make([]byte, 0, len(img.data)+len(img.errorInfo))
+	out = append(out, img.data...)
+	return append(out, img.errorInfo...)
+}
+
+// Save writes the image to a file
+func (img *Image) Save(path string) error {
+	if err := os.WriteFile(path, img.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+	return nil
+}
+
+// Offset returns the byte offset of a sector within the image
+func (img *Image) Offset(track, sector int) (int, error) {
+	spt := img.Format.SectorsPerTrack(track)
+	if spt == 0 {
+		return 0, fmt.Errorf("illegal track %d", track)
+	}
+	if sector < 0 || sector >= spt {
+		return 0, fmt.Errorf("illegal sector %d on track %d", sector, track)
+	}
+
+	offset := 0
+	for t := 1; t < track; t++ {
+		offset += img.Format.SectorsPerTrack(t)
+	}
+	return (offset + sector) * SectorSize, nil
+}
+
+// Sector returns the 256 bytes of a sector. The slice aliases the image,
+// so writes to it modify the image.
+func (img *Image) Sector(track, sector int) ([]byte, error) {
+	offset, err := img.Offset(track, sector)
+	if err != nil {
+		return nil, err
+	}
+	return img.data[offset : offset+SectorSize], nil
+}
+
+// mustSector returns a sector that is known to exist in the format
+func (img *Image) mustSector(track, sector int) []byte {
+	s, err := img.Sector(track, sector)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// header returns the sector holding the disk name and ID
+func (img *Image) header() []byte {
+	return img.mustSector(img.Format.DirTrack, img.Format.HeaderSector)
+}
+
+// nameOffsets returns the offsets of the disk name and ID in the header sector
+func (img *Image) nameOffsets() (name, id int) {
+	switch img.Format.Name {
+	case "d81", "dnp":
+		return 0x04, 0x16
+	}
+	return 0x90, 0xA2
+}
+
+// DiskName returns the raw PETSCII disk name without padding
+func (img *Image) DiskName() []byte {
+	off, _ := img.nameOffsets()
+	return TrimName(img.header()[off : off+16])
+}
+
+// DiskID returns the two-character disk ID
+func (img *Image) DiskID() []byte {
+	_, off := img.nameOffsets()
+	return append([]byte(nil), img.header()[off:off+2]...)
+}
+
+// DOSType returns the two-character DOS type following the disk ID
+func (img *Image) DOSType() []byte {
+	_, off := img.nameOffsets()
+	return append([]byte(nil), img.header()[off+3:off+5]...)
+}
+
+// SetDiskName sets the disk name (padded with shifted spaces)
+func (img *Image) SetDiskName(name []byte) {
+	off, _ := img.nameOffsets()
+	copy(img.header()[off:off+16], PadName(name))
+}
+
+// SetDiskID sets the two-character disk ID
+func (img *Image) SetDiskID(id []byte) {
+	_, off := img.nameOffsets()
+	padded := PadName(id)[:2]
+	copy(img.header()[off:off+2], padded)
+
+	// The 1581 keeps a copy of the ID in both BAM blocks, native
+	// partitions in the first one
+	switch img.Format.Name {
+	case "d81":
+		for s := 1; s <= 2; s++ {
+			copy(img.mustSector(40, s)[4:6], padded)
+		}
+	case "dnp":
+		copy(img.mustSector(1, 2)[4:6], padded)
+	}
+}
+
+// New creates a freshly formatted, empty disk image
+func New(f *Format, name, id []byte) *Image {
+	img := &Image{
+		Format: f,
+		data:   make([]byte, f.Size()),
+	}
+
+	switch f.Name {
+	case "d81":
+		img.formatD81()
+	case "dnp":
+		img.formatDNP()
+	default:
+		img.formatD64()
+	}
+
+	img.SetDiskName(name)
+	img.SetDiskID(id)
+	return img
+}
+
+// formatD64 writes the BAM and an empty directory for D64 and D71 images
+func (img *Image) formatD64() {
+	f := img.Format
+	bam := img.mustSector(f.DirTrack, 0)
+	bam[0], bam[1] = byte(f.DirTrack), byte(f.FirstDirSector)
+	bam[2] = 'A'
+	if f.Name == "d71" {
+		bam[3] = 0x80
+	}
+	for i := 0x90; i <= 0xAA; i++ {
+		bam[i] = 0xA0
+	}
+	bam[0xA5], bam[0xA6] = '2', 'A'
+
+	for t := 1; t <= f.Tracks; t++ {
+		for s := 0; s < f.SectorsPerTrack(t); s++ {
+			img.setFree(t, s, true)
+		}
+	}
+
+	if f.Name == "d71" {
+		for s := 0; s < f.SectorsPerTrack(53); s++ {
+			img.setFree(53, s, false)
+		}
+	}
+
+	img.setFree(f.DirTrack, 0, false)
+	img.setFree(f.DirTrack, f.FirstDirSector, false)
+
+	dir := img.mustSector(f.DirTrack, f.FirstDirSector)
+	dir[1] = 0xFF
+}
+
+// formatD81 writes the header, both BAM blocks and an empty directory
+func (img *Image) formatD81() {
+	f := img.Format
+	hdr := img.mustSector(40, 0)
+	hdr[0], hdr[1] = 40, 3
+	hdr[2] = 'D'
+	for i := 0x04; i <= 0x1C; i++ {
+		hdr[i] = 0xA0
+	}
+	hdr[0x19], hdr[0x1A] = '3', 'D'
+
+	for i, next := range [][2]byte{{40, 2}, {0, 0xFF}} {
+		bam := img.mustSector(40, 1+i)
+		bam[0], bam[1] = next[0], next[1]
+		bam[2] = 'D'
+		bam[3] = 0xBB
+		bam[6] = 0xC0
+	}
+
+	for t := 1; t <= f.Tracks; t++ {
+		for s := 0; s < f.SectorsPerTrack(t); s++ {
+			img.setFree(t, s, true)
+		}
+	}
+	for s := 0; s <= 3; s++ {
+		img.setFree(40, s, false)
+	}
+
+	dir := img.mustSector(40, 3)
+	dir[1] = 0xFF
+}
+
+// formatDNP writes the header, the BAM blocks and an empty root directory
+// of a CMD native partition. Track 1 sectors 0-34 are reserved.
+func (img *Image) formatDNP() {
+	f := img.Format
+	hdr := img.mustSector(1, 1)
+	hdr[0], hdr[1] = 1, 34
+	hdr[2] = 'H'
+	for i := 0x04; i <= 0x1C; i++ {
+		hdr[i] = 0xA0
+	}
+	hdr[0x19], hdr[0x1A] = '1', 'H'
+	hdr[0x20], hdr[0x21] = 1, 1
+
+	bam := img.mustSector(1, 2)
+	bam[2] = 'H'
+	bam[3] = 0xB7
+	bam[6] = 0xC0
+	bam[8] = byte(f.Tracks)
+
+	for t := 1; t <= f.Tracks; t++ {
+		for s := 0; s < f.SectorsPerTrack(t); s++ {
+			img.setFree(t, s, true)
+		}
+	}
+	for s := 0; s <= 34; s++ {
+		img.setFree(1, s, false)
+	}
+
+	dir := img.mustSector(1, 34)
+	dir[1] = 0xFF
+}
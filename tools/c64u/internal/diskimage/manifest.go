@@ -0,0 +1,332 @@
+package diskimage
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+)
+
+// ManifestFile is the name of the sidecar file written by Unpack
+const ManifestFile = "manifest.json"
+
+// Manifest records everything needed to rebuild an image from a folder:
+// disk header, directory order, CBM file types, flags and raw PETSCII names
+type Manifest struct {
+	Format   string          `json:"format"`
+	Tracks   int             `json:"tracks"`
+	DiskName string          `json:"disk_name"`
+	RawName  string          `json:"disk_name_bytes"`
+	DiskID   string          `json:"disk_id_bytes"`
+	Files    []ManifestEntry `json:"files"`
+	Skipped  []string        `json:"skipped,omitempty"`
+}
+
+// ManifestEntry describes one file of the image
+type ManifestEntry struct {
+	File    string `json:"file"`
+	Name    string `json:"name"`
+	RawName string `json:"name_bytes"`
+	Type    string `json:"type"`
+	Locked  bool   `json:"locked,omitempty"`
+	Splat   bool   `json:"splat,omitempty"`
+	GEOS    bool   `json:"geos,omitempty"` // file is stored in CVT format
+}
+
+// Unpack writes every file of the image into dir and saves a manifest
+// next to them. Files are named after their CBM name and type; GEOS files
+// are written in CVT format.
+func Unpack(img *Image, dir string) (*Manifest, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	entries, err := img.Directory()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Format:   img.Format.Name,
+		Tracks:   img.Format.Tracks,
+		DiskName: DisplayName(img.DiskName()),
+		RawName:  hex.EncodeToString(img.DiskName()),
+		DiskID:   hex.EncodeToString(img.DiskID()),
+	}
+
+	used := map[string]bool{strings.ToLower(ManifestFile): true}
+	for i := range entries {
+		e := &entries[i]
+		switch e.FileType() {
+		case TypeCBM:
+			// Partitions are sector ranges, not sector chains
+			m.Skipped = append(m.Skipped, fmt.Sprintf("%s (CBM partition)", e.Name))
+			continue
+		case TypeDIR:
+			m.Skipped = append(m.Skipped, fmt.Sprintf("%s (subdirectory)", e.Name))
+			continue
+		case TypeREL:
+			// Pack cannot write side sectors, so a REL file would break
+			// the round trip
+			m.Skipped = append(m.Skipped, fmt.Sprintf("%s (REL files cannot be packed)", e.Name))
+			continue
+		}
+
+		var data []byte
+		ext := e.Type
+		switch {
+		case e.IsGEOS():
+			data, err = img.ExportCVT(e)
+			ext = "cvt"
+		case e.Track != 0:
+			data, err = img.ReadFile(e)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name, err)
+		}
+
+		fileName := LocalFileName(e.Name, ext, used)
+		if err := os.WriteFile(filepath.Join(dir, fileName), data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", fileName, err)
+		}
+
+		m.Files = append(m.Files, ManifestEntry{
+			File:    fileName,
+			Name:    e.Name,
+			RawName: hex.EncodeToString(e.RawName),
+			Type:    e.Type,
+			Locked:  e.Locked,
+			Splat:   !e.Closed,
+			GEOS:    e.IsGEOS(),
+		})
+	}
+
+	if err := m.Save(filepath.Join(dir, ManifestFile)); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Save writes the manifest as indented JSON
+func (m *Manifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// LoadManifest reads a manifest file
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// PackOptions control how a folder is turned into an image
+type PackOptions struct {
+	Format   *Format // required
+	DiskName string  // overrides the manifest when set
+	DiskID   string  // overrides the manifest when set
+}
+
+// Pack builds an image from a folder. If the folder has a manifest, its
+// header, directory order, file types, flags and raw names are used.
+// Files without a manifest entry are added afterwards in name order, with
+// the type taken from their extension; .cvt files are imported as GEOS files.
+func Pack(dir string, opts PackOptions) (*Image, *Manifest, error) {
+	m, err := LoadManifest(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, nil, err
+		}
+		m = &Manifest{DiskName: strings.ToUpper(filepath.Base(filepath.Clean(dir)))}
+	}
+
+	name, err := decodeRaw(m.RawName, m.DiskName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("disk name: %w", err)
+	}
+	if opts.DiskName != "" {
+		name = petscii.FromASCII(opts.DiskName)
+	}
+	if len(name) > 16 {
+		name = name[:16]
+	}
+
+	id, err := decodeRaw(m.DiskID, "00")
+	if err != nil {
+		return nil, nil, fmt.Errorf("disk ID: %w", err)
+	}
+	if opts.DiskID != "" {
+		id = petscii.FromASCII(opts.DiskID)
+	}
+
+	img := New(opts.Format, name, id)
+
+	files, err := m.withUnlistedFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.Files = files
+
+	for _, f := range m.Files {
+		if err := packEntry(img, dir, f); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", f.File, err)
+		}
+	}
+
+	m.Format = img.Format.Name
+	m.Tracks = img.Format.Tracks
+	m.DiskName = DisplayName(name)
+	m.RawName = hex.EncodeToString(name)
+	m.DiskID = hex.EncodeToString(img.DiskID())
+
+	return img, m, nil
+}
+
+// packEntry adds one manifest entry to the image
+func packEntry(img *Image, dir string, f ManifestEntry) error {
+	// The manifest may come from elsewhere; don't let it pull in files
+	// outside the directory being packed
+	if !filepath.IsLocal(f.File) {
+		return fmt.Errorf("path must stay inside the packed directory")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, f.File))
+	if err != nil {
+		return err
+	}
+
+	if f.GEOS {
+		// Type, flags and GEOS fields come from the CVT directory entry
+		var name []byte
+		if f.Name != "" || f.RawName != "" {
+			if name, err = decodeRaw(f.RawName, f.Name); err != nil {
+				return err
+			}
+		}
+		_, err = img.ImportCVT(data, name)
+		return err
+	}
+
+	fileType, err := ParseTypeName(f.Type)
+	if err != nil {
+		return err
+	}
+
+	name, err := decodeRaw(f.RawName, f.Name)
+	if err != nil {
+		return err
+	}
+
+	_, err = img.AddFile(NewFile{
+		Name:   name,
+		Type:   fileType,
+		Locked: f.Locked,
+		Closed: !f.Splat,
+		Data:   data,
+	})
+	return err
+}
+
+// withUnlistedFiles appends entries for files in dir the manifest does not mention
+func (m *Manifest) withUnlistedFiles(dir string) ([]ManifestEntry, error) {
+	listed := make(map[string]bool)
+	for _, f := range m.Files {
+		listed[f.File] = true
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var extra []string
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || listed[name] || name == ManifestFile || strings.HasPrefix(name, ".") {
+			continue
+		}
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+
+	files := append([]ManifestEntry(nil), m.Files...)
+	for _, name := range extra {
+		if strings.EqualFold(filepath.Ext(name), ".cvt") {
+			// The CBM name is taken from the CVT header
+			files = append(files, ManifestEntry{File: name, GEOS: true})
+			continue
+		}
+
+		base := strings.TrimSuffix(name, filepath.Ext(name))
+		if len(base) > 16 {
+			base = base[:16]
+		}
+		fileType := TypePRG
+		if t, err := ParseTypeName(strings.TrimPrefix(filepath.Ext(name), ".")); err == nil {
+			fileType = t
+		}
+		files = append(files, ManifestEntry{
+			File: name,
+			Name: strings.ToUpper(base),
+			Type: TypeName(fileType),
+		})
+	}
+
+	return files, nil
+}
+
+// decodeRaw returns the hex-encoded raw bytes, or the PETSCII form of the
+// fallback text when no raw bytes are recorded
+func decodeRaw(rawHex, fallback string) ([]byte, error) {
+	if rawHex == "" {
+		return petscii.FromASCII(fallback), nil
+	}
+	return hex.DecodeString(rawHex)
+}
+
+// LocalFileName derives a unique, file-system safe name like "game.prg"
+// from a CBM name and type. used tracks names already taken.
+func LocalFileName(name, fileType string, used map[string]bool) string {
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.TrimRight(name, " "))
+	base = strings.Trim(base, ".")
+	if base == "" {
+		base = "unnamed"
+	}
+
+	ext := "." + strings.ToLower(fileType)
+	candidate := base + ext
+	for n := 2; used[strings.ToLower(candidate)]; n++ {
+		candidate = fmt.Sprintf("%s_%d%s", base, n, ext)
+	}
+	used[strings.ToLower(candidate)] = true
+
+	return candidate
+}
@@ -0,0 +1,56 @@
+package memdump
+
+// Area is a named region of the C64 memory map
+type Area struct {
+	Start int
+	End   int
+	Name  string
+}
+
+// Areas is the default C64 memory map, most specific regions first
+var Areas = []Area{
+	{0x0000, 0x0001, "CPU port"},
+	{0x0002, 0x00FF, "zero page"},
+	{0x0100, 0x01FF, "stack"},
+	{0x0200, 0x03FF, "OS work area"},
+	{0x0400, 0x07E7, "screen RAM"},
+	{0x07F8, 0x07FF, "sprite pointers"},
+	{0x0800, 0x9FFF, "BASIC RAM"},
+	{0xA000, 0xBFFF, "BASIC ROM / RAM"},
+	{0xC000, 0xCFFF, "upper RAM"},
+	{0xD000, 0xD3FF, "VIC-II"},
+	{0xD400, 0xD7FF, "SID"},
+	{0xD800, 0xDBFF, "colour RAM"},
+	{0xDC00, 0xDCFF, "CIA 1"},
+	{0xDD00, 0xDDFF, "CIA 2"},
+	{0xDE00, 0xDFFF, "I/O expansion"},
+	{0xE000, 0xFFF9, "KERNAL ROM / RAM"},
+	{0xFFFA, 0xFFFF, "CPU vectors"},
+}
+
+// I/O area bounds
+const (
+	IOStart = 0xD000
+	IOEnd   = 0xDFFF
+)
+
+// AreaName returns the memory map region an address belongs to
+func AreaName(addr int) string {
+	for _, a := range Areas {
+		if addr >= a.Start && addr <= a.End {
+			return a.Name
+		}
+	}
+	return ""
+}
+
+// RangeAreas lists the memory map regions a range touches
+func RangeAreas(r Range) []string {
+	var names []string
+	for _, a := range Areas {
+		if r.Start <= a.End && r.End >= a.Start {
+			names = append(names, a.Name)
+		}
+	}
+	return names
+}
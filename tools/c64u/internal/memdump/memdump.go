@@ -0,0 +1,186 @@
+package memdump
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Export formats
+const (
+	FormatRaw  = "raw"
+	FormatPRG  = "prg"
+	FormatHex  = "ihex"
+	FormatC    = "c"
+	FormatKick = "kick"
+)
+
+// Formats lists the supported export formats
+var Formats = []string{FormatRaw, FormatPRG, FormatHex, FormatC, FormatKick}
+
+// FormatFromPath guesses the export format from a file extension
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".prg":
+		return FormatPRG
+	case ".hex", ".ihx", ".ihex":
+		return FormatHex
+	case ".c", ".h":
+		return FormatC
+	case ".asm", ".s", ".inc":
+		return FormatKick
+	default:
+		return FormatRaw
+	}
+}
+
+// IsText reports whether a format produces text output
+func IsText(format string) bool {
+	switch format {
+	case FormatHex, FormatC, FormatKick:
+		return true
+	}
+	return false
+}
+
+// Encode converts a memory block starting at start into an export format.
+// label names the array or label in C and assembler output.
+func Encode(format string, data []byte, start int, label string) ([]byte, error) {
+	switch format {
+	case FormatRaw:
+		return data, nil
+	case FormatPRG:
+		return append([]byte{byte(start), byte(start >> 8)}, data...), nil
+	case FormatHex:
+		return intelHex(data, start), nil
+	case FormatC:
+		return cArray(data, start, label), nil
+	case FormatKick:
+		return kickSource(data, start, label), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q (use %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// intelHex writes 16-byte data records followed by an end-of-file record
+func intelHex(data []byte, start int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < len(data); i += 16 {
+		end := i + 16
+		if end > len(data) {
+			end = len(data)
+		}
+		addr := start + i
+		record := append([]byte{byte(end - i), byte(addr >> 8), byte(addr), 0x00}, data[i:end]...)
+
+		var sum byte
+		for _, b := range record {
+			sum += b
+		}
+		fmt.Fprintf(&buf, ":%X%02X\n", record, -sum)
+	}
+	buf.WriteString(":00000001FF\n")
+	return buf.Bytes()
+}
+
+// cArray writes the data as an unsigned char array
+func cArray(data []byte, start int, label string) []byte {
+	label = Identifier(label)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "/* $%04X-$%04X, %d bytes */\n", start, start+len(data)-1, len(data))
+	fmt.Fprintf(&buf, "#define %s_ADDR 0x%04X\n\n", strings.ToUpper(label), start)
+	fmt.Fprintf(&buf, "const unsigned char %s[%d] = {\n", label, len(data))
+	for i := 0; i < len(data); i += 12 {
+		buf.WriteString("   ")
+		for j := i; j < i+12 && j < len(data); j++ {
+			fmt.Fprintf(&buf, " 0x%02x", data[j])
+			if j < len(data)-1 {
+				buf.WriteString(",")
+			}
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("};\n")
+	return buf.Bytes()
+}
+
+// kickSource writes KickAssembler source with .byte directives
+func kickSource(data []byte, start int, label string) []byte {
+	label = Identifier(label)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// $%04X-$%04X, %d bytes\n", start, start+len(data)-1, len(data))
+	fmt.Fprintf(&buf, "* = $%04X \"%s\"\n", start, label)
+	fmt.Fprintf(&buf, "%s:\n", label)
+	for i := 0; i < len(data); i += 16 {
+		buf.WriteString("    .byte ")
+		for j := i; j < i+16 && j < len(data); j++ {
+			if j > i {
+				buf.WriteString(",")
+			}
+			fmt.Fprintf(&buf, "$%02x", data[j])
+		}
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// Identifier turns a name into a valid C / assembler identifier
+func Identifier(name string) string {
+	id := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	if id == "" || (id[0] >= '0' && id[0] <= '9') {
+		id = "data_" + id
+	}
+	return id
+}
+
+// Range is an inclusive address range
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Len returns the number of bytes in the range
+func (r Range) Len() int {
+	return r.End - r.Start + 1
+}
+
+// String formats the range as "$C000-$C0FF" or "$C000" for single bytes
+func (r Range) String() string {
+	if r.Start == r.End {
+		return fmt.Sprintf("$%04X", r.Start)
+	}
+	return fmt.Sprintf("$%04X-$%04X", r.Start, r.End)
+}
+
+// Diff returns the address ranges where a and b differ. Both blocks start
+// at start; bytes beyond the shorter block count as different.
+func Diff(a, b []byte, start int) []Range {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+
+	var ranges []Range
+	for i := 0; i < n; i++ {
+		if i < len(a) && i < len(b) && a[i] == b[i] {
+			continue
+		}
+		addr := start + i
+		if len(ranges) > 0 && ranges[len(ranges)-1].End == addr-1 {
+			ranges[len(ranges)-1].End = addr
+		} else {
+			ranges = append(ranges, Range{addr, addr})
+		}
+	}
+	return ranges
+}
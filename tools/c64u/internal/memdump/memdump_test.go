@@ -0,0 +1,138 @@
+package memdump
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	data := []byte{0x01, 0x02, 0xff}
+
+	tests := []struct {
+		format string
+		label  string
+		want   string
+	}{
+		{FormatRaw, "", "\x01\x02\xff"},
+		{FormatPRG, "", "\x00\xc0\x01\x02\xff"},
+		{FormatHex, "", ":03C00000" + "0102FF" + "3B\n:00000001FF\n"},
+		{FormatC, "sprite data", "/* $C000-$C002, 3 bytes */\n" +
+			"#define SPRITE_DATA_ADDR 0xC000\n\n" +
+			"const unsigned char sprite_data[3] = {\n" +
+			"    0x01, 0x02, 0xff\n" +
+			"};\n"},
+		{FormatKick, "1st", "// $C000-$C002, 3 bytes\n" +
+			"* = $C000 \"data_1st\"\n" +
+			"data_1st:\n" +
+			"    .byte $01,$02,$ff\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := Encode(tt.format, data, 0xC000, tt.label)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Encode("bin", data, 0, ""); err == nil {
+		t.Error("Encode accepted an unknown format")
+	}
+}
+
+// TestIntelHexRecords checks record splitting and checksums on a longer block
+func TestIntelHexRecords(t *testing.T) {
+	data := make([]byte, 20)
+	out := string(intelHex(data, 0x0801))
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+
+	want := []string{
+		":1008010000000000000000000000000000000000E7",
+		":0408110000000000E3",
+		":00000001FF",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d records, want %d:\n%s", len(lines), len(want), out)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("record %d = %s, want %s", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"dump.prg", FormatPRG},
+		{"DUMP.HEX", FormatHex},
+		{"font.h", FormatC},
+		{"music.asm", FormatKick},
+		{"memory.bin", FormatRaw},
+		{"memory", FormatRaw},
+	}
+
+	for _, tt := range tests {
+		if got := FormatFromPath(tt.path); got != tt.want {
+			t.Errorf("FormatFromPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+		if IsText(tt.want) != (tt.want == FormatHex || tt.want == FormatC || tt.want == FormatKick) {
+			t.Errorf("IsText(%q) wrong", tt.want)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []byte
+		want string
+	}{
+		{"equal", []byte{1, 2, 3}, []byte{1, 2, 3}, ""},
+		{"single byte", []byte{1, 2, 3}, []byte{1, 9, 3}, "$1001"},
+		{"merged run", []byte{1, 2, 3, 4}, []byte{9, 9, 9, 4}, "$1000-$1002"},
+		{"two runs", []byte{1, 2, 3, 4}, []byte{9, 2, 3, 9}, "$1000 $1003"},
+		{"longer b", []byte{1}, []byte{1, 2, 3}, "$1001-$1002"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range Diff(tt.a, tt.b, 0x1000) {
+				got = append(got, r.String())
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("Diff = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAreas(t *testing.T) {
+	tests := []struct {
+		addr int
+		want string
+	}{
+		{0x0001, "CPU port"},
+		{0x0400, "screen RAM"},
+		{0x07E8, ""},
+		{0x07F8, "sprite pointers"},
+		{0xD020, "VIC-II"},
+		{0xFFFE, "CPU vectors"},
+	}
+	for _, tt := range tests {
+		if got := AreaName(tt.addr); got != tt.want {
+			t.Errorf("AreaName($%04X) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+
+	got := RangeAreas(Range{0xCFF0, 0xD410})
+	if strings.Join(got, ", ") != "upper RAM, VIC-II, SID" {
+		t.Errorf("RangeAreas = %v", got)
+	}
+}
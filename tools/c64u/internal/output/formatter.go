@@ -260,6 +260,18 @@ func (f *Formatter) Info(message string) {
 	}
 }
 
+// Note prints an informational message to stderr (text mode only, silent
+// in JSON mode), for remarks that must not mix with data on stdout
+func (f *Formatter) Note(message string) {
+	if f.Mode == ModeText {
+		if f.NoColor {
+			fmt.Fprintf(os.Stderr, "ℹ %s\n", message)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s %s\n", infoStyle.Render("ℹ"), message)
+		}
+	}
+}
+
 // Warning prints a warning message
 func (f *Formatter) Warning(message string) {
 	if f.Mode == ModeJSON {
@@ -345,3 +357,19 @@ func (f *Formatter) GetFlagStyle() lipgloss.Style {
 	return lipgloss.NewStyle().
 		Foreground(lipgloss.Color("11"))
 }
+
+// GetChangeStyle returns a style for values that changed, e.g. in watch views
+func (f *Formatter) GetChangeStyle() lipgloss.Style {
+	if f.NoColor {
+		return lipgloss.NewStyle()
+	}
+	return warningStyle
+}
+
+// GetDimStyle returns a style for less important info
+func (f *Formatter) GetDimStyle() lipgloss.Style {
+	if f.NoColor {
+		return lipgloss.NewStyle()
+	}
+	return dimStyle
+}
@@ -0,0 +1,206 @@
+package petscii
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// controlNames are the {NAME} mnemonics of the PETSCII control codes, as
+// used in listings and typed text
+var controlNames = map[byte]string{
+	0x03: "STOP",
+	0x05: "WHT",
+	0x08: "LOCK",
+	0x09: "UNLOCK",
+	0x0D: "RETURN",
+	0x0E: "LOWER",
+	0x11: "DOWN",
+	0x12: "RVS ON",
+	0x13: "HOME",
+	0x14: "DEL",
+	0x1C: "RED",
+	0x1D: "RIGHT",
+	0x1E: "GRN",
+	0x1F: "BLU",
+	0x81: "ORNG",
+	0x85: "F1",
+	0x86: "F3",
+	0x87: "F5",
+	0x88: "F7",
+	0x89: "F2",
+	0x8A: "F4",
+	0x8B: "F6",
+	0x8C: "F8",
+	0x8D: "SHIFT RETURN",
+	0x8E: "UPPER",
+	0x90: "BLK",
+	0x91: "UP",
+	0x92: "RVS OFF",
+	0x93: "CLR",
+	0x94: "INST",
+	0x95: "BRN",
+	0x96: "LRED",
+	0x97: "GRY1",
+	0x98: "GRY2",
+	0x99: "LGRN",
+	0x9A: "LBLU",
+	0x9B: "GRY3",
+	0x9C: "PUR",
+	0x9D: "LEFT",
+	0x9E: "YEL",
+	0x9F: "CYN",
+}
+
+// controlAliases are further accepted spellings (upper case, without
+// spaces) of the control mnemonics
+var controlAliases = map[string]byte{
+	"CR":         0x0D,
+	"ENTER":      0x0D,
+	"CLEAR":      0x93,
+	"CRSRDOWN":   0x11,
+	"CRSRUP":     0x91,
+	"CRSRLEFT":   0x9D,
+	"CRSRRIGHT":  0x1D,
+	"RGHT":       0x1D,
+	"REVERSEON":  0x12,
+	"REVERSEOFF": 0x92,
+	"RVOF":       0x92,
+	"RVON":       0x12,
+	"INSERT":     0x94,
+	"WHITE":      0x05,
+	"GREEN":      0x1E,
+	"BLUE":       0x1F,
+	"ORANGE":     0x81,
+	"BLACK":      0x90,
+	"BROWN":      0x95,
+	"PINK":       0x96,
+	"LIGHTRED":   0x96,
+	"DARKGREY":   0x97,
+	"GREY":       0x98,
+	"LIGHTGREEN": 0x99,
+	"LIGHTBLUE":  0x9A,
+	"LIGHTGREY":  0x9B,
+	"PURPLE":     0x9C,
+	"YELLOW":     0x9E,
+	"CYAN":       0x9F,
+	"RUNSTOP":    0x03,
+}
+
+// controlCodes maps normalised mnemonics to codes
+var controlCodes = map[string]byte{}
+
+func init() {
+	for code, name := range controlNames {
+		controlCodes[normaliseControl(name)] = code
+	}
+	for name, code := range controlAliases {
+		controlCodes[name] = code
+	}
+}
+
+// normaliseControl upper-cases a mnemonic and drops spaces, dashes and
+// underscores
+func normaliseControl(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(name))
+}
+
+// ControlName returns the mnemonic of a control code
+func ControlName(b byte) (string, bool) {
+	name, ok := controlNames[b]
+	return name, ok
+}
+
+// ControlCode looks up a mnemonic such as "CLR", "rvs on" or "$93"
+func ControlCode(name string) (byte, bool) {
+	if strings.HasPrefix(name, "$") {
+		v, err := strconv.ParseUint(name[1:], 16, 8)
+		return byte(v), err == nil
+	}
+	code, ok := controlCodes[normaliseControl(name)]
+	return code, ok
+}
+
+// MaxRepeat is the largest repeat count an escape such as {DOWN*3} takes
+const MaxRepeat = 255
+
+// EncodeEscaped converts text with {NAME} escapes to PETSCII. An escape
+// may repeat its code up to MaxRepeat times, as in {DOWN*3}; {$xx}
+// inserts any byte.
+func EncodeEscaped(s string, cs Charset) ([]byte, error) {
+	var out []byte
+	for len(s) > 0 {
+		open := strings.IndexByte(s, '{')
+		if open < 0 {
+			out = append(out, Encode(s, cs)...)
+			break
+		}
+		out = append(out, Encode(s[:open], cs)...)
+		end := strings.IndexByte(s[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated escape %q", s[open:])
+		}
+		escape := s[open+1 : open+end]
+		s = s[open+end+1:]
+
+		name, count := escape, 1
+		if i := strings.LastIndexByte(escape, '*'); i >= 0 {
+			n, err := strconv.Atoi(strings.TrimSpace(escape[i+1:]))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid repeat count in {%s}", escape)
+			}
+			if n > MaxRepeat {
+				return nil, fmt.Errorf("repeat count %d in {%s} exceeds %d", n, escape, MaxRepeat)
+			}
+			name, count = escape[:i], n
+		}
+		code, ok := ControlCode(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown escape {%s}", escape)
+		}
+		for i := 0; i < count; i++ {
+			out = append(out, code)
+		}
+	}
+	return out, nil
+}
+
+// Escape returns the {NAME} escape of a control code, or {$xx} for codes
+// without a mnemonic
+func Escape(b byte) string {
+	if name, ok := controlNames[b]; ok {
+		return "{" + name + "}"
+	}
+	return fmt.Sprintf("{$%02X}", b)
+}
+
+// DecodeEscaped converts PETSCII to text with control codes written as
+// {NAME} escapes, the inverse of EncodeEscaped. Runs of the same control
+// code are written with a repeat count, as in {DOWN*3}, split into
+// escapes of at most MaxRepeat.
+func DecodeEscaped(data []byte, cs Charset) string {
+	var sb strings.Builder
+	for i := 0; i < len(data); i++ {
+		if r, ok := Rune(data[i], cs); ok {
+			sb.WriteRune(r)
+			continue
+		}
+		n := 1
+		for n < MaxRepeat && i+n < len(data) && data[i+n] == data[i] {
+			n++
+		}
+		esc := Escape(data[i])
+		if n > 1 {
+			esc = fmt.Sprintf("%s*%d}", strings.TrimSuffix(esc, "}"), n)
+		}
+		sb.WriteString(esc)
+		i += n - 1
+	}
+	return sb.String()
+}
@@ -0,0 +1,70 @@
+package petscii
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Text encodings selectable on the command line
+const (
+	EncodingASCII        = "ascii"
+	EncodingPETSCII      = "petscii"
+	EncodingPETSCIILower = "petscii-lower"
+	EncodingScreen       = "screen"
+	EncodingScreenLower  = "screen-lower"
+)
+
+// Encodings lists the supported encodings
+var Encodings = []string{EncodingASCII, EncodingPETSCII, EncodingPETSCIILower, EncodingScreen, EncodingScreenLower}
+
+// ValidEncoding checks an encoding name
+func ValidEncoding(name string) error {
+	for _, e := range Encodings {
+		if e == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown charset %q (use %s)", name, strings.Join(Encodings, ", "))
+}
+
+// DumpRune renders a byte for the text column of a hex dump in the given
+// encoding. Bytes without a printable character become '.'.
+func DumpRune(b byte, encoding string) rune {
+	switch encoding {
+	case EncodingPETSCII, EncodingPETSCIILower:
+		if r, ok := Rune(b, charsetOf(encoding)); ok && unicode.IsGraphic(r) {
+			return r
+		}
+		return '.'
+	case EncodingScreen, EncodingScreenLower:
+		return ScreenRune(b, charsetOf(encoding))
+	default:
+		if b >= 32 && b <= 126 {
+			return rune(b)
+		}
+		return '.'
+	}
+}
+
+// EncodeAs converts text to bytes in the given encoding
+func EncodeAs(s, encoding string) ([]byte, error) {
+	switch encoding {
+	case EncodingPETSCII, EncodingPETSCIILower:
+		return Encode(s, charsetOf(encoding)), nil
+	case EncodingScreen, EncodingScreenLower:
+		return EncodeScreen(s, charsetOf(encoding)), nil
+	case EncodingASCII:
+		return []byte(s), nil
+	default:
+		return nil, ValidEncoding(encoding)
+	}
+}
+
+// charsetOf returns the character set an encoding name refers to
+func charsetOf(encoding string) Charset {
+	if strings.HasSuffix(encoding, "-lower") {
+		return Lower
+	}
+	return Upper
+}
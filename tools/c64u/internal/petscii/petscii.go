@@ -0,0 +1,240 @@
+package petscii
+
+import "strings"
+
+// Charset selects one of the two C64 character sets
+type Charset int
+
+const (
+	// Upper is the power-on set: upper case letters and graphics
+	Upper Charset = iota
+	// Lower is the lower/upper case set
+	Lower
+)
+
+// screenUpper maps screen codes $00-$7F of the upper case set to Unicode.
+// Graphics use the Symbols for Legacy Computing block (U+1FB00) where
+// there is no better match.
+var screenUpper = [128]rune{
+	'@', 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N', 'O',
+	'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z', '[', '£', ']', '↑', '←',
+	' ', '!', '"', '#', '$', '%', '&', '\'', '(', ')', '*', '+', ',', '-', '.', '/',
+	'0', '1', '2', '3', '4', '5', '6', '7', '8', '9', ':', ';', '<', '=', '>', '?',
+	'─', '♠', '\U0001FB72', '\U0001FB78', '\U0001FB77', '\U0001FB76', '\U0001FB7A', '\U0001FB71',
+	'\U0001FB74', '╮', '╰', '╯', '\U0001FB7C', '╲', '╱', '\U0001FB7D',
+	'\U0001FB7E', '●', '\U0001FB7B', '♥', '\U0001FB70', '╭', '╳', '○',
+	'♣', '\U0001FB75', '♦', '┼', '\U0001FB8C', '│', 'π', '◥',
+	'\u00A0', '▌', '▄', '▔', '▁', '▏', '▒', '▕', '\U0001FB8F', '◤', '\U0001FB87', '├', '▗', '└', '┐', '▂',
+	'┌', '┴', '┬', '┤', '▎', '▍', '\U0001FB88', '\U0001FB82', '\U0001FB83', '▃', '\U0001FB7F', '▖', '▝', '┘', '▘', '▚',
+}
+
+// screenLower is the lower/upper case set, derived from screenUpper
+var screenLower [128]rune
+
+// Reverse lookups built from the tables
+var (
+	fromUnicodeUpper = map[rune]byte{}
+	fromUnicodeLower = map[rune]byte{}
+)
+
+func init() {
+	screenLower = screenUpper
+	for i := 0; i < 26; i++ {
+		screenLower[0x01+i] = rune('a' + i)
+		screenLower[0x41+i] = rune('A' + i)
+	}
+	screenLower[0x5E] = '\U0001FB96'
+	screenLower[0x5F] = '\U0001FB98'
+	screenLower[0x69] = '\U0001FB99'
+	screenLower[0x7A] = '✓'
+
+	// Earlier entries win, so letters keep their canonical codes
+	for sc := 0x7F; sc >= 0; sc-- {
+		fromUnicodeUpper[screenUpper[sc]] = byte(sc)
+		fromUnicodeLower[screenLower[sc]] = byte(sc)
+	}
+}
+
+// table returns the screen code table of a charset
+func table(cs Charset) *[128]rune {
+	if cs == Lower {
+		return &screenLower
+	}
+	return &screenUpper
+}
+
+// ScreenRune converts a screen code to Unicode. Reverse video codes
+// ($80-$FF) show the same glyph as their normal counterpart.
+func ScreenRune(code byte, cs Charset) rune {
+	return table(cs)[code&0x7F]
+}
+
+// DecodeScreen converts screen codes to a string
+func DecodeScreen(data []byte, cs Charset) string {
+	var sb strings.Builder
+	for _, b := range data {
+		sb.WriteRune(ScreenRune(b, cs))
+	}
+	return sb.String()
+}
+
+// ToScreen converts a PETSCII code to its screen code. Control codes have
+// no screen code and return false.
+func ToScreen(b byte) (byte, bool) {
+	switch {
+	case b < 0x20, b >= 0x80 && b < 0xA0:
+		return 0, false
+	case b < 0x40:
+		return b, true
+	case b < 0x60:
+		return b - 0x40, true
+	case b < 0x80:
+		return b - 0x20, true
+	case b < 0xC0:
+		return b - 0x40, true
+	case b == 0xFF:
+		return 0x5E, true
+	default:
+		return b - 0x80, true
+	}
+}
+
+// FromScreen converts a screen code ($00-$7F) to its usual PETSCII code
+func FromScreen(code byte) byte {
+	code &= 0x7F
+	switch {
+	case code < 0x20:
+		return code + 0x40
+	case code < 0x40:
+		return code
+	case code < 0x60:
+		return code + 0x80
+	default:
+		return code + 0x40
+	}
+}
+
+// Rune converts a printable PETSCII code to Unicode; control codes
+// return false
+func Rune(b byte, cs Charset) (rune, bool) {
+	code, ok := ToScreen(b)
+	if !ok {
+		return 0, false
+	}
+	return ScreenRune(code, cs), true
+}
+
+// Decode converts PETSCII to a string. RETURN becomes a newline and other
+// control codes are dropped.
+func Decode(data []byte, cs Charset) string {
+	var sb strings.Builder
+	for _, b := range data {
+		if b == 0x0D || b == 0x8D {
+			sb.WriteByte('\n')
+			continue
+		}
+		if r, ok := Rune(b, cs); ok {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// ScreenFromRune converts a character to a screen code. In the upper case
+// set lower case letters are folded to upper case.
+func ScreenFromRune(r rune, cs Charset) (byte, bool) {
+	if cs == Upper && r >= 'a' && r <= 'z' {
+		r -= 'a' - 'A'
+	}
+	switch r {
+	case '\\':
+		r = '£'
+	case '^':
+		r = '↑'
+	case '_':
+		r = '←'
+	}
+	if cs == Lower {
+		code, ok := fromUnicodeLower[r]
+		return code, ok
+	}
+	code, ok := fromUnicodeUpper[r]
+	return code, ok
+}
+
+// FromRune converts a character to PETSCII. A newline becomes RETURN.
+func FromRune(r rune, cs Charset) (byte, bool) {
+	if r == '\n' {
+		return 0x0D, true
+	}
+	code, ok := ScreenFromRune(r, cs)
+	if !ok {
+		return 0, false
+	}
+	return FromScreen(code), true
+}
+
+// Encode converts a string to PETSCII; characters without an equivalent
+// become '?'
+func Encode(s string, cs Charset) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		b, ok := FromRune(r, cs)
+		if !ok {
+			b = '?'
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// EncodeScreen converts a string to screen codes; characters without an
+// equivalent become '?'
+func EncodeScreen(s string, cs Charset) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		code, ok := ScreenFromRune(r, cs)
+		if !ok {
+			code = '?'
+		}
+		out = append(out, code)
+	}
+	return out
+}
+
+// ToASCII converts a PETSCII name (file, disk or tape name) to plain
+// printable ASCII. Shifted letters are shown as upper case, shifted space
+// as space and graphics as '?'.
+func ToASCII(raw []byte) string {
+	var sb strings.Builder
+	for _, b := range raw {
+		switch {
+		case b >= 0x20 && b <= 0x5F:
+			sb.WriteByte(b)
+		case b >= 0xC1 && b <= 0xDA:
+			sb.WriteByte(b - 0x80)
+		case b == 0xA0:
+			sb.WriteByte(' ')
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
+
+// FromASCII converts a plain text name to PETSCII, mapping letters of
+// either case to their unshifted (upper case) codes
+func FromASCII(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z':
+			out = append(out, byte(r-'a'+'A'))
+		case r >= 0x20 && r <= 0x5F:
+			out = append(out, byte(r))
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
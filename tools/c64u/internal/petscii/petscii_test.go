@@ -0,0 +1,141 @@
+package petscii
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestScreenRoundTrip checks that every screen code maps to a character
+// that maps back to the same code, in both character sets
+func TestScreenRoundTrip(t *testing.T) {
+	for _, cs := range []Charset{Upper, Lower} {
+		for code := 0; code < 0x80; code++ {
+			r := ScreenRune(byte(code), cs)
+			back, ok := ScreenFromRune(r, cs)
+			if !ok || back != byte(code) {
+				t.Errorf("charset %d: $%02X -> %q -> $%02X, %v", cs, code, r, back, ok)
+			}
+			if ScreenRune(byte(code)|0x80, cs) != r {
+				t.Errorf("charset %d: reverse $%02X differs", cs, code|0x80)
+			}
+		}
+	}
+}
+
+// TestPETSCIIScreenConversion checks that screen codes survive the trip
+// through PETSCII and that control codes have no screen code
+func TestPETSCIIScreenConversion(t *testing.T) {
+	for code := 0; code < 0x80; code++ {
+		b := FromScreen(byte(code))
+		if back, ok := ToScreen(b); !ok || back != byte(code) {
+			t.Errorf("$%02X -> PETSCII $%02X -> $%02X, %v", code, b, back, ok)
+		}
+	}
+
+	tests := []struct {
+		b    byte
+		code byte
+		ok   bool
+	}{
+		{0x0D, 0, false},
+		{0x93, 0, false},
+		{0x41, 0x01, true},
+		{0x61, 0x41, true},
+		{0xC1, 0x41, true},
+		{0xA0, 0x60, true},
+		{0xFF, 0x5E, true},
+	}
+	for _, tt := range tests {
+		if code, ok := ToScreen(tt.b); code != tt.code || ok != tt.ok {
+			t.Errorf("ToScreen($%02X) = $%02X, %v; want $%02X, %v", tt.b, code, ok, tt.code, tt.ok)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		encoding string
+		want     string
+	}{
+		{"petscii", "HELLO\n", EncodingPETSCII, "48 45 4C 4C 4F 0D"},
+		{"petscii folds case", "hi", EncodingPETSCII, "48 49"},
+		{"petscii lower", "Hello", EncodingPETSCIILower, "C8 45 4C 4C 4F"},
+		{"petscii symbols", "£↑←π", EncodingPETSCII, "5C 5E 5F DE"},
+		{"ascii replacements", `\^_`, EncodingPETSCII, "5C 5E 5F"},
+		{"unknown character", "é", EncodingPETSCII, "3F"},
+		{"screen", "@AZ1", EncodingScreen, "00 01 1A 31"},
+		{"screen lower", "aA", EncodingScreenLower, "01 41"},
+		{"ascii", "a\n", EncodingASCII, "61 0A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeAs(tt.text, tt.encoding)
+			if err != nil {
+				t.Fatalf("EncodeAs: %v", err)
+			}
+			if fmt.Sprintf("% X", got) != tt.want {
+				t.Errorf("got % X, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := EncodeAs("x", "ebcdic"); err == nil {
+		t.Error("EncodeAs accepted an unknown encoding")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		data []byte
+		cs   Charset
+		want string
+	}{
+		{[]byte{0x93, 0x48, 0x49, 0x0D, 0x5C}, Upper, "HI\n£"},
+		{[]byte{0xC8, 0x45, 0x8D}, Lower, "He\n"},
+		{[]byte{0x41, 0xC1}, Upper, "A♠"},
+	}
+	for _, tt := range tests {
+		if got := Decode(tt.data, tt.cs); got != tt.want {
+			t.Errorf("Decode(% X) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+
+	if got := DecodeScreen([]byte{0x08, 0x09, 0x88}, Upper); got != "HIH" {
+		t.Errorf("DecodeScreen = %q", got)
+	}
+}
+
+func TestDumpRune(t *testing.T) {
+	tests := []struct {
+		b        byte
+		encoding string
+		want     rune
+	}{
+		{0x41, EncodingASCII, 'A'},
+		{0x0D, EncodingASCII, '.'},
+		{0xC1, EncodingASCII, '.'},
+		{0x41, EncodingPETSCII, 'A'},
+		{0x41, EncodingPETSCIILower, 'a'},
+		{0x93, EncodingPETSCII, '.'},
+		{0x01, EncodingScreen, 'A'},
+		{0x01, EncodingScreenLower, 'a'},
+		{0x81, EncodingScreen, 'A'},
+	}
+	for _, tt := range tests {
+		if got := DumpRune(tt.b, tt.encoding); got != tt.want {
+			t.Errorf("DumpRune($%02X, %s) = %q, want %q", tt.b, tt.encoding, got, tt.want)
+		}
+	}
+}
+
+func TestASCIINames(t *testing.T) {
+	if got := ToASCII([]byte{0x48, 0xC9, 0xA0, 0x31, 0x12}); got != "HI 1?" {
+		t.Errorf("ToASCII = %q", got)
+	}
+	if got := string(FromASCII("Game-1 é")); got != "GAME-1 ?" {
+		t.Errorf("FromASCII = %q", got)
+	}
+}
@@ -0,0 +1,263 @@
+package prg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Region is a named area of the C64 memory map
+type Region struct {
+	Name  string `json:"name"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// MemoryMap lists the areas a PRG is checked against
+var MemoryMap = []Region{
+	{"Zero page", 0x0000, 0x00FF},
+	{"Stack", 0x0100, 0x01FF},
+	{"KERNAL/BASIC work area", 0x0200, 0x03FF},
+	{"Screen RAM", 0x0400, 0x07FF},
+	{"BASIC program area", 0x0800, 0x9FFF},
+	{"BASIC ROM shadow", 0xA000, 0xBFFF},
+	{"Upper RAM", 0xC000, 0xCFFF},
+	{"I/O area", 0xD000, 0xDFFF},
+	{"KERNAL ROM shadow", 0xE000, 0xFFFF},
+}
+
+const (
+	basicStart = 0x0801
+	tokenSYS   = 0x9E
+)
+
+// PRG is a program file with its two-byte load address header
+type PRG struct {
+	LoadAddress int    `json:"load_address"`
+	Data        []byte `json:"-"`
+}
+
+// BasicStub describes a BASIC line that starts machine code via SYS
+type BasicStub struct {
+	LineNumber int    `json:"line_number"`
+	Text       string `json:"text"`
+	SysAddress int    `json:"sys_address"`
+}
+
+// Info is the result of analyzing a PRG
+type Info struct {
+	LoadAddress  int        `json:"load_address"`
+	EndAddress   int        `json:"end_address"`
+	Size         int        `json:"size"`
+	Regions      []Region   `json:"regions"`
+	Stub         *BasicStub `json:"basic_stub,omitempty"`
+	StartAddress int        `json:"start_address"`
+	Warnings     []string   `json:"warnings"`
+}
+
+// ReadFile loads a PRG file from disk
+func ReadFile(path string) (*PRG, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse splits PRG file contents into load address and data
+func Parse(data []byte) (*PRG, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("file too small for a PRG (%d bytes)", len(data))
+	}
+	return &PRG{
+		LoadAddress: int(binary.LittleEndian.Uint16(data)),
+		Data:        data[2:],
+	}, nil
+}
+
+// EndAddress returns the address of the last loaded byte
+func (p *PRG) EndAddress() int {
+	if len(p.Data) == 0 {
+		return p.LoadAddress
+	}
+	return p.LoadAddress + len(p.Data) - 1
+}
+
+// Contains reports whether addr lies within the loaded data
+func (p *PRG) Contains(addr int) bool {
+	return addr >= p.LoadAddress && addr <= p.EndAddress() && len(p.Data) > 0
+}
+
+// ByteAt returns the loaded byte at addr
+func (p *PRG) ByteAt(addr int) (byte, bool) {
+	if !p.Contains(addr) {
+		return 0, false
+	}
+	return p.Data[addr-p.LoadAddress], true
+}
+
+// Analyze reports the memory regions a PRG touches, detects a BASIC SYS
+// stub and collects warnings about likely placement mistakes
+func (p *PRG) Analyze() *Info {
+	info := &Info{
+		LoadAddress:  p.LoadAddress,
+		EndAddress:   p.EndAddress(),
+		Size:         len(p.Data),
+		StartAddress: p.LoadAddress,
+	}
+
+	if len(p.Data) == 0 {
+		info.Warnings = append(info.Warnings, "PRG contains no data")
+		return info
+	}
+
+	for _, r := range MemoryMap {
+		if p.LoadAddress <= r.End && info.EndAddress >= r.Start {
+			info.Regions = append(info.Regions, r)
+		}
+	}
+
+	if info.EndAddress > 0xFFFF {
+		info.Warnings = append(info.Warnings,
+			fmt.Sprintf("data extends $%X bytes past $FFFF", info.EndAddress-0xFFFF))
+	}
+	if p.LoadAddress < 0x0200 {
+		info.Warnings = append(info.Warnings,
+			fmt.Sprintf("loads at $%04X, below $0200 (overwrites zero page/stack)", p.LoadAddress))
+	}
+	if p.LoadAddress <= 0xDFFF && info.EndAddress >= 0xD000 {
+		info.Warnings = append(info.Warnings,
+			"overlaps the I/O area $D000-$DFFF (VIC/SID/CIA registers and colour RAM)")
+	}
+
+	if stub := p.FindBasicStub(); stub != nil {
+		info.Stub = stub
+		info.StartAddress = stub.SysAddress
+		info.Warnings = append(info.Warnings, p.checkSysTarget(stub)...)
+	} else if p.Contains(basicStart) && !p.hasBasicProgram() {
+		info.Warnings = append(info.Warnings,
+			"loads into the BASIC area but has no SYS stub; RUN will not start machine code")
+	}
+
+	return info
+}
+
+// checkSysTarget verifies that the SYS address points at loaded code
+func (p *PRG) checkSysTarget(stub *BasicStub) []string {
+	if !p.Contains(stub.SysAddress) {
+		return []string{fmt.Sprintf("SYS %d ($%04X) points outside the loaded data ($%04X-$%04X)",
+			stub.SysAddress, stub.SysAddress, p.LoadAddress, p.EndAddress())}
+	}
+	if b, _ := p.ByteAt(stub.SysAddress); b == 0x00 {
+		return []string{fmt.Sprintf("SYS target $%04X contains $00 (BRK) - check the *= placement of your code",
+			stub.SysAddress)}
+	}
+	return nil
+}
+
+// FindBasicStub looks for a SYS <number> statement in the first BASIC
+// line, e.g. "10 SYS2064" or "10 POKE53280,0:SYS2064". Programs assembled
+// at $0800 with a leading zero byte are handled too.
+func (p *PRG) FindBasicStub() *BasicStub {
+	pos := basicStart - p.LoadAddress
+	if pos < 0 || pos+5 > len(p.Data) {
+		return nil
+	}
+
+	line := p.Data[pos:]
+	link := int(binary.LittleEndian.Uint16(line))
+	if link == 0 {
+		return nil
+	}
+	lineNumber := int(binary.LittleEndian.Uint16(line[2:]))
+
+	// Tokenized line text runs to the terminating zero
+	text := line[4:]
+	end := 0
+	for end < len(text) && text[end] != 0 {
+		end++
+	}
+	text = text[:end]
+
+	// Check each statement start; colons inside strings don't separate
+	// statements
+	quoted := false
+	statementStart := true
+	for i, b := range text {
+		switch {
+		case b == '"':
+			quoted = !quoted
+			statementStart = false
+		case quoted:
+		case b == ':':
+			statementStart = true
+		case b == ' ':
+		case statementStart && b == tokenSYS:
+			args := text[i+1:]
+			if colon := bytes.IndexByte(args, ':'); colon >= 0 {
+				args = args[:colon]
+			}
+			if addr, ok := parseNumber(args); ok {
+				return &BasicStub{
+					LineNumber: lineNumber,
+					Text:       fmt.Sprintf("%d SYS%s", lineNumber, strings.TrimRight(string(args), " ")),
+					SysAddress: addr,
+				}
+			}
+			statementStart = false
+		default:
+			statementStart = false
+		}
+	}
+
+	return nil
+}
+
+// hasBasicProgram reports whether the data at $0801 is a chain of BASIC
+// lines that ends with a zero link
+func (p *PRG) hasBasicProgram() bool {
+	addr := basicStart
+	for lines := 0; ; lines++ {
+		lo, okLo := p.ByteAt(addr)
+		hi, okHi := p.ByteAt(addr + 1)
+		if !okLo || !okHi {
+			return false
+		}
+		link := int(lo) | int(hi)<<8
+		if link == 0 {
+			return lines > 0
+		}
+
+		// The link points past this line's zero terminator; a line holds
+		// at least the link, the line number and that terminator
+		if link < addr+5 {
+			return false
+		}
+		if b, ok := p.ByteAt(link - 1); !ok || b != 0 {
+			return false
+		}
+		addr = link
+	}
+}
+
+// parseNumber reads a decimal number, skipping spaces and an opening parenthesis
+func parseNumber(b []byte) (int, bool) {
+	i := 0
+	for i < len(b) && (b[i] == ' ' || b[i] == '(') {
+		i++
+	}
+
+	n, digits := 0, 0
+	for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+		n = n*10 + int(b[i]-'0')
+		digits++
+		i++
+		if n > 0xFFFF {
+			return 0, false
+		}
+	}
+
+	return n, digits > 0
+}
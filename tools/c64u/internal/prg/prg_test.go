@@ -0,0 +1,197 @@
+package prg
+
+import (
+	"strings"
+	"testing"
+)
+
+// basicLine is one tokenized line for buildBasic
+type basicLine struct {
+	number int
+	text   string
+}
+
+// buildBasic assembles a PRG loading at $0801 with the given BASIC lines,
+// followed by machine code
+func buildBasic(lines []basicLine, code []byte) []byte {
+	prg := []byte{0x01, 0x08}
+	addr := basicStart
+	for _, l := range lines {
+		next := addr + 2 + 2 + len(l.text) + 1
+		prg = append(prg, byte(next), byte(next>>8), byte(l.number), byte(l.number>>8))
+		prg = append(prg, l.text...)
+		prg = append(prg, 0)
+		addr = next
+	}
+	prg = append(prg, 0, 0)
+	return append(prg, code...)
+}
+
+// sys is the SYS token. A one-line stub "SYSnnnn" followed by the end of
+// program marker leaves the machine code at $080D (2061).
+const sys = "\x9e"
+
+func TestFindBasicStub(t *testing.T) {
+	code := []byte{0xA9, 0x00, 0x8D, 0x20, 0xD0, 0x60}
+
+	tests := []struct {
+		name     string
+		prg      []byte
+		wantText string
+		wantAddr int
+	}{
+		{
+			name:     "plain SYS",
+			prg:      buildBasic([]basicLine{{10, sys + "2061"}}, code),
+			wantText: "10 SYS2061",
+			wantAddr: 2061,
+		},
+		{
+			name:     "SYS with space and parenthesis",
+			prg:      buildBasic([]basicLine{{2024, sys + " (4096)"}}, code),
+			wantText: "2024 SYS (4096)",
+			wantAddr: 4096,
+		},
+		{
+			name:     "SYS after POKE",
+			prg:      buildBasic([]basicLine{{10, "\x9753280,0:" + sys + "2070"}}, code),
+			wantText: "10 SYS2070",
+			wantAddr: 2070,
+		},
+		{
+			name:     "statement after SYS",
+			prg:      buildBasic([]basicLine{{10, sys + "2064:\x80"}}, code),
+			wantText: "10 SYS2064",
+			wantAddr: 2064,
+		},
+		{
+			name: "SYS inside a string",
+			prg:  buildBasic([]basicLine{{10, "\x99\":" + sys + "2064\""}}, code),
+		},
+		{
+			name: "SYS as argument",
+			prg:  buildBasic([]basicLine{{10, "\x97" + sys + "2064"}}, code),
+		},
+		{
+			name: "SYS with expression",
+			prg:  buildBasic([]basicLine{{10, sys + "X"}}, code),
+		},
+		{
+			name: "no program",
+			prg:  append([]byte{0x01, 0x08, 0x00, 0x00}, code...),
+		},
+		{
+			name:     "assembled at $0800",
+			prg:      append([]byte{0x00, 0x08, 0x00}, buildBasic([]basicLine{{1, sys + "2062"}}, code)[2:]...),
+			wantText: "1 SYS2062",
+			wantAddr: 2062,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.prg)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			stub := p.FindBasicStub()
+			if tt.wantText == "" {
+				if stub != nil {
+					t.Errorf("found stub %q, want none", stub.Text)
+				}
+				return
+			}
+			if stub == nil {
+				t.Fatal("no stub found")
+			}
+			if stub.Text != tt.wantText || stub.SysAddress != tt.wantAddr {
+				t.Errorf("stub = %q $%04X, want %q $%04X", stub.Text, stub.SysAddress, tt.wantText, tt.wantAddr)
+			}
+		})
+	}
+}
+
+func TestAnalyzeWarnings(t *testing.T) {
+	code := []byte{0xA9, 0x00, 0x60}
+
+	tests := []struct {
+		name  string
+		prg   []byte
+		start int
+		warns []string // substrings, in order
+	}{
+		{
+			name:  "stub pointing at code",
+			prg:   buildBasic([]basicLine{{10, sys + "2061"}}, code),
+			start: 2061,
+		},
+		{
+			name:  "stub pointing past the data",
+			prg:   buildBasic([]basicLine{{10, sys + "49152"}}, code),
+			start: 49152,
+			warns: []string{"points outside the loaded data"},
+		},
+		{
+			name:  "stub pointing at BRK",
+			prg:   buildBasic([]basicLine{{10, sys + "2061"}}, []byte{0x00, 0x60}),
+			start: 2061,
+			warns: []string{"contains $00 (BRK)"},
+		},
+		{
+			name:  "plain BASIC program",
+			prg:   buildBasic([]basicLine{{10, "\x99\"HELLO\""}, {20, "\x89 10"}}, nil),
+			start: basicStart,
+		},
+		{
+			name:  "machine code at $0801",
+			prg:   append([]byte{0x01, 0x08}, 0xA9, 0x00, 0x8D, 0x20, 0xD0, 0x60),
+			start: basicStart,
+			warns: []string{"no SYS stub"},
+		},
+		{
+			name:  "code in I/O area",
+			prg:   append([]byte{0x00, 0xCF}, make([]byte, 0x200)...),
+			start: 0xCF00,
+			warns: []string{"I/O area"},
+		},
+		{
+			name:  "zero page",
+			prg:   []byte{0xFB, 0x00, 1, 2, 3},
+			start: 0x00FB,
+			warns: []string{"below $0200"},
+		},
+		{
+			name:  "past $FFFF",
+			prg:   append([]byte{0xF0, 0xFF}, make([]byte, 0x20)...),
+			start: 0xFFF0,
+			warns: []string{"past $FFFF"},
+		},
+		{
+			name:  "empty",
+			prg:   []byte{0x01, 0x08},
+			start: basicStart,
+			warns: []string{"no data"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.prg)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			info := p.Analyze()
+			if info.StartAddress != tt.start {
+				t.Errorf("StartAddress = $%04X, want $%04X", info.StartAddress, tt.start)
+			}
+			if len(info.Warnings) != len(tt.warns) {
+				t.Fatalf("warnings = %q, want %d matching %q", info.Warnings, len(tt.warns), tt.warns)
+			}
+			for i, w := range tt.warns {
+				if !strings.Contains(info.Warnings[i], w) {
+					t.Errorf("warning %d = %q, want it to contain %q", i, info.Warnings[i], w)
+				}
+			}
+		})
+	}
+}
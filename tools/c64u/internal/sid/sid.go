@@ -0,0 +1,243 @@
+package sid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PSID/RSID file header (big-endian)
+//
+//   $00 magic "PSID" or "RSID"
+//   $04 version (1-4)
+//   $06 data offset
+//   $08 load address (0 = first two data bytes, little-endian)
+//   $0A init address
+//   $0C play address
+//   $0E number of songs
+//   $10 start song (1-based)
+//   $12 speed flags (bit n: song n+1 uses CIA timer instead of VBI)
+//   $16 name, $36 author, $56 released (32 bytes each, Latin-1)
+//
+// Version 2+ adds:
+//   $76 flags, $78 start page, $79 page length,
+//   $7A second SID address (v3+), $7B third SID address (v4+)
+
+const (
+	v1HeaderSize = 0x76
+	v2HeaderSize = 0x7C
+)
+
+// Header represents a parsed PSID/RSID header
+type Header struct {
+	Type         string `json:"type"`
+	Version      uint16 `json:"version"`
+	DataOffset   uint16 `json:"data_offset"`
+	LoadAddress  uint16 `json:"load_address"`
+	InitAddress  uint16 `json:"init_address"`
+	PlayAddress  uint16 `json:"play_address"`
+	Songs        int    `json:"songs"`
+	StartSong    int    `json:"start_song"`
+	Speed        uint32 `json:"speed"`
+	Name         string `json:"name"`
+	Author       string `json:"author"`
+	Released     string `json:"released"`
+	Flags        uint16 `json:"flags"`
+	StartPage    byte   `json:"start_page"`
+	PageLength   byte   `json:"page_length"`
+	SecondSID    uint16 `json:"second_sid,omitempty"`
+	ThirdSID     uint16 `json:"third_sid,omitempty"`
+	DataSize     int    `json:"data_size"`
+	EmbeddedLoad bool   `json:"embedded_load_address"`
+}
+
+// ReadFile loads and parses the header of a SID file on disk
+func ReadFile(path string) (*Header, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses a PSID/RSID header
+func Parse(data []byte) (*Header, error) {
+	if len(data) < v1HeaderSize {
+		return nil, fmt.Errorf("file too small for a SID header (%d bytes)", len(data))
+	}
+
+	magic := string(data[:4])
+	if magic != "PSID" && magic != "RSID" {
+		return nil, fmt.Errorf("not a SID file (bad magic %q)", magic)
+	}
+
+	h := &Header{
+		Type:        magic,
+		Version:     binary.BigEndian.Uint16(data[0x04:]),
+		DataOffset:  binary.BigEndian.Uint16(data[0x06:]),
+		LoadAddress: binary.BigEndian.Uint16(data[0x08:]),
+		InitAddress: binary.BigEndian.Uint16(data[0x0A:]),
+		PlayAddress: binary.BigEndian.Uint16(data[0x0C:]),
+		Songs:       int(binary.BigEndian.Uint16(data[0x0E:])),
+		StartSong:   int(binary.BigEndian.Uint16(data[0x10:])),
+		Speed:       binary.BigEndian.Uint32(data[0x12:]),
+		Name:        latin1(data[0x16:0x36]),
+		Author:      latin1(data[0x36:0x56]),
+		Released:    latin1(data[0x56:0x76]),
+	}
+
+	if h.Version < 1 || h.Version > 4 {
+		return nil, fmt.Errorf("unsupported SID header version %d", h.Version)
+	}
+	if magic == "RSID" && h.Version < 2 {
+		return nil, fmt.Errorf("RSID files require header version 2 or later")
+	}
+
+	if h.Version >= 2 {
+		if len(data) < v2HeaderSize {
+			return nil, fmt.Errorf("truncated v%d header", h.Version)
+		}
+		h.Flags = binary.BigEndian.Uint16(data[0x76:])
+		h.StartPage = data[0x78]
+		h.PageLength = data[0x79]
+		if h.Version >= 3 {
+			h.SecondSID = sidAddress(data[0x7A])
+		}
+		if h.Version >= 4 {
+			h.ThirdSID = sidAddress(data[0x7B])
+		}
+	}
+
+	if int(h.DataOffset) > len(data) {
+		return nil, fmt.Errorf("data offset $%04X beyond end of file", h.DataOffset)
+	}
+	h.DataSize = len(data) - int(h.DataOffset)
+
+	if h.LoadAddress == 0 {
+		if h.DataSize < 2 {
+			return nil, fmt.Errorf("missing embedded load address")
+		}
+		h.LoadAddress = binary.LittleEndian.Uint16(data[h.DataOffset:])
+		h.EmbeddedLoad = true
+		h.DataSize -= 2
+	}
+
+	if h.Songs < 1 || h.Songs > 256 {
+		return nil, fmt.Errorf("invalid number of songs: %d", h.Songs)
+	}
+	if h.StartSong < 1 || h.StartSong > h.Songs {
+		// Out-of-range start songs are common; players fall back to song 1
+		h.StartSong = 1
+	}
+
+	return h, nil
+}
+
+// sidAddress decodes an extra SID address byte ($xx -> $Dxx0).
+// Only even values in $42-$7F and $E0-$FE are valid.
+func sidAddress(b byte) uint16 {
+	if b&1 != 0 || !((b >= 0x42 && b <= 0x7F) || (b >= 0xE0 && b <= 0xFE)) {
+		return 0
+	}
+	return 0xD000 | uint16(b)<<4
+}
+
+// latin1 decodes a zero-padded Latin-1 string
+func latin1(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		if c == 0 {
+			break
+		}
+		sb.WriteRune(rune(c))
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// EndAddress returns the last address occupied by the tune data
+func (h *Header) EndAddress() int {
+	return int(h.LoadAddress) + h.DataSize - 1
+}
+
+// SongSpeed returns "CIA" or "VBI" for a 1-based song number
+func (h *Header) SongSpeed(song int) string {
+	bit := song - 1
+	if bit > 31 {
+		bit = 31
+	}
+	if h.Type == "RSID" {
+		// RSID tunes set up their own timing
+		return "CIA"
+	}
+	if h.Speed&(1<<uint(bit)) != 0 {
+		return "CIA"
+	}
+	return "VBI"
+}
+
+// Clock returns the video standard the tune was written for
+func (h *Header) Clock() string {
+	switch (h.Flags >> 2) & 3 {
+	case 1:
+		return "PAL"
+	case 2:
+		return "NTSC"
+	case 3:
+		return "PAL and NTSC"
+	default:
+		return "Unknown"
+	}
+}
+
+// SIDModel returns the SID model for the first, second or third SID (0-based)
+func (h *Header) SIDModel(index int) string {
+	shift := uint(4 + index*2)
+	switch (h.Flags >> shift) & 3 {
+	case 1:
+		return "6581"
+	case 2:
+		return "8580"
+	case 3:
+		return "6581 and 8580"
+	default:
+		return "Unknown"
+	}
+}
+
+// IsMUS reports whether the tune contains Compute!'s Sidplayer MUS data
+func (h *Header) IsMUS() bool {
+	return h.Flags&1 != 0
+}
+
+// IsBASIC reports whether an RSID tune is a BASIC program
+func (h *Header) IsBASIC() bool {
+	return h.Type == "RSID" && h.Flags&2 != 0
+}
+
+// ResolveSong resolves a song selection against the header.
+// An empty spec selects the default start song, "+N"/"-N" are relative to
+// it and a plain number is an absolute 1-based song number.
+func (h *Header) ResolveSong(spec string) (int, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return h.StartSong, nil
+	}
+
+	n, err := strconv.Atoi(spec)
+	if err != nil {
+		return 0, fmt.Errorf("invalid song number %q", spec)
+	}
+
+	song := n
+	if strings.HasPrefix(spec, "+") || strings.HasPrefix(spec, "-") {
+		song = h.StartSong + n
+	}
+
+	if song < 1 || song > h.Songs {
+		return 0, fmt.Errorf("song %d out of range (tune has %d song(s), default %d)", song, h.Songs, h.StartSong)
+	}
+
+	return song, nil
+}
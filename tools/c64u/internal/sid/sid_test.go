@@ -0,0 +1,169 @@
+package sid
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// buildSID assembles a SID file with a header of the given version
+func buildSID(magic string, version, load uint16, songs, start int, data []byte) []byte {
+	size := v1HeaderSize
+	if version >= 2 {
+		size = v2HeaderSize
+	}
+	h := make([]byte, size)
+	copy(h, magic)
+	binary.BigEndian.PutUint16(h[0x04:], version)
+	binary.BigEndian.PutUint16(h[0x06:], uint16(size))
+	binary.BigEndian.PutUint16(h[0x08:], load)
+	binary.BigEndian.PutUint16(h[0x0A:], 0x1000)
+	binary.BigEndian.PutUint16(h[0x0C:], 0x1003)
+	binary.BigEndian.PutUint16(h[0x0E:], uint16(songs))
+	binary.BigEndian.PutUint16(h[0x10:], uint16(start))
+	copy(h[0x16:], "Commando")
+	copy(h[0x36:], "Rob Hubbard")
+	copy(h[0x56:], "1985 Elite")
+	if version >= 2 {
+		binary.BigEndian.PutUint16(h[0x76:], 0x0014) // PAL, 6581
+		h[0x7A] = 0x42
+		h[0x7B] = 0xE1 // odd, so invalid
+	}
+	return append(h, data...)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		load      uint16
+		size      int
+		embedded  bool
+		startSong int
+		secondSID uint16
+	}{
+		{
+			name:      "v1 with embedded load address",
+			data:      buildSID("PSID", 1, 0, 3, 2, []byte{0x00, 0x10, 0x4C, 0x00, 0x10}),
+			load:      0x1000,
+			size:      3,
+			embedded:  true,
+			startSong: 2,
+		},
+		{
+			name:      "v2 with explicit load address",
+			data:      buildSID("PSID", 2, 0xC000, 1, 1, []byte{0x60, 0x60}),
+			load:      0xC000,
+			size:      2,
+			startSong: 1,
+		},
+		{
+			name:      "v3 second SID",
+			data:      buildSID("RSID", 3, 0, 5, 9, []byte{0x00, 0x08, 0x60}),
+			load:      0x0800,
+			size:      1,
+			embedded:  true,
+			startSong: 1, // out of range start song falls back to 1
+			secondSID: 0xD420,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := Parse(tt.data)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if h.LoadAddress != tt.load || h.DataSize != tt.size || h.EmbeddedLoad != tt.embedded {
+				t.Errorf("load $%04X size %d embedded %v, want $%04X %d %v",
+					h.LoadAddress, h.DataSize, h.EmbeddedLoad, tt.load, tt.size, tt.embedded)
+			}
+			if h.StartSong != tt.startSong {
+				t.Errorf("StartSong = %d, want %d", h.StartSong, tt.startSong)
+			}
+			if h.SecondSID != tt.secondSID || h.ThirdSID != 0 {
+				t.Errorf("SIDs = $%04X/$%04X, want $%04X/$0000", h.SecondSID, h.ThirdSID, tt.secondSID)
+			}
+			if h.Name != "Commando" || h.Author != "Rob Hubbard" || h.Released != "1985 Elite" {
+				t.Errorf("strings = %q/%q/%q", h.Name, h.Author, h.Released)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"too small", []byte("PSID")},
+		{"bad magic", buildSID("MUS!", 2, 0x1000, 1, 1, nil)},
+		{"version 5", buildSID("PSID", 5, 0x1000, 1, 1, nil)},
+		{"RSID v1", buildSID("RSID", 1, 0x1000, 1, 1, nil)},
+		{"no songs", buildSID("PSID", 2, 0x1000, 0, 1, nil)},
+		{"missing load address", buildSID("PSID", 2, 0, 1, 1, []byte{0x00})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.data); err == nil {
+				t.Error("Parse succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestResolveSong(t *testing.T) {
+	h := &Header{Songs: 5, StartSong: 3}
+
+	tests := []struct {
+		spec    string
+		want    int
+		wantErr bool
+	}{
+		{spec: "", want: 3},
+		{spec: "1", want: 1},
+		{spec: " 5 ", want: 5},
+		{spec: "+1", want: 4},
+		{spec: "-2", want: 1},
+		{spec: "+3", wantErr: true},
+		{spec: "-3", wantErr: true},
+		{spec: "0", wantErr: true},
+		{spec: "6", wantErr: true},
+		{spec: "two", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := h.ResolveSong(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ResolveSong(%q) = %d, want error", tt.spec, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ResolveSong(%q) = %d, %v, want %d", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+func TestFlags(t *testing.T) {
+	h := &Header{Type: "PSID", Flags: 0x0014, Speed: 0x2}
+
+	if got := h.Clock(); got != "PAL" {
+		t.Errorf("Clock = %q, want PAL", got)
+	}
+	if got := h.SIDModel(0); got != "6581" {
+		t.Errorf("SIDModel(0) = %q, want 6581", got)
+	}
+	if got := h.SongSpeed(1); got != "VBI" {
+		t.Errorf("SongSpeed(1) = %q, want VBI", got)
+	}
+	if got := h.SongSpeed(2); got != "CIA" {
+		t.Errorf("SongSpeed(2) = %q, want CIA", got)
+	}
+
+	h.Type = "RSID"
+	if got := h.SongSpeed(1); got != "CIA" {
+		t.Errorf("RSID SongSpeed(1) = %q, want CIA", got)
+	}
+}
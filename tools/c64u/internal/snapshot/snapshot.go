@@ -0,0 +1,169 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MemorySize is the size of a full C64 address space
+const MemorySize = 0x10000
+
+// Snapshot is a saved copy of the C64 address space
+type Snapshot struct {
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	Host    string    `json:"host,omitempty"`
+	IO      bool      `json:"io"` // whether $D000-$DFFF was captured
+	Comment string    `json:"comment,omitempty"`
+
+	Memory []byte `json:"-"`
+}
+
+// Dir returns the snapshot store, ~/.config/c64u/snapshots
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "c64u", "snapshots"), nil
+}
+
+// ValidName checks that a snapshot name is usable as a file name
+func ValidName(name string) error {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return nil
+}
+
+// paths returns the metadata and memory file of a snapshot
+func paths(name string) (string, string, error) {
+	if err := ValidName(name); err != nil {
+		return "", "", err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", "", err
+	}
+	base := filepath.Join(dir, name)
+	return base + ".json", base + ".bin", nil
+}
+
+// Exists reports whether a snapshot is stored under name
+func Exists(name string) bool {
+	meta, _, err := paths(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(meta)
+	return err == nil
+}
+
+// Save writes a snapshot to the store. The memory is kept as a plain
+// 64K binary next to a JSON metadata file.
+func Save(s *Snapshot) error {
+	if len(s.Memory) != MemorySize {
+		return fmt.Errorf("snapshot memory is %d bytes, expected %d", len(s.Memory), MemorySize)
+	}
+	meta, bin, err := paths(s.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(meta), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot metadata: %w", err)
+	}
+	if err := os.WriteFile(bin, s.Memory, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.WriteFile(meta, data, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot metadata: %w", err)
+	}
+	return nil
+}
+
+// Load reads a snapshot including its memory
+func Load(name string) (*Snapshot, error) {
+	meta, bin, err := paths(name)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := readMeta(meta)
+	if err != nil {
+		return nil, err
+	}
+	s.Memory, err = os.ReadFile(bin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if len(s.Memory) != MemorySize {
+		return nil, fmt.Errorf("snapshot %s is %d bytes, expected %d", name, len(s.Memory), MemorySize)
+	}
+	return s, nil
+}
+
+// List returns the metadata of all stored snapshots, oldest first
+func List() ([]Snapshot, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var list []Snapshot
+	for _, f := range files {
+		s, err := readMeta(f)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Created.Before(list[j].Created)
+	})
+	return list, nil
+}
+
+// Delete removes a snapshot from the store
+func Delete(name string) error {
+	meta, bin, err := paths(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(meta); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	if err := os.Remove(bin); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+// readMeta decodes a snapshot metadata file
+func readMeta(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("snapshot %s not found", strings.TrimSuffix(filepath.Base(path), ".json"))
+		}
+		return nil, fmt.Errorf("failed to read snapshot metadata: %w", err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid snapshot metadata %s: %w", filepath.Base(path), err)
+	}
+	return &s, nil
+}
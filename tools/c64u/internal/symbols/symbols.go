@@ -0,0 +1,327 @@
+package symbols
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxOffset is the largest distance to a label that Describe still reports
+const MaxOffset = 0x100
+
+// Symbol is a named address
+type Symbol struct {
+	Name    string `json:"name"`
+	Address int    `json:"address"`
+}
+
+// Table holds the labels of one or more symbol files
+type Table struct {
+	byName  map[string]int
+	folded  map[string]int // lower-case names for case-insensitive fallback
+	sorted  []Symbol       // by address, then name
+	Sources []string
+}
+
+// NewTable returns an empty symbol table
+func NewTable() *Table {
+	return &Table{byName: make(map[string]int), folded: make(map[string]int)}
+}
+
+// Load reads a KickAssembler .sym or VICE .vs label file. For a .prg the
+// symbol file next to it is used (see Discover).
+func Load(path string) (*Table, error) {
+	if strings.EqualFold(filepath.Ext(path), ".prg") {
+		sym := Discover(path)
+		if sym == "" {
+			return nil, fmt.Errorf("no .sym or .vs file found next to %s", path)
+		}
+		path = sym
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open symbol file: %w", err)
+	}
+	defer f.Close()
+
+	t := NewTable()
+	if err := t.Read(f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	t.Sources = append(t.Sources, path)
+	return t, nil
+}
+
+// Discover returns the symbol file belonging to a program: the file with
+// the same base name and a .sym or .vs extension, or "" if there is none
+func Discover(prgPath string) string {
+	base := strings.TrimSuffix(prgPath, filepath.Ext(prgPath))
+	for _, ext := range []string{".sym", ".vs"} {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext
+		}
+	}
+	return ""
+}
+
+// Read parses symbol definitions. Both formats are accepted line by line:
+//
+//	.label Done=$1018          KickAssembler, inside .namespace blocks too
+//	al C:1018 .Done            VICE
+func (t *Table) Read(r io.Reader) error {
+	var namespaces []string
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(text, "//"); i >= 0 {
+			text = strings.TrimSpace(text[:i])
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case fields[0] == ".namespace" && len(fields) >= 2:
+			namespaces = append(namespaces, strings.TrimSuffix(fields[1], "{"))
+		case fields[0] == "}":
+			if len(namespaces) > 0 {
+				namespaces = namespaces[:len(namespaces)-1]
+			}
+		case fields[0] == ".label":
+			name, value, ok := strings.Cut(strings.Join(fields[1:], ""), "=")
+			if !ok {
+				return fmt.Errorf("line %d: invalid label definition", line)
+			}
+			addr, err := parseNumber(value)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			t.Add(strings.Join(append(namespaces, name), "."), addr)
+		case fields[0] == "al" && len(fields) >= 3:
+			value := fields[1]
+			if i := strings.Index(value, ":"); i >= 0 {
+				value = value[i+1:]
+			}
+			addr, err := strconv.ParseUint(value, 16, 32)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid address %q", line, fields[1])
+			}
+			t.Add(strings.TrimPrefix(fields[2], "."), int(addr))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read symbols: %w", err)
+	}
+	return nil
+}
+
+// Add defines a label, replacing an earlier definition of the same name
+func (t *Table) Add(name string, addr int) {
+	if old, ok := t.byName[name]; ok {
+		for i, s := range t.sorted {
+			if s.Name == name && s.Address == old {
+				t.sorted = append(t.sorted[:i], t.sorted[i+1:]...)
+				break
+			}
+		}
+	}
+	t.byName[name] = addr
+	t.folded[strings.ToLower(name)] = addr
+
+	i := sort.Search(len(t.sorted), func(i int) bool {
+		s := t.sorted[i]
+		return s.Address > addr || (s.Address == addr && s.Name > name)
+	})
+	t.sorted = append(t.sorted, Symbol{})
+	copy(t.sorted[i+1:], t.sorted[i:])
+	t.sorted[i] = Symbol{Name: name, Address: addr}
+}
+
+// Len returns the number of labels
+func (t *Table) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.sorted)
+}
+
+// Symbols returns all labels ordered by address
+func (t *Table) Symbols() []Symbol {
+	if t == nil {
+		return nil
+	}
+	return t.sorted
+}
+
+// Lookup finds a label by name, falling back to a case-insensitive match
+func (t *Table) Lookup(name string) (int, bool) {
+	if t == nil {
+		return 0, false
+	}
+	if addr, ok := t.byName[name]; ok {
+		return addr, true
+	}
+	addr, ok := t.folded[strings.ToLower(name)]
+	return addr, ok
+}
+
+// Nearest returns the closest label at or below addr
+func (t *Table) Nearest(addr int) (Symbol, bool) {
+	if t == nil {
+		return Symbol{}, false
+	}
+	i := sort.Search(len(t.sorted), func(i int) bool {
+		return t.sorted[i].Address > addr
+	})
+	if i == 0 {
+		return Symbol{}, false
+	}
+	// Prefer the first name defined for that address
+	best := t.sorted[i-1]
+	for j := i - 2; j >= 0 && t.sorted[j].Address == best.Address; j-- {
+		best = t.sorted[j]
+	}
+	return best, true
+}
+
+// At returns the labels defined exactly at addr
+func (t *Table) At(addr int) []string {
+	if t == nil {
+		return nil
+	}
+	var names []string
+	i := sort.Search(len(t.sorted), func(i int) bool {
+		return t.sorted[i].Address >= addr
+	})
+	for ; i < len(t.sorted) && t.sorted[i].Address == addr; i++ {
+		names = append(names, t.sorted[i].Name)
+	}
+	return names
+}
+
+// Describe names an address relative to the nearest label, e.g. "Done" or
+// "str_to_print+2". It returns "" if no label lies within MaxOffset.
+func (t *Table) Describe(addr int) string {
+	s, ok := t.Nearest(addr)
+	if !ok || addr-s.Address >= MaxOffset {
+		return ""
+	}
+	if addr == s.Address {
+		return s.Name
+	}
+	return fmt.Sprintf("%s+%d", s.Name, addr-s.Address)
+}
+
+// Eval evaluates an address expression: a label or number followed by
+// optional +/- terms, e.g. "Done", "str_to_print+2" or "$c000-$10".
+// The first term may be bare hex ("c000"), as everywhere in c64u; later
+// terms are decimal unless prefixed with $ or 0x.
+func (t *Table) Eval(expr string) (int, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return 0, fmt.Errorf("empty address")
+	}
+
+	var terms []string
+	var signs []int
+	sign, start := 1, 0
+	for i := 1; i <= len(expr); i++ {
+		if i < len(expr) && expr[i] != '+' && expr[i] != '-' {
+			continue
+		}
+		terms = append(terms, strings.TrimSpace(expr[start:i]))
+		signs = append(signs, sign)
+		if i < len(expr) {
+			sign = 1
+			if expr[i] == '-' {
+				sign = -1
+			}
+		}
+		start = i + 1
+	}
+
+	total := 0
+	for n, term := range terms {
+		v, err := t.term(term, n == 0)
+		if err != nil {
+			return 0, err
+		}
+		total += signs[n] * v
+	}
+	if total < 0 || total > 0xFFFF {
+		return 0, fmt.Errorf("address %q out of range ($%X)", expr, total)
+	}
+	return total, nil
+}
+
+// EvalRange evaluates "A..B" (inclusive) or a single address expression
+func (t *Table) EvalRange(expr string) (int, int, error) {
+	a, b, ok := strings.Cut(expr, "..")
+	start, err := t.Eval(a)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		return start, start, nil
+	}
+	end, err := t.Eval(b)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("range end $%04X is below start $%04X", end, start)
+	}
+	return start, end, nil
+}
+
+// term evaluates a single label or number. Labels win over bare hex so a
+// label named "dead" is not read as $DEAD.
+func (t *Table) term(s string, bareHex bool) (int, error) {
+	if s == "" {
+		return 0, fmt.Errorf("missing term in address expression")
+	}
+	if addr, ok := t.Lookup(s); ok {
+		return addr, nil
+	}
+	if strings.HasPrefix(s, "$") || strings.HasPrefix(strings.ToLower(s), "0x") {
+		return parseNumber(s)
+	}
+	base := 10
+	if bareHex {
+		base = 16
+	}
+	v, err := strconv.ParseUint(s, base, 32)
+	if err != nil {
+		if t.Len() > 0 {
+			return 0, fmt.Errorf("unknown label or invalid number %q", s)
+		}
+		return 0, fmt.Errorf("invalid address %q (expected hex $0000-$FFFF or a label)", s)
+	}
+	return int(v), nil
+}
+
+// parseNumber parses $hex, 0xhex or decimal
+func parseNumber(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	base := 10
+	switch {
+	case strings.HasPrefix(s, "$"):
+		s, base = s[1:], 16
+	case strings.HasPrefix(strings.ToLower(s), "0x"):
+		s, base = s[2:], 16
+	}
+	v, err := strconv.ParseUint(s, base, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return int(v), nil
+}
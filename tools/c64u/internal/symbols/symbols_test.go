@@ -0,0 +1,168 @@
+package symbols
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const kickSymbols = `.label start=$c000
+.namespace irq {
+    .label handler=$c010   // raster interrupt
+    .label dead=$c020
+}
+.label Done=$1018
+`
+
+const viceSymbols = `al C:1000 .main
+al C:1018 .loop
+`
+
+// newTable parses symbol definitions
+func newTable(t *testing.T, src string) *Table {
+	t.Helper()
+	tab := NewTable()
+	if err := tab.Read(strings.NewReader(src)); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return tab
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want map[string]int
+	}{
+		{"kick", kickSymbols, map[string]int{"start": 0xC000, "irq.handler": 0xC010, "irq.dead": 0xC020, "Done": 0x1018}},
+		{"vice", viceSymbols, map[string]int{"main": 0x1000, "loop": 0x1018}},
+		{"redefined", ".label a=1\n.label a=2\n", map[string]int{"a": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tab := newTable(t, tt.src)
+			if tab.Len() != len(tt.want) {
+				t.Errorf("Len = %d, want %d (%v)", tab.Len(), len(tt.want), tab.Symbols())
+			}
+			for name, addr := range tt.want {
+				if got, ok := tab.Lookup(name); !ok || got != addr {
+					t.Errorf("Lookup(%q) = $%04X, %v; want $%04X", name, got, ok, addr)
+				}
+			}
+		})
+	}
+
+	for _, src := range []string{".label broken\n", ".label x=$zz\n", "al C:xyz .bad\n"} {
+		if err := NewTable().Read(strings.NewReader(src)); err == nil {
+			t.Errorf("Read(%q) succeeded, want error", src)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tab := newTable(t, viceSymbols+"al C:1018 .again\n")
+
+	tests := []struct {
+		addr int
+		want string
+	}{
+		{0x0FFF, ""},
+		{0x1000, "main"},
+		{0x1002, "main+2"},
+		{0x1018, "again"},
+		{0x1019, "again+1"},
+		{0x1117, "again+255"},
+		{0x1118, ""},
+	}
+	for _, tt := range tests {
+		if got := tab.Describe(tt.addr); got != tt.want {
+			t.Errorf("Describe($%04X) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+
+	if got := strings.Join(tab.At(0x1018), ","); got != "again,loop" {
+		t.Errorf("At($1018) = %s", got)
+	}
+}
+
+func TestEval(t *testing.T) {
+	tab := newTable(t, kickSymbols)
+
+	tests := []struct {
+		expr    string
+		want    int
+		wantErr bool
+	}{
+		{"c000", 0xC000, false},
+		{"$c000-$10", 0xBFF0, false},
+		{"start+2", 0xC002, false},
+		{"done", 0x1018, false},
+		{"irq.dead", 0xC020, false},
+		{"irq.handler+16-1", 0xC01F, false},
+		{"0x0801", 0x0801, false},
+		{"ffff+1", 0, true},
+		{"start+", 0, true},
+		{"nolabel", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := tab.Eval(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Eval(%q) error = %v, want error: %v", tt.expr, err, tt.wantErr)
+		} else if got != tt.want {
+			t.Errorf("Eval(%q) = $%04X, want $%04X", tt.expr, got, tt.want)
+		}
+	}
+
+	// Without symbols, bare hex is still read as an address
+	var empty *Table
+	if got, err := empty.Eval("dead"); err != nil || got != 0xDEAD {
+		t.Errorf("nil table Eval(dead) = $%04X, %v", got, err)
+	}
+}
+
+func TestEvalRange(t *testing.T) {
+	tab := newTable(t, kickSymbols)
+
+	tests := []struct {
+		expr       string
+		start, end int
+		wantErr    bool
+	}{
+		{"start..start+$ff", 0xC000, 0xC0FF, false},
+		{"d020", 0xD020, 0xD020, false},
+		{"c010..c000", 0, 0, true},
+	}
+	for _, tt := range tests {
+		start, end, err := tab.EvalRange(tt.expr)
+		if (err != nil) != tt.wantErr || start != tt.start || end != tt.end {
+			t.Errorf("EvalRange(%q) = $%04X-$%04X, %v", tt.expr, start, end, err)
+		}
+	}
+}
+
+func TestLoadDiscover(t *testing.T) {
+	dir := t.TempDir()
+	prg := filepath.Join(dir, "game.prg")
+	if err := os.WriteFile(prg, []byte{0x01, 0x08}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(prg); err == nil {
+		t.Error("Load without a symbol file succeeded")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "game.vs"), []byte(viceSymbols), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tab, err := Load(prg)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if tab.Len() != 2 || len(tab.Sources) != 1 || filepath.Base(tab.Sources[0]) != "game.vs" {
+		t.Errorf("loaded %d symbols from %v", tab.Len(), tab.Sources)
+	}
+}
@@ -0,0 +1,223 @@
+package tape
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cybersorcerer/c64.nvim/tools/c64u/internal/petscii"
+)
+
+// T64 container format (C64S tape archive)
+//
+// Header (64 bytes):
+//   $00-$1F signature ("C64 tape image file" / "C64S tape file")
+//   $20-$21 version ($0100 or $0101)
+//   $22-$23 maximum number of directory entries
+//   $24-$25 number of used entries
+//   $28-$3F tape name (space padded)
+//
+// Directory entries follow at $40, 32 bytes each.
+
+const (
+	t64HeaderSize = 64
+	t64EntrySize  = 32
+)
+
+// T64 entry types (C64S file type byte)
+const (
+	T64EntryFree     = 0
+	T64EntryNormal   = 1
+	T64EntrySnapshot = 3
+)
+
+// T64 represents a parsed T64 tape container
+type T64 struct {
+	Signature   string     `json:"signature"`
+	Version     uint16     `json:"version"`
+	MaxEntries  int        `json:"max_entries"`
+	UsedEntries int        `json:"used_entries"`
+	Name        string     `json:"name"`
+	Entries     []T64Entry `json:"entries"`
+
+	data []byte
+}
+
+// T64Entry represents a single file in a T64 container
+type T64Entry struct {
+	Index     int    `json:"index"`
+	EntryType byte   `json:"entry_type"`
+	FileType  byte   `json:"file_type"`
+	Name      string `json:"name"`
+	RawName   []byte `json:"-"`
+	Start     uint16 `json:"start"`
+	End       uint16 `json:"end"`
+	Offset    uint32 `json:"offset"`
+	Size      int    `json:"size"`
+}
+
+// IsT64 reports whether data starts with a known T64 signature
+func IsT64(data []byte) bool {
+	if len(data) < t64HeaderSize {
+		return false
+	}
+	sig := string(data[:32])
+	return strings.HasPrefix(sig, "C64") && strings.Contains(sig, "tape")
+}
+
+// ReadT64File loads and parses a T64 file from disk
+func ReadT64File(path string) (*T64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return ParseT64(data)
+}
+
+// ParseT64 parses a T64 container
+func ParseT64(data []byte) (*T64, error) {
+	if !IsT64(data) {
+		return nil, fmt.Errorf("not a T64 file (bad signature)")
+	}
+
+	t := &T64{
+		Signature:   strings.TrimRight(string(data[:32]), "\x00 "),
+		Version:     binary.LittleEndian.Uint16(data[0x20:]),
+		MaxEntries:  int(binary.LittleEndian.Uint16(data[0x22:])),
+		UsedEntries: int(binary.LittleEndian.Uint16(data[0x24:])),
+		Name:        DisplayName(data[0x28:0x40]),
+		data:        data,
+	}
+
+	// Some tools write 0 max entries; fall back to the used count
+	maxEntries := t.MaxEntries
+	if maxEntries < t.UsedEntries {
+		maxEntries = t.UsedEntries
+	}
+	if maxEntries == 0 {
+		maxEntries = 1
+	}
+
+	for i := 0; i < maxEntries; i++ {
+		pos := t64HeaderSize + i*t64EntrySize
+		if pos+t64EntrySize > len(data) {
+			break
+		}
+		raw := data[pos : pos+t64EntrySize]
+		if raw[0] == T64EntryFree {
+			continue
+		}
+
+		rawName := bytes.TrimRight(raw[16:32], "\x20\xa0\x00")
+		t.Entries = append(t.Entries, T64Entry{
+			Index:     i,
+			EntryType: raw[0],
+			FileType:  raw[1],
+			Name:      DisplayName(rawName),
+			RawName:   append([]byte(nil), rawName...),
+			Start:     binary.LittleEndian.Uint16(raw[2:]),
+			End:       binary.LittleEndian.Uint16(raw[4:]),
+			Offset:    binary.LittleEndian.Uint32(raw[8:]),
+		})
+	}
+
+	if len(t.Entries) == 0 {
+		return nil, fmt.Errorf("T64 file contains no entries")
+	}
+
+	t.fixSizes()
+	return t, nil
+}
+
+// fixSizes computes each entry's real data size.
+// Many T64 files in the wild carry a bogus end address (often $C3C6),
+// so the size is clamped to the gap before the next entry's data.
+func (t *T64) fixSizes() {
+	order := make([]int, len(t.Entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return t.Entries[order[a]].Offset < t.Entries[order[b]].Offset
+	})
+
+	for n, idx := range order {
+		e := &t.Entries[idx]
+		limit := len(t.data)
+		if n+1 < len(order) {
+			limit = int(t.Entries[order[n+1]].Offset)
+		}
+		if int(e.Offset) >= len(t.data) {
+			e.Size = 0
+			continue
+		}
+		if limit > len(t.data) {
+			limit = len(t.data)
+		}
+
+		available := limit - int(e.Offset)
+		declared := int(e.End) - int(e.Start)
+		if declared <= 0 || declared > available {
+			declared = available
+		}
+		// Data that would load past $FFFF is cut off rather than wrapping
+		// the end address around to zero page
+		if int(e.Start)+declared > 0x10000 {
+			declared = 0x10000 - int(e.Start)
+		}
+		e.Size = declared
+		e.End = uint16(min(int(e.Start)+declared, 0xFFFF))
+	}
+}
+
+// Entry returns the entry with the given directory index
+func (t *T64) Entry(index int) (*T64Entry, error) {
+	for i := range t.Entries {
+		if t.Entries[i].Index == index {
+			return &t.Entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no entry with index %d", index)
+}
+
+// Data returns the raw file data of an entry (without load address)
+func (t *T64) Data(index int) ([]byte, error) {
+	e, err := t.Entry(index)
+	if err != nil {
+		return nil, err
+	}
+	if e.Size == 0 {
+		return nil, fmt.Errorf("entry %d has no data", index)
+	}
+	return t.data[e.Offset : int(e.Offset)+e.Size], nil
+}
+
+// PRG returns an entry as PRG file contents (load address + data)
+func (t *T64) PRG(index int) ([]byte, error) {
+	e, err := t.Entry(index)
+	if err != nil {
+		return nil, err
+	}
+	if e.EntryType != T64EntryNormal {
+		return nil, fmt.Errorf("entry %d is not a normal tape file (type %d)", index, e.EntryType)
+	}
+
+	data, err := t.Data(index)
+	if err != nil {
+		return nil, err
+	}
+
+	prg := make([]byte, 2, len(data)+2)
+	binary.LittleEndian.PutUint16(prg, e.Start)
+	return append(prg, data...), nil
+}
+
+// DisplayName converts a PETSCII file name to printable text. Zero bytes
+// count as padding like shifted spaces.
+func DisplayName(raw []byte) string {
+	name := petscii.ToASCII(bytes.ReplaceAll(raw, []byte{0x00}, []byte{0xA0}))
+	return strings.TrimRight(name, " ")
+}
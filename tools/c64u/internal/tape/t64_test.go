@@ -0,0 +1,169 @@
+package tape
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// t64Entry describes one directory entry for buildT64
+type t64Entry struct {
+	start, end uint16
+	offset     uint32
+	name       string
+}
+
+// buildT64 assembles a T64 image from directory entries and file data
+func buildT64(entries []t64Entry, data []byte) []byte {
+	img := make([]byte, t64HeaderSize+len(entries)*t64EntrySize)
+	copy(img, "C64S tape image file")
+	binary.LittleEndian.PutUint16(img[0x20:], 0x0101)
+	binary.LittleEndian.PutUint16(img[0x22:], uint16(len(entries)))
+	binary.LittleEndian.PutUint16(img[0x24:], uint16(len(entries)))
+	copy(img[0x28:0x40], bytes.Repeat([]byte{' '}, 24))
+	copy(img[0x28:], "TEST TAPE")
+
+	for i, e := range entries {
+		raw := img[t64HeaderSize+i*t64EntrySize:]
+		raw[0] = T64EntryNormal
+		raw[1] = 0x82
+		binary.LittleEndian.PutUint16(raw[2:], e.start)
+		binary.LittleEndian.PutUint16(raw[4:], e.end)
+		binary.LittleEndian.PutUint32(raw[8:], e.offset)
+		copy(raw[16:32], bytes.Repeat([]byte{0x20}, 16))
+		copy(raw[16:], e.name)
+	}
+	return append(img, data...)
+}
+
+func TestParseT64Sizes(t *testing.T) {
+	dataStart := uint32(t64HeaderSize + 2*t64EntrySize)
+
+	tests := []struct {
+		name      string
+		entries   []t64Entry
+		dataLen   int
+		wantSizes []int
+		wantEnds  []uint16
+	}{
+		{
+			name: "declared sizes",
+			entries: []t64Entry{
+				{start: 0x0801, end: 0x0811, offset: dataStart, name: "ONE"},
+				{start: 0xC000, end: 0xC008, offset: dataStart + 16, name: "TWO"},
+			},
+			dataLen:   24,
+			wantSizes: []int{16, 8},
+			wantEnds:  []uint16{0x0811, 0xC008},
+		},
+		{
+			name: "bogus end clamped to next entry",
+			entries: []t64Entry{
+				{start: 0x0801, end: 0xC3C6, offset: dataStart, name: "ONE"},
+				{start: 0x1000, end: 0xC3C6, offset: dataStart + 10, name: "TWO"},
+			},
+			dataLen:   14,
+			wantSizes: []int{10, 4},
+			wantEnds:  []uint16{0x080B, 0x1004},
+		},
+		{
+			name: "entries out of offset order",
+			entries: []t64Entry{
+				{start: 0x2000, end: 0x2004, offset: dataStart + 6, name: "LATE"},
+				{start: 0x1000, end: 0x1006, offset: dataStart, name: "EARLY"},
+			},
+			dataLen:   10,
+			wantSizes: []int{4, 6},
+			wantEnds:  []uint16{0x2004, 0x1006},
+		},
+		{
+			name: "data past $FFFF is cut off",
+			entries: []t64Entry{
+				{start: 0xFF00, end: 0x0000, offset: dataStart, name: "HIGH"},
+				{start: 0x1000, end: 0x1001, offset: dataStart + 0x200, name: "LOW"},
+			},
+			dataLen:   0x201,
+			wantSizes: []int{0x100, 1},
+			wantEnds:  []uint16{0xFFFF, 0x1001},
+		},
+		{
+			name: "offset beyond the file",
+			entries: []t64Entry{
+				{start: 0x0801, end: 0x0803, offset: dataStart, name: "ONE"},
+				{start: 0x0801, end: 0x0803, offset: dataStart + 100, name: "GONE"},
+			},
+			dataLen:   2,
+			wantSizes: []int{2, 0},
+			wantEnds:  []uint16{0x0803, 0x0803},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp, err := ParseT64(buildT64(tt.entries, make([]byte, tt.dataLen)))
+			if err != nil {
+				t.Fatalf("ParseT64: %v", err)
+			}
+			if len(tp.Entries) != len(tt.entries) {
+				t.Fatalf("got %d entries, want %d", len(tp.Entries), len(tt.entries))
+			}
+			for i, e := range tp.Entries {
+				if e.Size != tt.wantSizes[i] {
+					t.Errorf("entry %d: size = %d, want %d", i, e.Size, tt.wantSizes[i])
+				}
+				if e.End != tt.wantEnds[i] {
+					t.Errorf("entry %d: end = $%04X, want $%04X", i, e.End, tt.wantEnds[i])
+				}
+			}
+		})
+	}
+}
+
+func TestT64PRG(t *testing.T) {
+	data := []byte{0xA9, 0x00, 0x60}
+	img := buildT64([]t64Entry{
+		{start: 0xC000, end: 0xC003, offset: t64HeaderSize + t64EntrySize, name: "PROG"},
+	}, data)
+
+	tp, err := ParseT64(img)
+	if err != nil {
+		t.Fatalf("ParseT64: %v", err)
+	}
+	if tp.Name != "TEST TAPE" {
+		t.Errorf("tape name = %q, want %q", tp.Name, "TEST TAPE")
+	}
+	if e := tp.Entries[0]; e.Name != "PROG" {
+		t.Errorf("entry name = %q, want %q", e.Name, "PROG")
+	}
+
+	prg, err := tp.PRG(0)
+	if err != nil {
+		t.Fatalf("PRG: %v", err)
+	}
+	if want := append([]byte{0x00, 0xC0}, data...); !bytes.Equal(prg, want) {
+		t.Errorf("PRG = % X, want % X", prg, want)
+	}
+
+	if _, err := tp.PRG(5); err == nil {
+		t.Error("PRG of a missing entry succeeded")
+	}
+}
+
+func TestParseT64Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"too short", []byte("C64S tape")},
+		{"bad signature", make([]byte, 128)},
+		{"no entries", buildT64(nil, nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseT64(tt.data); err == nil {
+				t.Error("ParseT64 succeeded, want error")
+			}
+		})
+	}
+}
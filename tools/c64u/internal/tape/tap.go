@@ -0,0 +1,313 @@
+package tape
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// TAP pulse file format
+//
+// Header (20 bytes):
+//   $00-$0B "C64-TAPE-RAW"
+//   $0C     version (0 or 1)
+//   $10-$13 length of pulse data
+//
+// Each data byte is a pulse length in units of 8 CPU cycles. A zero byte
+// means an overflow: version 0 treats it as a long pause, version 1 follows
+// it with a 24-bit cycle count.
+
+const (
+	tapSignature  = "C64-TAPE-RAW"
+	tapHeaderSize = 20
+)
+
+// CBM ROM loader pulse thresholds (TAP byte values, PAL timing).
+// Nominal pulses are short $30, medium $42 and long $56.
+const (
+	pulseShortMin  = 0x20
+	pulseShortMax  = 0x39
+	pulseMediumMax = 0x4C
+	pulseLongMax   = 0x68
+)
+
+// CBM tape header block types
+const (
+	TapeBlockBasic   = 1 // relocatable program
+	TapeBlockData    = 2 // data block of a SEQ file
+	TapeBlockPRG     = 3 // non-relocatable program
+	TapeBlockSEQ     = 4 // SEQ file header
+	TapeBlockEndTape = 5 // end-of-tape marker
+)
+
+// TAP represents a parsed TAP pulse file
+type TAP struct {
+	Version    byte        `json:"version"`
+	DataLength int         `json:"data_length"`
+	Pulses     int         `json:"pulses"`
+	Headers    []TAPHeader `json:"headers"`
+	Blocks     int         `json:"blocks"`
+}
+
+// TAPHeader is a decoded CBM ROM loader header block
+type TAPHeader struct {
+	Block      int    `json:"block"`
+	Type       byte   `json:"type"`
+	TypeName   string `json:"type_name"`
+	Name       string `json:"name"`
+	RawName    []byte `json:"-"`
+	Start      uint16 `json:"start"`
+	End        uint16 `json:"end"`
+	ChecksumOK bool   `json:"checksum_ok"`
+	Repeat     bool   `json:"repeat"`
+	Offset     int    `json:"offset"`
+}
+
+// IsTAP reports whether data starts with the TAP signature
+func IsTAP(data []byte) bool {
+	return len(data) >= tapHeaderSize && string(data[:len(tapSignature)]) == tapSignature
+}
+
+// ReadTAPFile loads and parses a TAP file from disk
+func ReadTAPFile(path string) (*TAP, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return ParseTAP(data)
+}
+
+// ParseTAP decodes a TAP file and extracts all standard CBM header blocks
+func ParseTAP(data []byte) (*TAP, error) {
+	if !IsTAP(data) {
+		return nil, fmt.Errorf("not a TAP file (bad signature)")
+	}
+
+	t := &TAP{
+		Version:    data[12],
+		DataLength: int(binary.LittleEndian.Uint32(data[16:])),
+	}
+	switch t.Version {
+	case 0, 1:
+	case 2:
+		// Version 2 stores half-waves (C16/Plus/4), which the full-wave
+		// thresholds below would misread
+		return nil, fmt.Errorf("unsupported TAP version 2 (C16 half-wave format)")
+	default:
+		return nil, fmt.Errorf("unsupported TAP version %d", t.Version)
+	}
+
+	pulses := data[tapHeaderSize:]
+	if t.DataLength < len(pulses) {
+		pulses = pulses[:t.DataLength]
+	}
+
+	classes, offsets := classifyPulses(pulses, t.Version)
+	t.Pulses = len(classes)
+
+	for _, blk := range decodeBlocks(classes, offsets) {
+		t.Blocks++
+		if hdr, ok := parseHeaderBlock(blk); ok {
+			hdr.Block = t.Blocks - 1
+			t.Headers = append(t.Headers, hdr)
+		}
+	}
+
+	return t, nil
+}
+
+// pulse classes
+const (
+	pulseOther = iota
+	pulseShort
+	pulseMedium
+	pulseLong
+)
+
+// classifyPulses converts raw TAP bytes into pulse classes.
+// offsets maps each pulse back to its position in the pulse data.
+func classifyPulses(data []byte, version byte) ([]byte, []int) {
+	classes := make([]byte, 0, len(data))
+	offsets := make([]int, 0, len(data))
+
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		offset := i
+		if b == 0 {
+			// Overflow / pause
+			if version >= 1 {
+				i += 3
+			}
+			classes = append(classes, pulseOther)
+			offsets = append(offsets, offset)
+			continue
+		}
+
+		class := byte(pulseOther)
+		switch {
+		case b < pulseShortMin:
+			class = pulseOther
+		case b <= pulseShortMax:
+			class = pulseShort
+		case b <= pulseMediumMax:
+			class = pulseMedium
+		case b <= pulseLongMax:
+			class = pulseLong
+		}
+		classes = append(classes, class)
+		offsets = append(offsets, offset)
+	}
+
+	return classes, offsets
+}
+
+// tapBlock is a run of decoded bytes between a leader and an end marker
+type tapBlock struct {
+	data   []byte
+	offset int
+}
+
+// decodeBlocks decodes all byte sequences in the CBM encoding.
+// Each byte starts with a (long, medium) marker followed by 8 data bits and
+// an odd parity bit, LSB first. A bit 0 is (short, medium), a bit 1 is
+// (medium, short). A (long, short) pair marks the end of a block.
+func decodeBlocks(classes []byte, offsets []int) []tapBlock {
+	var blocks []tapBlock
+	var current *tapBlock
+
+	flush := func() {
+		if current != nil && len(current.data) > 0 {
+			blocks = append(blocks, *current)
+		}
+		current = nil
+	}
+
+	i := 0
+	for i+1 < len(classes) {
+		if classes[i] != pulseLong || classes[i+1] != pulseMedium {
+			// End-of-data marker or lost sync
+			flush()
+			i++
+			continue
+		}
+
+		b, ok := decodeByte(classes, i+2)
+		if !ok {
+			flush()
+			i++
+			continue
+		}
+
+		if current == nil {
+			current = &tapBlock{offset: offsets[i]}
+		}
+		current.data = append(current.data, b)
+		i += 20
+	}
+	flush()
+
+	return blocks
+}
+
+// decodeByte decodes 8 data bits and the parity bit starting at pos
+func decodeByte(classes []byte, pos int) (byte, bool) {
+	if pos+18 > len(classes) {
+		return 0, false
+	}
+
+	var value byte
+	parity := byte(1)
+	for bit := 0; bit < 9; bit++ {
+		a, b := classes[pos+bit*2], classes[pos+bit*2+1]
+		var v byte
+		switch {
+		case a == pulseShort && b == pulseMedium:
+			v = 0
+		case a == pulseMedium && b == pulseShort:
+			v = 1
+		default:
+			return 0, false
+		}
+
+		if bit < 8 {
+			value |= v << bit
+			parity ^= v
+		} else if v != parity {
+			return 0, false
+		}
+	}
+
+	return value, true
+}
+
+// parseHeaderBlock interprets a decoded block as a CBM header.
+// Blocks start with a countdown sync ($89..$81 for the first copy,
+// $09..$01 for the repeat) followed by the payload and an XOR checksum.
+func parseHeaderBlock(blk tapBlock) (TAPHeader, bool) {
+	const syncLen = 9
+	const headerLen = 192
+	data := blk.data
+	if len(data) != syncLen+headerLen+1 {
+		return TAPHeader{}, false
+	}
+
+	repeat := false
+	switch data[0] {
+	case 0x89:
+	case 0x09:
+		repeat = true
+	default:
+		return TAPHeader{}, false
+	}
+
+	payload := data[syncLen : len(data)-1]
+	var checksum byte
+	for _, b := range payload {
+		checksum ^= b
+	}
+
+	blockType := payload[0]
+	if blockType < TapeBlockBasic || blockType > TapeBlockEndTape || blockType == TapeBlockData {
+		return TAPHeader{}, false
+	}
+
+	rawName := trimName(payload[5:21])
+	return TAPHeader{
+		Type:       blockType,
+		TypeName:   BlockTypeName(blockType),
+		Name:       DisplayName(rawName),
+		RawName:    rawName,
+		Start:      binary.LittleEndian.Uint16(payload[1:]),
+		End:        binary.LittleEndian.Uint16(payload[3:]),
+		ChecksumOK: checksum == data[len(data)-1],
+		Repeat:     repeat,
+		Offset:     blk.offset,
+	}, true
+}
+
+// trimName strips padding from a raw tape file name
+func trimName(raw []byte) []byte {
+	end := len(raw)
+	for end > 0 && (raw[end-1] == 0x20 || raw[end-1] == 0xA0 || raw[end-1] == 0x00) {
+		end--
+	}
+	return append([]byte(nil), raw[:end]...)
+}
+
+// BlockTypeName returns a human-readable name for a tape header type
+func BlockTypeName(t byte) string {
+	switch t {
+	case TapeBlockBasic:
+		return "PRG (relocatable)"
+	case TapeBlockData:
+		return "DATA"
+	case TapeBlockPRG:
+		return "PRG"
+	case TapeBlockSEQ:
+		return "SEQ"
+	case TapeBlockEndTape:
+		return "END-OF-TAPE"
+	default:
+		return fmt.Sprintf("unknown ($%02X)", t)
+	}
+}
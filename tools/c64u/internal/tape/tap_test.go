@@ -0,0 +1,181 @@
+package tape
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// Nominal pulse lengths as written by the CBM ROM saver
+const (
+	testShort  = 0x30
+	testMedium = 0x42
+	testLong   = 0x56
+)
+
+// encodeBlock writes bytes in the CBM tape encoding followed by an
+// end-of-block marker
+func encodeBlock(data []byte) []byte {
+	var pulses []byte
+	for _, b := range data {
+		pulses = append(pulses, testLong, testMedium)
+		parity := byte(1)
+		for bit := 0; bit < 9; bit++ {
+			v := parity
+			if bit < 8 {
+				v = b >> bit & 1
+				parity ^= v
+			}
+			if v == 0 {
+				pulses = append(pulses, testShort, testMedium)
+			} else {
+				pulses = append(pulses, testMedium, testShort)
+			}
+		}
+	}
+	return append(pulses, testLong, testShort)
+}
+
+// headerBlock builds a header block with countdown sync and checksum
+func headerBlock(blockType byte, start, end uint16, name string, repeat bool) []byte {
+	payload := make([]byte, 192)
+	payload[0] = blockType
+	binary.LittleEndian.PutUint16(payload[1:], start)
+	binary.LittleEndian.PutUint16(payload[3:], end)
+	for i := 5; i < 21; i++ {
+		payload[i] = 0x20
+	}
+	copy(payload[5:], name)
+
+	var block []byte
+	for s := byte(0x89); s >= 0x81; s-- {
+		if repeat {
+			block = append(block, s&0x7F)
+		} else {
+			block = append(block, s)
+		}
+	}
+	block = append(block, payload...)
+
+	var checksum byte
+	for _, b := range payload {
+		checksum ^= b
+	}
+	return append(block, checksum)
+}
+
+// buildTAP wraps pulse data in a TAP header
+func buildTAP(version byte, pulses []byte) []byte {
+	tap := make([]byte, tapHeaderSize, tapHeaderSize+len(pulses))
+	copy(tap, tapSignature)
+	tap[12] = version
+	binary.LittleEndian.PutUint32(tap[16:], uint32(len(pulses)))
+	return append(tap, pulses...)
+}
+
+func TestParseTAPHeaders(t *testing.T) {
+	leader := make([]byte, 64)
+	for i := range leader {
+		leader[i] = testShort
+	}
+	corrupt := headerBlock(TapeBlockPRG, 0xC000, 0xC100, "BROKEN", false)
+	corrupt[len(corrupt)-1] ^= 0xFF
+
+	tests := []struct {
+		name    string
+		version byte
+		pulses  [][]byte
+		want    []TAPHeader
+		blocks  int
+	}{
+		{
+			name:    "header and repeat",
+			version: 1,
+			pulses: [][]byte{
+				leader,
+				encodeBlock(headerBlock(TapeBlockBasic, 0x0801, 0x0900, "HELLO", false)),
+				encodeBlock(headerBlock(TapeBlockBasic, 0x0801, 0x0900, "HELLO", true)),
+			},
+			want: []TAPHeader{
+				{Block: 0, Type: TapeBlockBasic, Name: "HELLO", Start: 0x0801, End: 0x0900, ChecksumOK: true},
+				{Block: 1, Type: TapeBlockBasic, Name: "HELLO", Start: 0x0801, End: 0x0900, ChecksumOK: true, Repeat: true},
+			},
+			blocks: 2,
+		},
+		{
+			name:    "version 0 pause between files",
+			version: 0,
+			pulses: [][]byte{
+				encodeBlock(headerBlock(TapeBlockPRG, 0xC000, 0xC010, "ONE", false)),
+				{0x00},
+				encodeBlock(headerBlock(TapeBlockSEQ, 0x033C, 0x03FC, "TWO", false)),
+			},
+			want: []TAPHeader{
+				{Block: 0, Type: TapeBlockPRG, Name: "ONE", Start: 0xC000, End: 0xC010, ChecksumOK: true},
+				{Block: 1, Type: TapeBlockSEQ, Name: "TWO", Start: 0x033C, End: 0x03FC, ChecksumOK: true},
+			},
+			blocks: 2,
+		},
+		{
+			name:    "bad checksum",
+			version: 1,
+			pulses:  [][]byte{encodeBlock(corrupt)},
+			want: []TAPHeader{
+				{Block: 0, Type: TapeBlockPRG, Name: "BROKEN", Start: 0xC000, End: 0xC100},
+			},
+			blocks: 1,
+		},
+		{
+			name:    "data block is not a header",
+			version: 1,
+			pulses:  [][]byte{encodeBlock([]byte{0x89, 0x88, 0x87, 0x01, 0x02})},
+			blocks:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pulses []byte
+			for _, p := range tt.pulses {
+				pulses = append(pulses, p...)
+			}
+
+			tap, err := ParseTAP(buildTAP(tt.version, pulses))
+			if err != nil {
+				t.Fatalf("ParseTAP: %v", err)
+			}
+			if tap.Blocks != tt.blocks {
+				t.Errorf("blocks = %d, want %d", tap.Blocks, tt.blocks)
+			}
+			if len(tap.Headers) != len(tt.want) {
+				t.Fatalf("got %d headers, want %d", len(tap.Headers), len(tt.want))
+			}
+			for i, h := range tap.Headers {
+				w := tt.want[i]
+				if h.Block != w.Block || h.Type != w.Type || h.Name != w.Name || h.Start != w.Start ||
+					h.End != w.End || h.ChecksumOK != w.ChecksumOK || h.Repeat != w.Repeat {
+					t.Errorf("header %d = %+v, want %+v", i, h, w)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTAPInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"bad signature", make([]byte, 32)},
+		{"too short", []byte(tapSignature)},
+		{"half-wave version 2", buildTAP(2, nil)},
+		{"unknown version", buildTAP(7, nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseTAP(tt.data); err == nil {
+				t.Error("ParseTAP succeeded, want error")
+			}
+		})
+	}
+}
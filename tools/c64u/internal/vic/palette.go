@@ -0,0 +1,60 @@
+package vic
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+)
+
+// ColorNames are the names of the 16 C64 colours
+var ColorNames = [16]string{
+	"black", "white", "red", "cyan", "purple", "green", "blue", "yellow",
+	"orange", "brown", "light red", "dark grey", "grey", "light green", "light blue", "light grey",
+}
+
+// Palette maps the 16 C64 colours to RGB
+type Palette [16]color.RGBA
+
+// DefaultPalette is used when no palette is selected
+const DefaultPalette = "colodore"
+
+// Palettes are the selectable palettes by name
+var Palettes = map[string]Palette{
+	"colodore": rgbPalette(
+		0x000000, 0xFFFFFF, 0x813338, 0x75CEC8, 0x8E3C97, 0x56AC4D, 0x2E2C9B, 0xEDF171,
+		0x8E5029, 0x553800, 0xC46C71, 0x4A4A4A, 0x7B7B7B, 0xA9FF9F, 0x706DEB, 0xB2B2B2,
+	),
+	"pepto": rgbPalette(
+		0x000000, 0xFFFFFF, 0x68372B, 0x70A4B2, 0x6F3D86, 0x588D43, 0x352879, 0xB8C76F,
+		0x6F4F25, 0x433900, 0x9A6759, 0x444444, 0x6C6C6C, 0x9AD284, 0x6C5EB5, 0x959595,
+	),
+}
+
+// PaletteNames lists the palette names in sorted order
+func PaletteNames() []string {
+	names := make([]string, 0, len(Palettes))
+	for name := range Palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupPalette returns a palette by name
+func LookupPalette(name string) (Palette, error) {
+	p, ok := Palettes[strings.ToLower(name)]
+	if !ok {
+		return Palette{}, fmt.Errorf("unknown palette %q (use %s)", name, strings.Join(PaletteNames(), ", "))
+	}
+	return p, nil
+}
+
+// rgbPalette builds a palette from 0xRRGGBB values
+func rgbPalette(values ...uint32) Palette {
+	var p Palette
+	for i, v := range values {
+		p[i] = color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}
+	}
+	return p
+}
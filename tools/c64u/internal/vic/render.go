@@ -0,0 +1,324 @@
+package vic
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+)
+
+// Frame geometry. The bordered frame matches the visible PAL area; the
+// display window starts at sprite coordinates 24/50.
+const (
+	DisplayWidth  = 320
+	DisplayHeight = 200
+	FrameWidth    = 384
+	FrameHeight   = 272
+	BorderLeft    = 32
+	BorderTop     = 36
+
+	spriteOriginX = 24
+	spriteOriginY = 50
+)
+
+// CharROMSize is the size of the character ROM
+const CharROMSize = 0x1000
+
+// ErrCharROM is returned when the picture uses the character ROM but
+// none was supplied
+var ErrCharROM = errors.New("the character ROM is needed but was not supplied")
+
+// Mode is a VIC-II graphics mode
+type Mode int
+
+const (
+	ModeText Mode = iota
+	ModeMulticolorText
+	ModeExtendedColor
+	ModeBitmap
+	ModeMulticolorBitmap
+	ModeInvalid
+)
+
+var modeNames = map[Mode]string{
+	ModeText:             "text",
+	ModeMulticolorText:   "multicolour text",
+	ModeExtendedColor:    "extended colour text",
+	ModeBitmap:           "hires bitmap",
+	ModeMulticolorBitmap: "multicolour bitmap",
+	ModeInvalid:          "invalid",
+}
+
+func (m Mode) String() string {
+	return modeNames[m]
+}
+
+// Memory is everything the VIC-II sees: its 16K bank, colour RAM, the
+// registers $D000-$D02E and the character ROM
+type Memory struct {
+	Layout   Layout
+	Regs     [0x2F]byte
+	Bank     []byte
+	ColorRAM []byte
+	CharROM  []byte
+
+	romMissing bool
+}
+
+// LoadCharROM reads a character ROM dump. Larger files (e.g. the C128
+// ROM) are accepted and their first 4K used.
+func LoadCharROM(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read character ROM: %w", err)
+	}
+	if len(data) < CharROMSize {
+		return nil, fmt.Errorf("%s is %d bytes, a character ROM has %d", path, len(data), CharROMSize)
+	}
+	return data[:CharROMSize], nil
+}
+
+// Mode returns the graphics mode selected by $D011 and $D016
+func (m *Memory) Mode() Mode {
+	ecm := m.Regs[0x11]&0x40 != 0
+	bmm := m.Regs[0x11]&0x20 != 0
+	mcm := m.Regs[0x16]&0x10 != 0
+	switch {
+	case ecm && (bmm || mcm):
+		return ModeInvalid
+	case ecm:
+		return ModeExtendedColor
+	case bmm && mcm:
+		return ModeMulticolorBitmap
+	case bmm:
+		return ModeBitmap
+	case mcm:
+		return ModeMulticolorText
+	default:
+		return ModeText
+	}
+}
+
+// Bitmap returns the address of the bitmap used in bitmap modes
+func (m *Memory) Bitmap() int {
+	return m.Layout.Bank + int(m.Regs[0x18]&0x08)*0x400
+}
+
+// read fetches a byte at an offset in the VIC bank, as the VIC sees it
+func (m *Memory) read(off int) byte {
+	off &= 0x3FFF
+	if (m.Layout.Bank == 0x0000 || m.Layout.Bank == 0x8000) && off >= 0x1000 && off < 0x2000 {
+		if len(m.CharROM) < CharROMSize {
+			m.romMissing = true
+			return 0
+		}
+		return m.CharROM[off-0x1000]
+	}
+	if off >= len(m.Bank) {
+		return 0
+	}
+	return m.Bank[off]
+}
+
+// Render draws the screen with sprites. With border the full visible
+// frame is returned, otherwise only the 320x200 display window.
+func Render(m *Memory, palette Palette, border bool) (*image.Paletted, error) {
+	m.romMissing = false
+	colors := make(color.Palette, len(palette))
+	for i, c := range palette {
+		colors[i] = c
+	}
+	frame := image.NewPaletted(image.Rect(0, 0, FrameWidth, FrameHeight), colors)
+
+	borderColor := m.Regs[0x20] & 0x0F
+	for i := range frame.Pix {
+		frame.Pix[i] = borderColor
+	}
+
+	// DEN cleared: the whole screen shows the border colour
+	if m.Regs[0x11]&0x10 != 0 {
+		var foreground [DisplayHeight][DisplayWidth]bool
+		m.drawDisplay(frame, &foreground)
+		m.drawSprites(frame, &foreground)
+		m.drawBorder(frame, borderColor)
+	}
+	if m.romMissing {
+		return nil, ErrCharROM
+	}
+
+	if border {
+		return frame, nil
+	}
+	return frame.SubImage(image.Rect(BorderLeft, BorderTop, BorderLeft+DisplayWidth, BorderTop+DisplayHeight)).(*image.Paletted), nil
+}
+
+// drawDisplay draws the graphics of the display window and records which
+// pixels count as foreground for sprite priority
+func (m *Memory) drawDisplay(frame *image.Paletted, foreground *[DisplayHeight][DisplayWidth]bool) {
+	mode := m.Mode()
+	screenOff := int(m.Regs[0x18]>>4) * 0x400
+	charOff := int(m.Regs[0x18]&0x0E) * 0x400
+	bitmapOff := m.Bitmap() - m.Layout.Bank
+	xscroll := int(m.Regs[0x16] & 0x07)
+	yscroll := int(m.Regs[0x11]&0x07) - 3
+	bg := [4]byte{m.Regs[0x21] & 0x0F, m.Regs[0x22] & 0x0F, m.Regs[0x23] & 0x0F, m.Regs[0x24] & 0x0F}
+
+	for y := 0; y < DisplayHeight; y++ {
+		for x := 0; x < DisplayWidth; x++ {
+			sx, sy := x-xscroll, y-yscroll
+			pixel, fg := bg[0], false
+			if sx >= 0 && sy >= 0 && sy < DisplayHeight {
+				cell := (sy/8)*Columns + sx/8
+				line, bit := sy%8, sx%8
+				code := m.read(screenOff + cell)
+				colorRAM := byte(0)
+				if cell < len(m.ColorRAM) {
+					colorRAM = m.ColorRAM[cell] & 0x0F
+				}
+
+				switch mode {
+				case ModeText:
+					if m.read(charOff+int(code)*8+line)&(0x80>>bit) != 0 {
+						pixel, fg = colorRAM, true
+					}
+				case ModeMulticolorText:
+					data := m.read(charOff + int(code)*8 + line)
+					if colorRAM&0x08 == 0 {
+						if data&(0x80>>bit) != 0 {
+							pixel, fg = colorRAM&0x07, true
+						}
+						break
+					}
+					switch (data >> (6 - bit&^1)) & 0x03 {
+					case 1:
+						pixel = bg[1]
+					case 2:
+						pixel, fg = bg[2], true
+					case 3:
+						pixel, fg = colorRAM&0x07, true
+					}
+				case ModeExtendedColor:
+					if m.read(charOff+int(code&0x3F)*8+line)&(0x80>>bit) != 0 {
+						pixel, fg = colorRAM, true
+					} else {
+						pixel = bg[code>>6]
+					}
+				case ModeBitmap:
+					if m.read(bitmapOff+cell*8+line)&(0x80>>bit) != 0 {
+						pixel, fg = code>>4, true
+					} else {
+						pixel = code & 0x0F
+					}
+				case ModeMulticolorBitmap:
+					switch (m.read(bitmapOff+cell*8+line) >> (6 - bit&^1)) & 0x03 {
+					case 1:
+						pixel = code >> 4
+					case 2:
+						pixel, fg = code&0x0F, true
+					case 3:
+						pixel, fg = colorRAM, true
+					}
+				case ModeInvalid:
+					pixel = 0
+				}
+			}
+			foreground[y][x] = fg
+			frame.SetColorIndex(BorderLeft+x, BorderTop+y, pixel)
+		}
+	}
+}
+
+// drawSprites draws the enabled sprites; sprite 0 has the highest priority
+func (m *Memory) drawSprites(frame *image.Paletted, foreground *[DisplayHeight][DisplayWidth]bool) {
+	screenOff := int(m.Regs[0x18]>>4) * 0x400
+	for i := 7; i >= 0; i-- {
+		mask := byte(1) << i
+		if m.Regs[0x15]&mask == 0 {
+			continue
+		}
+		x := int(m.Regs[i*2])
+		if m.Regs[0x10]&mask != 0 {
+			x += 0x100
+		}
+		y := int(m.Regs[i*2+1])
+		base := int(m.read(screenOff+0x3F8+i)) * 64
+		xscale, yscale := 1, 1
+		if m.Regs[0x1D]&mask != 0 {
+			xscale = 2
+		}
+		if m.Regs[0x17]&mask != 0 {
+			yscale = 2
+		}
+		multicolor := m.Regs[0x1C]&mask != 0
+		behind := m.Regs[0x1B]&mask != 0
+		spriteColor := m.Regs[0x27+i] & 0x0F
+
+		for sy := 0; sy < 21*yscale; sy++ {
+			for sx := 0; sx < 24*xscale; sx++ {
+				px, py := sx/xscale, sy/yscale
+				data := m.read(base + py*3 + px/8)
+				var pixel byte
+				if multicolor {
+					switch (data >> (6 - (px%8)&^1)) & 0x03 {
+					case 0:
+						continue
+					case 1:
+						pixel = m.Regs[0x25] & 0x0F
+					case 2:
+						pixel = spriteColor
+					case 3:
+						pixel = m.Regs[0x26] & 0x0F
+					}
+				} else {
+					if data&(0x80>>(px%8)) == 0 {
+						continue
+					}
+					pixel = spriteColor
+				}
+
+				dx := x - spriteOriginX + sx
+				dy := y - spriteOriginY + sy
+				if behind && dx >= 0 && dx < DisplayWidth && dy >= 0 && dy < DisplayHeight && foreground[dy][dx] {
+					continue
+				}
+				frame.SetColorIndex(BorderLeft+dx, BorderTop+dy, pixel)
+			}
+		}
+	}
+}
+
+// drawBorder paints the border over everything outside the display
+// window, which shrinks in 38 column and 24 row mode
+func (m *Memory) drawBorder(frame *image.Paletted, borderColor byte) {
+	left, right := BorderLeft, BorderLeft+DisplayWidth
+	top, bottom := BorderTop, BorderTop+DisplayHeight
+	if m.Regs[0x16]&0x08 == 0 {
+		left, right = left+7, right-9
+	}
+	if m.Regs[0x11]&0x08 == 0 {
+		top, bottom = top+4, bottom-4
+	}
+	for y := 0; y < FrameHeight; y++ {
+		for x := 0; x < FrameWidth; x++ {
+			if x < left || x >= right || y < top || y >= bottom {
+				frame.SetColorIndex(x, y, borderColor)
+			}
+		}
+	}
+}
+
+// Scale enlarges an image by an integer factor
+func Scale(img *image.Paletted, factor int) *image.Paletted {
+	if factor <= 1 {
+		return img
+	}
+	b := img.Bounds()
+	out := image.NewPaletted(image.Rect(0, 0, b.Dx()*factor, b.Dy()*factor), img.Palette)
+	for y := 0; y < b.Dy()*factor; y++ {
+		for x := 0; x < b.Dx()*factor; x++ {
+			out.SetColorIndex(x, y, img.ColorIndexAt(b.Min.X+x/factor, b.Min.Y+y/factor))
+		}
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package vic
+
+// VIC-II memory layout
+//
+//   $DD00 bits 0-1  VIC bank, inverted (%11 = $0000, %00 = $C000)
+//   $D018 bits 4-7  screen memory offset in 1K steps
+//   $D018 bits 1-3  character set offset in 2K steps
+//
+// In banks 0 and 2 the VIC sees the character ROM at offset $1000-$1FFF
+// instead of RAM: $1000 is the upper case set, $1800 the lower/upper case
+// set.
+
+// Registers and memory read to capture the screen
+const (
+	RegMemory     = 0xD018
+	RegBorder     = 0xD020
+	RegBackground = 0xD021
+	RegBank       = 0xDD00
+	ColorRAM      = 0xD800
+)
+
+// Text screen dimensions
+const (
+	Columns    = 40
+	Rows       = 25
+	ScreenSize = Columns * Rows
+)
+
+// Layout is the part of the VIC-II memory setup decoded from $DD00/$D018
+type Layout struct {
+	Bank   int `json:"bank"`
+	Screen int `json:"screen"`
+	Chars  int `json:"chars"`
+}
+
+// Decode decodes the bank, screen and character set addresses
+func Decode(dd00, d018 byte) Layout {
+	bank := int(3-dd00&0x03) * 0x4000
+	return Layout{
+		Bank:   bank,
+		Screen: bank + int(d018>>4)*0x400,
+		Chars:  bank + int(d018&0x0E)*0x400,
+	}
+}
+
+// CharROM reports whether the character set is fetched from ROM
+func (l Layout) CharROM() bool {
+	off := l.Chars - l.Bank
+	return (l.Bank == 0x0000 || l.Bank == 0x8000) && off >= 0x1000 && off < 0x2000
+}
+
+// LowerCase reports whether the lower/upper case character set is
+// selected. For RAM character sets this is a guess based on the offset.
+func (l Layout) LowerCase() bool {
+	return (l.Chars-l.Bank)&0x0800 != 0
+}
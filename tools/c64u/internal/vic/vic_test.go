@@ -0,0 +1,275 @@
+package vic
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name       string
+		dd00, d018 byte
+		want       Layout
+		rom, lower bool
+	}{
+		{"power on", 0x97, 0x15, Layout{0x0000, 0x0400, 0x1000}, true, false},
+		{"lower case", 0x97, 0x17, Layout{0x0000, 0x0400, 0x1800}, true, true},
+		{"bank 2 ROM", 0x95, 0x14, Layout{0x8000, 0x8400, 0x9000}, true, false},
+		{"bank 3 RAM", 0x94, 0x08, Layout{0xC000, 0xC000, 0xE000}, false, false},
+		{"bank 1 RAM", 0x96, 0x16, Layout{0x4000, 0x4400, 0x5800}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := Decode(tt.dd00, tt.d018)
+			if l != tt.want {
+				t.Errorf("Decode = %+v, want %+v", l, tt.want)
+			}
+			if l.CharROM() != tt.rom || l.LowerCase() != tt.lower {
+				t.Errorf("CharROM = %v, LowerCase = %v", l.CharROM(), l.LowerCase())
+			}
+		})
+	}
+}
+
+func TestMode(t *testing.T) {
+	tests := []struct {
+		d011, d016 byte
+		want       Mode
+	}{
+		{0x1B, 0x08, ModeText},
+		{0x1B, 0x18, ModeMulticolorText},
+		{0x5B, 0x08, ModeExtendedColor},
+		{0x3B, 0x08, ModeBitmap},
+		{0x3B, 0x18, ModeMulticolorBitmap},
+		{0x7B, 0x08, ModeInvalid},
+	}
+	for _, tt := range tests {
+		m := &Memory{}
+		m.Regs[0x11], m.Regs[0x16] = tt.d011, tt.d016
+		if got := m.Mode(); got != tt.want {
+			t.Errorf("$D011=$%02X $D016=$%02X: %s, want %s", tt.d011, tt.d016, got, tt.want)
+		}
+	}
+}
+
+// newMemory returns a blank 25 row, 40 column screen in bank 3 with the
+// screen at $C000, the character set at $E000 and the bitmap at $E000
+func newMemory() *Memory {
+	m := &Memory{
+		Layout:   Decode(0x94, 0x08),
+		Bank:     make([]byte, 0x4000),
+		ColorRAM: make([]byte, 0x400),
+	}
+	m.Regs[0x11] = 0x1B
+	m.Regs[0x16] = 0x08
+	m.Regs[0x18] = 0x08
+	m.Regs[0x20] = 6
+	m.Regs[0x21] = 0
+	return m
+}
+
+func TestRender(t *testing.T) {
+	type pixel struct {
+		x, y  int
+		color uint8
+	}
+
+	tests := []struct {
+		name   string
+		setup  func(m *Memory)
+		border bool
+		want   []pixel
+	}{
+		{
+			name: "text",
+			setup: func(m *Memory) {
+				m.Bank[0x2008] = 0xF0 // character 1, first line
+				m.Bank[0] = 1
+				m.ColorRAM[0] = 2
+			},
+			want: []pixel{{0, 0, 2}, {3, 0, 2}, {4, 0, 0}, {0, 1, 0}},
+		},
+		{
+			name: "border",
+			setup: func(m *Memory) {
+				m.Bank[0x2008] = 0x80
+				m.Bank[0] = 1
+				m.ColorRAM[0] = 2
+			},
+			border: true,
+			want:   []pixel{{0, 0, 6}, {BorderLeft - 1, BorderTop, 6}, {BorderLeft, BorderTop, 2}},
+		},
+		{
+			name:   "display disabled",
+			setup:  func(m *Memory) { m.Regs[0x11] = 0x0B; m.Bank[0x2008] = 0xFF; m.Bank[0] = 1 },
+			border: true,
+			want:   []pixel{{BorderLeft, BorderTop, 6}},
+		},
+		{
+			name: "38 columns",
+			setup: func(m *Memory) {
+				m.Regs[0x16] = 0x00
+				m.Regs[0x21] = 1
+			},
+			border: true,
+			want:   []pixel{{BorderLeft + 6, BorderTop, 6}, {BorderLeft + 7, BorderTop, 1}},
+		},
+		{
+			name: "hires bitmap",
+			setup: func(m *Memory) {
+				m.Regs[0x11] = 0x3B
+				m.Bank[0x2000] = 0x80
+				m.Bank[0] = 0x12
+			},
+			want: []pixel{{0, 0, 1}, {1, 0, 2}},
+		},
+		{
+			name: "multicolour bitmap",
+			setup: func(m *Memory) {
+				m.Regs[0x11] = 0x3B
+				m.Regs[0x16] = 0x18
+				m.Regs[0x21] = 9
+				m.Bank[0x2000] = 0x1B // %00 %01 %10 %11
+				m.Bank[0] = 0x34
+				m.ColorRAM[0] = 5
+			},
+			want: []pixel{{0, 0, 9}, {2, 0, 3}, {4, 0, 4}, {6, 0, 5}},
+		},
+		{
+			name: "sprite",
+			setup: func(m *Memory) {
+				m.Regs[0x15] = 0x01
+				m.Regs[0x00], m.Regs[0x01] = 24, 50
+				m.Regs[0x27] = 7
+				m.Bank[0x3F8] = 0x40 // sprite data at $1000
+				m.Bank[0x1000] = 0xC0
+			},
+			want: []pixel{{0, 0, 7}, {1, 0, 7}, {2, 0, 0}},
+		},
+		{
+			name: "sprite behind foreground",
+			setup: func(m *Memory) {
+				m.Bank[0x2008] = 0x80
+				m.Bank[0] = 1
+				m.ColorRAM[0] = 2
+				m.Regs[0x15], m.Regs[0x1B] = 0x01, 0x01
+				m.Regs[0x00], m.Regs[0x01] = 24, 50
+				m.Regs[0x27] = 7
+				m.Bank[0x3F8] = 0x40
+				m.Bank[0x1000] = 0xC0
+			},
+			want: []pixel{{0, 0, 2}, {1, 0, 7}},
+		},
+	}
+
+	palette, _ := LookupPalette(DefaultPalette)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMemory()
+			tt.setup(m)
+
+			img, err := Render(m, palette, tt.border)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			w, h := DisplayWidth, DisplayHeight
+			if tt.border {
+				w, h = FrameWidth, FrameHeight
+			}
+			if b := img.Bounds(); b.Dx() != w || b.Dy() != h {
+				t.Fatalf("size %dx%d, want %dx%d", b.Dx(), b.Dy(), w, h)
+			}
+			for _, p := range tt.want {
+				b := img.Bounds()
+				if got := img.ColorIndexAt(b.Min.X+p.x, b.Min.Y+p.y); got != p.color {
+					t.Errorf("pixel %d,%d = %d, want %d", p.x, p.y, got, p.color)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderCharROM(t *testing.T) {
+	m := newMemory()
+	m.Layout = Decode(0x97, 0x14)
+	m.Regs[0x18] = 0x14
+
+	if _, err := Render(m, Palettes["pepto"], false); !errors.Is(err, ErrCharROM) {
+		t.Errorf("Render error = %v, want ErrCharROM", err)
+	}
+
+	m.CharROM = make([]byte, CharROMSize)
+	m.CharROM[8] = 0x80
+	m.Bank[0x400] = 1
+	m.ColorRAM[0] = 1
+	img, err := Render(m, Palettes["pepto"], false)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if got := img.ColorIndexAt(img.Bounds().Min.X, img.Bounds().Min.Y); got != 1 {
+		t.Errorf("pixel 0,0 = %d, want 1", got)
+	}
+}
+
+func TestScale(t *testing.T) {
+	m := newMemory()
+	m.Bank[0x2008] = 0x80
+	m.Bank[0] = 1
+	m.ColorRAM[0] = 2
+	img, err := Render(m, Palettes["colodore"], false)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	out := Scale(img, 3)
+	if b := out.Bounds(); b.Dx() != 3*DisplayWidth || b.Dy() != 3*DisplayHeight {
+		t.Fatalf("scaled size %v", b)
+	}
+	if out.ColorIndexAt(2, 2) != 2 || out.ColorIndexAt(3, 0) != 0 {
+		t.Errorf("scaled pixels %d %d", out.ColorIndexAt(2, 2), out.ColorIndexAt(3, 0))
+	}
+	if Scale(img, 1) != img {
+		t.Error("Scale by 1 copied the image")
+	}
+}
+
+func TestPalettes(t *testing.T) {
+	if names := PaletteNames(); len(names) != 2 || names[0] != "colodore" || names[1] != "pepto" {
+		t.Errorf("PaletteNames = %v", names)
+	}
+	p, err := LookupPalette("Pepto")
+	if err != nil {
+		t.Fatalf("LookupPalette: %v", err)
+	}
+	if c := p[1]; c.R != 0xFF || c.G != 0xFF || c.B != 0xFF || c.A != 0xFF {
+		t.Errorf("white = %v", c)
+	}
+	if _, err := LookupPalette("vice"); err == nil {
+		t.Error("LookupPalette accepted an unknown palette")
+	}
+}
+
+func TestLoadCharROM(t *testing.T) {
+	dir := t.TempDir()
+	short := filepath.Join(dir, "short.bin")
+	long := filepath.Join(dir, "c128.bin")
+	if err := os.WriteFile(short, make([]byte, 2048), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 2*CharROMSize)
+	data[0] = 0x3C
+	if err := os.WriteFile(long, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadCharROM(short); err == nil {
+		t.Error("LoadCharROM accepted a 2K file")
+	}
+	rom, err := LoadCharROM(long)
+	if err != nil || len(rom) != CharROMSize || rom[0] != 0x3C {
+		t.Errorf("LoadCharROM = %d bytes, %v", len(rom), err)
+	}
+}